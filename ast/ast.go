@@ -22,8 +22,10 @@ type Literals struct {
     Line        string
 }
 
+// AssignStatement is `target = value`, or a chain of them like
+// `a = b = value`, whose Targets are assigned from left to right
 type AssignStatement struct {
-    Target      Expression
+    Targets     []Expression
     Value       Expression
     Literals
 }
@@ -31,6 +33,28 @@ type AssignStatement struct {
 func (as *AssignStatement) getStatement() {}
 func (as *AssignStatement) GetLiterals() Literals {return as.Literals}
 
+// AugAssignStatement is `target op= value`, Operator being the
+// token of op=, like `+=`
+type AugAssignStatement struct {
+    Target      Expression
+    Operator    token.Token
+    Value       Expression
+    Literals
+}
+
+func (as *AugAssignStatement) getStatement() {}
+func (as *AugAssignStatement) GetLiterals() Literals {return as.Literals}
+
+// DelStatement is `del target`, which is a tuple
+// expression when there are more than one
+type DelStatement struct {
+    Target      Expression
+    Literals
+}
+
+func (ds *DelStatement) getStatement() {}
+func (ds *DelStatement) GetLiterals() Literals {return ds.Literals}
+
 type ExpressionStatement struct {
     Value       Expression
     Literals
@@ -46,24 +70,59 @@ type IdentifierExpression struct {
 
 func (ie *IdentifierExpression) getExpression() {}
 
+// NumberExpression is an int or float literal, told
+// apart by the type of its token
 type NumberExpression struct {
     Value   token.Token
 }
 
 func (ne *NumberExpression) getExpression() {}
 
+// StringExpression is a str or bytes literal, likewise
+// told apart by the type of its token
 type StringExpression struct {
     Value   token.Token
 }
 
 func (se *StringExpression) getExpression() {}
 
+// FStringExpression is an f-string, like f'{x!r:>5} items', of
+// str literals and formatted fields one after the other
+type FStringExpression struct {
+    Parts   []Expression
+}
+
+func (fe *FStringExpression) getExpression() {}
+
+// FormattedExpression is a field of an f-string. Its Conversion is
+// 'r', 's' or 'a', or 0 if there's none, and its Spec may have
+// fields in turn, like f'{x:>{width}}'
+type FormattedExpression struct {
+    Value       Expression
+    Conversion  byte
+    Spec        *FStringExpression
+}
+
+func (fe *FormattedExpression) getExpression() {}
+
 type ListExpression struct {
     Items   []Expression
 }
 
 func (le *ListExpression) getExpression() {}
 
+type TupleExpression struct {
+    Items   []Expression
+}
+
+func (te *TupleExpression) getExpression() {}
+
+type SetExpression struct {
+    Items   []Expression
+}
+
+func (se *SetExpression) getExpression() {}
+
 type DictExpression struct {
     Keys   []Expression
     Vals   []Expression
@@ -71,6 +130,43 @@ type DictExpression struct {
 
 func (de *DictExpression) getExpression() {}
 
+// ComprehensionFor is a `for Target in Iter` clause of a
+// comprehension, with the `if` conditions following it
+type ComprehensionFor struct {
+    Target  Expression
+    Iter    Expression
+    Ifs     []Expression
+}
+
+type ListCompExpression struct {
+    Elt     Expression
+    Fors    []*ComprehensionFor
+}
+
+func (le *ListCompExpression) getExpression() {}
+
+type SetCompExpression struct {
+    Elt     Expression
+    Fors    []*ComprehensionFor
+}
+
+func (se *SetCompExpression) getExpression() {}
+
+type DictCompExpression struct {
+    Key     Expression
+    Val     Expression
+    Fors    []*ComprehensionFor
+}
+
+func (de *DictCompExpression) getExpression() {}
+
+type GeneratorExpression struct {
+    Elt     Expression
+    Fors    []*ComprehensionFor
+}
+
+func (ge *GeneratorExpression) getExpression() {}
+
 type SubscriptExpression struct {
     Target Expression
     Val   Expression
@@ -78,6 +174,16 @@ type SubscriptExpression struct {
 
 func (se *SubscriptExpression) getExpression() {}
 
+// SliceExpression is `start:stop:step` in a subscription, where
+// any of them can be left out as nil
+type SliceExpression struct {
+    Start   Expression
+    Stop    Expression
+    Step    Expression
+}
+
+func (se *SliceExpression) getExpression() {}
+
 type PlusExpression struct {
     Left    Expression
     Right   Expression
@@ -106,6 +212,91 @@ type DivideExpression struct {
 
 func (de *DivideExpression) getExpression() {}
 
+type FloorDivExpression struct {
+    Left    Expression
+    Right   Expression
+}
+
+func (fe *FloorDivExpression) getExpression() {}
+
+type ModExpression struct {
+    Left    Expression
+    Right   Expression
+}
+
+func (me *ModExpression) getExpression() {}
+
+type PowExpression struct {
+    Left    Expression
+    Right   Expression
+}
+
+func (pe *PowExpression) getExpression() {}
+
+type LShiftExpression struct {
+    Left    Expression
+    Right   Expression
+}
+
+func (le *LShiftExpression) getExpression() {}
+
+type RShiftExpression struct {
+    Left    Expression
+    Right   Expression
+}
+
+func (re *RShiftExpression) getExpression() {}
+
+type BitAndExpression struct {
+    Left    Expression
+    Right   Expression
+}
+
+func (be *BitAndExpression) getExpression() {}
+
+type BitOrExpression struct {
+    Left    Expression
+    Right   Expression
+}
+
+func (be *BitOrExpression) getExpression() {}
+
+type BitXorExpression struct {
+    Left    Expression
+    Right   Expression
+}
+
+func (be *BitXorExpression) getExpression() {}
+
+// UnaryExpression is `-x`, `+x` or `~x`, told by Operator
+type UnaryExpression struct {
+    Operator    token.Token
+    Expr        Expression
+}
+
+func (ue *UnaryExpression) getExpression() {}
+
+// YieldExpression is `yield value`, or `yield from value` if From
+// is set. Value is nil for a bare `yield`
+type YieldExpression struct {
+    Value   Expression
+    From    bool
+}
+
+func (ye *YieldExpression) getExpression() {}
+
+// LambdaExpression is `lambda params: body`, Literals is
+// where it is, for the traceback of an error in the body
+type LambdaExpression struct {
+    Params      *Parameters
+    Body        Expression
+    IsGenerator bool
+    Scope       *Scope
+    Literals
+}
+
+func (le *LambdaExpression) getExpression() {}
+
 type AndExpression struct {
     Left    Expression
     Right   Expression
@@ -157,8 +348,8 @@ func (cs *ContinueStatement) getStatement() {}
 func (cs *ContinueStatement) GetLiterals() Literals {return cs.Literals}
 
 type ForStatement struct {
-    Identifiers []token.Token
     Target      Expression
+    Iter        Expression
     Body        []Statement
     Literals
 }
@@ -175,24 +366,76 @@ type AssertStatement struct {
 func (as *AssertStatement) getStatement() {}
 func (as *AssertStatement) GetLiterals() Literals {return as.Literals}
 
+// ComparisonExpression is a chain of comparisons like `a < b <= c`,
+// where Operators[i] compares the operand before Comparators[i] with it
 type ComparisonExpression struct {
-    Operator    token.Token
     Left        Expression
-    Right       Expression
+    Operators   []token.Token
+    Comparators []Expression
 }
 
 func (ce *ComparisonExpression) getExpression() {}
 
 type DefStatement struct {
-    Name    token.Token
-    Params  []token.Token
-    Body    []Statement
+    Name        token.Token
+    Params      *Parameters
+    Body        []Statement
+    IsGenerator bool    // the body contains a `yield`
+    Scope       *Scope
+    Decorators  []Expression
     Literals
 }
 
 func (fs *DefStatement) getStatement() {}
 func (fs *DefStatement) GetLiterals() Literals {return fs.Literals}
 
+// Scope is what the parser learns of the names of a function or class
+// body. Those bound in it, by assignments, parameters, `def` and such,
+// are its Locals, except the ones declared Globals or Nonlocals
+type Scope struct {
+    Locals      map[string]bool
+    Globals     map[string]bool
+    Nonlocals   map[string]bool
+}
+
+func NewScope() *Scope {
+    return &Scope{
+        Locals: map[string]bool{},
+        Globals: map[string]bool{},
+        Nonlocals: map[string]bool{},
+    }
+}
+
+type GlobalStatement struct {
+    Names   []token.Token
+    Literals
+}
+
+func (gs *GlobalStatement) getStatement() {}
+func (gs *GlobalStatement) GetLiterals() Literals {return gs.Literals}
+
+type NonlocalStatement struct {
+    Names   []token.Token
+    Literals
+}
+
+func (ns *NonlocalStatement) getStatement() {}
+func (ns *NonlocalStatement) GetLiterals() Literals {return ns.Literals}
+
+// Parameters is the parameter list of a function definition,
+// VarArgs and KwArgs are nil when there is no `*args`, `**kwargs`
+type Parameters struct {
+    Args        []*Param
+    VarArgs     *Param
+    KwOnlyArgs  []*Param
+    KwArgs      *Param
+}
+
+type Param struct {
+    Name        token.Token
+    Default     Expression
+}
+
 type ReturnStatement struct {
     Value   Expression
     Literals
@@ -209,17 +452,55 @@ type RaiseStatement struct {
 func (rs *RaiseStatement) getStatement() {}
 func (rs *RaiseStatement) GetLiterals() Literals {return rs.Literals}
 
+type TryStatement struct {
+    Body        []Statement
+    Handlers    []*ExceptHandler
+    Else        []Statement
+    Finally     []Statement
+    Literals
+}
+
+func (ts *TryStatement) getStatement() {}
+func (ts *TryStatement) GetLiterals() Literals {return ts.Literals}
+
+// ExceptHandler is one `except` clause of a try statement,
+// a nil Type means a bare `except:` that catches everything
+type ExceptHandler struct {
+    Type        Expression
+    Name        token.Token
+    Body        []Statement
+    Literals
+}
+
 type CallExpression struct {
     Name        Expression
     Params      []Expression
+    Keywords    []*KeywordArg
 }
 
 func (ce *CallExpression) getExpression() {}
 
+// KeywordArg is a `name=value` argument of a call, or
+// a `**mapping` one when Name is empty
+type KeywordArg struct {
+    Name        token.Token
+    Value       Expression
+}
+
+// StarredExpression is the `*iterable` unpacking argument of a call,
+// an item of a list or tuple display, or a catch-all assignment target
+type StarredExpression struct {
+    Value       Expression
+}
+
+func (se *StarredExpression) getExpression() {}
+
 type ClassStatement struct {
-    Name    token.Token
-    Body    []Statement
-    Parent  token.Token
+    Name        token.Token
+    Body        []Statement
+    Bases       []Expression
+    Scope       *Scope
+    Decorators  []Expression
     Literals
 }
 
@@ -24,12 +24,7 @@ def LRSLength(X, lookup):
  
 X = 'ATACTCGGA'
 
-lookup = []
-for y in range(len(X) + 1):
-    t = []
-    for x in range(len(X) + 1):
-        t.append(0)
-    lookup.append(t)
+lookup = [[0 for x in range(len(X) + 1)] for y in range(len(X) + 1)]
 
 
 LRSLength(X, lookup)
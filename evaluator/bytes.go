@@ -0,0 +1,521 @@
+package evaluator
+
+import (
+    "fmt"
+    "math"
+    "strings"
+    "unicode/utf8"
+    "unsafe"
+)
+
+/*
+    bytes is an immutable sequence of ints in range(0, 256), written
+    like b'abc'. Its items are ints, but a slice of it is bytes. It's
+    what str.encode gives, and bytes.decode turns it back into str.
+*/
+
+type Pybytes struct {
+    *objectData
+}
+
+func newPybytes() *Pybytes {
+    return &Pybytes{
+        objectData: &objectData{
+            d: newDictInst(),
+        },
+    }
+}
+
+func (pb *Pybytes) otype() Class { return Py_type }
+func (pb *Pybytes) cbase() Class { return Py_object }
+func (pb *Pybytes) id() int64 { return int64(uintptr(unsafe.Pointer(pb))) }
+
+var Py_bytes = newPybytes()
+func init() {
+    Py_bytes.attrs().set(__name__, newStringInst("bytes"))
+
+    Py_bytes.attrs().set(__new__, newBuiltinKwFunc(__new__,
+            func(objs []Object, kwargs *DictInst) Object {
+                if len(objs) > 4 {
+                    panic(newError(Py_TypeError, "bytes() takes at most 3 arguments (%v given)", len(objs)-1))
+                }
+                args := builtinKwargs("bytes", kwargs, "source", "encoding", "errors")
+                for i, obj := range objs[1:] {
+                    args[i] = obj
+                }
+
+                inst := newBytesInst(bytesFrom(args[0], args[1], args[2]))
+                inst.class = objs[0].(Class)
+                return inst
+            },
+        ),
+    )
+
+    Py_bytes.attrs().set(__hash__, newBuiltinFunc(__hash__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 0)
+                return newIntegerInst(hash([]byte(objs[0].(*BytesInst).Value)))
+            },
+        ),
+    )
+
+    Py_bytes.attrs().set(__eq__, newBytesCompare(__eq__, func(c int) bool { return c == 0 }))
+    Py_bytes.attrs().set(__gt__, newBytesCompare(__gt__, func(c int) bool { return c > 0 }))
+    Py_bytes.attrs().set(__lt__, newBytesCompare(__lt__, func(c int) bool { return c < 0 }))
+    Py_bytes.attrs().set(__ge__, newBytesCompare(__ge__, func(c int) bool { return c >= 0 }))
+    Py_bytes.attrs().set(__le__, newBytesCompare(__le__, func(c int) bool { return c <= 0 }))
+
+    reprFn := newBuiltinFunc(__repr__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 0)
+                return newStringInst(bytesRepr(objs[0].(*BytesInst).Value))
+            },
+        )
+    Py_bytes.attrs().set(__repr__, reprFn)
+    Py_bytes.attrs().set(__str__, reprFn)
+
+    Py_bytes.attrs().set(__len__, newBuiltinFunc(__len__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 0)
+                return newIntegerInst(int64(len(objs[0].(*BytesInst).Value)))
+            },
+        ),
+    )
+
+    Py_bytes.attrs().set(__getitem__, newBuiltinFunc(__getitem__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 1)
+                self := objs[0].(*BytesInst)
+                switch key := objs[1].(type) {
+                case *IntegerInst:
+                    return newIntegerInst(int64(self.Value[seqIndex(key, len(self.Value), "index out of range")]))
+                case *SliceInst:
+                    start, _, step, n := key.indices(len(self.Value))
+                    b := make([]byte, n)
+                    for i := range b {
+                        b[i] = self.Value[start+i*step]
+                    }
+                    return newBytesInst(string(b))
+                }
+                panic(newError(Py_TypeError, "byte indices must be integers or slices, not %v", typeName(objs[1])))
+            },
+        ),
+    )
+
+    Py_bytes.attrs().set(__iter__, newBuiltinFunc(__iter__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 0)
+                return newSeqIteratorInst(objs[0])
+            },
+        ),
+    )
+
+    Py_bytes.attrs().set(__contains__, newBuiltinFunc(__contains__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 1)
+                self := objs[0].(*BytesInst)
+                if n, ok := objs[1].(*IntegerInst); ok {
+                    return newBoolInst(strings.IndexByte(self.Value, byteValue(n)) >= 0)
+                }
+                return newBoolInst(strings.Contains(self.Value, bytesArg(objs[1])))
+            },
+        ),
+    )
+
+    Py_bytes.attrs().set(__add__, newBuiltinFunc(__add__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 1)
+                other, ok := objs[1].(*BytesInst)
+                if !ok {
+                    panic(newError(Py_TypeError, "can't concat %v to bytes", typeName(objs[1])))
+                }
+                return newBytesInst(objs[0].(*BytesInst).Value + other.Value)
+            },
+        ),
+    )
+
+    bytesMul := newBuiltinFunc(__mul__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 1)
+                n, ok := objs[1].(*IntegerInst)
+                if !ok {
+                    return Py_NotImplemented
+                }
+                if n.big != nil {
+                    panic(newError(Py_OverflowError, "cannot fit 'int' into an index-sized integer"))
+                }
+
+                self := objs[0].(*BytesInst)
+                if n.Value <= 0 || self.Value == "" {
+                    return newBytesInst("")
+                }
+                if int64(len(self.Value)) > math.MaxInt32/n.Value {
+                    panic(newError(Py_OverflowError, "repeated bytes are too long"))
+                }
+                return newBytesInst(strings.Repeat(self.Value, int(n.Value)))
+            },
+        )
+    Py_bytes.attrs().set(__mul__, bytesMul)
+    Py_bytes.attrs().set(__rmul__, bytesMul)
+
+    setBytesMethod("decode", func(objs []Object, kwargs *DictInst) Object {
+        if len(objs) > 3 {
+            panic(newError(Py_TypeError, "decode() takes at most 2 arguments (%v given)", len(objs)-1))
+        }
+        args := builtinKwargs("decode", kwargs, "encoding", "errors")
+        for i, obj := range objs[1:] {
+            args[i] = obj
+        }
+        encoding, errors := codecArgs("decode", args[0], args[1])
+        return newStringInst(decodeBytes(objs[0].(*BytesInst).Value, encoding, errors))
+    })
+
+    setBytesMethod("hex", func(objs []Object, kwargs *DictInst) Object {
+        if len(objs) > 1 || kwargs != nil && kwargs.len() > 0 {
+            panic(newError(Py_TypeError, "hex() takes no arguments"))
+        }
+        return newStringInst(fmt.Sprintf("%x", objs[0].(*BytesInst).Value))
+    })
+
+    setBytesMethod("join", func(objs []Object, kwargs *DictInst) Object {
+        if len(objs) != 2 || kwargs != nil && kwargs.len() > 0 {
+            panic(newError(Py_TypeError, "bytes.join() takes exactly one argument (%v given)", len(objs)-1))
+        }
+
+        var items []string
+        for i, item := range iterItems(objs[1]) {
+            b, ok := item.(*BytesInst)
+            if !ok {
+                panic(newError(Py_TypeError, "sequence item %v: expected a bytes-like object, %v found", i, typeName(item)))
+            }
+            items = append(items, b.Value)
+        }
+        return newBytesInst(strings.Join(items, objs[0].(*BytesInst).Value))
+    })
+
+    setBytesMethod("startswith", newBytesAffixCheck("startswith", strings.HasPrefix))
+    setBytesMethod("endswith", newBytesAffixCheck("endswith", strings.HasSuffix))
+
+    setBytesMethod("find", func(objs []Object, kwargs *DictInst) Object {
+        if len(objs) != 2 || kwargs != nil && kwargs.len() > 0 {
+            panic(newError(Py_TypeError, "find() takes exactly one argument (%v given)", len(objs)-1))
+        }
+        self := objs[0].(*BytesInst).Value
+        if n, ok := objs[1].(*IntegerInst); ok {
+            return newIntegerInst(int64(strings.IndexByte(self, byteValue(n))))
+        }
+        return newIntegerInst(int64(strings.Index(self, bytesArg(objs[1]))))
+    })
+
+    setBytesMethod("count", func(objs []Object, kwargs *DictInst) Object {
+        if len(objs) != 2 || kwargs != nil && kwargs.len() > 0 {
+            panic(newError(Py_TypeError, "count() takes exactly one argument (%v given)", len(objs)-1))
+        }
+        self := objs[0].(*BytesInst).Value
+        if n, ok := objs[1].(*IntegerInst); ok {
+            return newIntegerInst(int64(strings.Count(self, string([]byte{byteValue(n)}))))
+        }
+        return newIntegerInst(int64(strings.Count(self, bytesArg(objs[1]))))
+    })
+
+    setStrMethod("encode", func(objs []Object, kwargs *DictInst) Object {
+        if len(objs) > 3 {
+            panic(newError(Py_TypeError, "encode() takes at most 2 arguments (%v given)", len(objs)-1))
+        }
+        args := builtinKwargs("encode", kwargs, "encoding", "errors")
+        for i, obj := range objs[1:] {
+            args[i] = obj
+        }
+        encoding, errors := codecArgs("encode", args[0], args[1])
+        return newBytesInst(encodeStr(objs[0].(*StringInst).Value, encoding, errors))
+    })
+}
+
+func setBytesMethod(name string, f builtinKwFn) {
+    Py_bytes.attrs().set(newStringInst(name), newBuiltinKwFunc(newStringInst(name), f))
+}
+
+// newBytesCompare makes a comparison method of bytes, which tells by
+// the sign of strings.Compare
+func newBytesCompare(name *StringInst, cmp func(int) bool) *BuiltinFunctionInst {
+    return newBuiltinFunc(name,
+        func(objs ...Object) Object {
+            checkSlotArgs(objs, 1)
+            other, ok := objs[1].(*BytesInst)
+            if !ok {
+                return Py_NotImplemented
+            }
+            return newBoolInst(cmp(strings.Compare(objs[0].(*BytesInst).Value, other.Value)))
+        },
+    )
+}
+
+func newBytesAffixCheck(name string, check func(string, string) bool) builtinKwFn {
+    return func(objs []Object, kwargs *DictInst) Object {
+        if len(objs) != 2 || kwargs != nil && kwargs.len() > 0 {
+            panic(newError(Py_TypeError, "%v() takes exactly one argument (%v given)", name, len(objs)-1))
+        }
+        self := objs[0].(*BytesInst).Value
+
+        if affixes, ok := objs[1].(*TupleInst); ok {
+            for _, affix := range affixes.items {
+                if check(self, bytesArg(affix)) {
+                    return Py_True
+                }
+            }
+            return Py_False
+        }
+        b, ok := objs[1].(*BytesInst)
+        if !ok {
+            panic(newError(Py_TypeError, "%v first arg must be bytes or a tuple of bytes, not %v", name, typeName(objs[1])))
+        }
+        return newBoolInst(check(self, b.Value))
+    }
+}
+
+// bytesFrom gives the bytes made by bytes(source, encoding, errors),
+// where any of them can be nil, as not given
+func bytesFrom(source, encoding, errors Object) string {
+    if s, ok := source.(*StringInst); ok {
+        if encoding == nil {
+            panic(newError(Py_TypeError, "string argument without an encoding"))
+        }
+        enc, errs := codecArgs("bytes", encoding, errors)
+        return encodeStr(s.Value, enc, errs)
+    }
+    if encoding != nil {
+        panic(newError(Py_TypeError, "encoding without a string argument"))
+    }
+    if errors != nil {
+        panic(newError(Py_TypeError, "errors without a string argument"))
+    }
+
+    switch source := source.(type) {
+    case nil:
+        return ""
+    case *BytesInst:
+        return source.Value
+    case *IntegerInst:
+        n := asInt(source)
+        if n < 0 {
+            panic(newError(Py_ValueError, "negative count"))
+        }
+        return string(make([]byte, n))
+    }
+
+    if attrItself(source.otype(), __iter__) == nil && attrItself(source.otype(), __getitem__) == nil {
+        panic(newError(Py_TypeError, "cannot convert '%v' object to bytes", typeName(source)))
+    }
+    items := iterItems(source)
+    b := make([]byte, len(items))
+    for i, item := range items {
+        n, ok := item.(*IntegerInst)
+        if !ok {
+            panic(newError(Py_TypeError, "'%v' object cannot be interpreted as an integer", typeName(item)))
+        }
+        if n.big != nil || n.Value < 0 || n.Value > 255 {
+            panic(newError(Py_ValueError, "bytes must be in range(0, 256)"))
+        }
+        b[i] = byte(n.Value)
+    }
+    return string(b)
+}
+
+// byteValue gives n as a byte, which it must fit in
+func byteValue(n *IntegerInst) byte {
+    if n.big != nil || n.Value < 0 || n.Value > 255 {
+        panic(newError(Py_ValueError, "byte must be in range(0, 256)"))
+    }
+    return byte(n.Value)
+}
+
+func bytesArg(obj Object) string {
+    b, ok := obj.(*BytesInst)
+    if !ok {
+        panic(newError(Py_TypeError, "a bytes-like object is required, not '%v'", typeName(obj)))
+    }
+    return b.Value
+}
+
+// codecArgs gives the encoding and errors arguments of fname, which
+// default to utf-8 and strict
+func codecArgs(fname string, encoding, errors Object) (string, string) {
+    enc, errs := "utf-8", "strict"
+    if encoding != nil {
+        enc = strArg(encoding, fname + "() argument 'encoding' must be str, not %v")
+    }
+    if errors != nil {
+        errs = strArg(errors, fname + "() argument 'errors' must be str, not %v")
+    }
+    return enc, errs
+}
+
+// codecName gives the name an encoding is known by in the messages
+// of its errors. Only utf-8, ascii and latin-1 are there
+func codecName(encoding string) string {
+    switch strings.ReplaceAll(strings.ToLower(encoding), "_", "-") {
+    case "utf-8", "utf8", "u8":
+        return "utf-8"
+    case "ascii", "us-ascii":
+        return "ascii"
+    case "latin-1", "latin1", "iso-8859-1", "l1":
+        return "latin-1"
+    }
+    panic(newError(Py_LookupError, "unknown encoding: %v", encoding))
+}
+
+func checkErrors(errors string) {
+    if errors != "strict" && errors != "ignore" && errors != "replace" {
+        panic(newError(Py_LookupError, "unknown error handler name '%v'", errors))
+    }
+}
+
+func encodeStr(s, encoding, errors string) string {
+    codec := codecName(encoding)
+    if codec == "utf-8" {
+        return s
+    }
+    checkErrors(errors)
+
+    limit := rune(0x80)
+    if codec == "latin-1" {
+        limit = 0x100
+    }
+    var b []byte
+    i := 0
+    for _, r := range s {
+        if r < limit {
+            b = append(b, byte(r))
+        } else if errors == "replace" {
+            b = append(b, '?')
+        } else if errors == "strict" {
+            panic(newError(Py_UnicodeEncodeError, "'%v' codec can't encode character '%v' in position %v: ordinal not in range(%v)",
+                codec, escapeRune(r), i, limit))
+        }
+        i++
+    }
+    return string(b)
+}
+
+func decodeBytes(b, encoding, errors string) string {
+    codec := codecName(encoding)
+    checkErrors(errors)
+
+    var res strings.Builder
+    for i := 0; i < len(b); {
+        r, size := rune(b[i]), 1
+        reason := ""
+        switch {
+        case codec == "utf-8":
+            r, size = utf8.DecodeRuneInString(b[i:])
+            if r == utf8.RuneError && size == 1 {
+                reason = utf8Fault(b[i:])
+            }
+        case codec == "ascii" && r >= 0x80:
+            reason = "ordinal not in range(128)"
+        }
+
+        if reason == "" {
+            res.WriteRune(r)
+        } else if errors == "replace" {
+            res.WriteRune(utf8.RuneError)
+        } else if errors == "strict" {
+            panic(newError(Py_UnicodeDecodeError, "'%v' codec can't decode byte 0x%02x in position %v: %v", codec, b[i], i, reason))
+        }
+        i += size
+    }
+    return res.String()
+}
+
+// utf8Fault tells why b doesn't start with a valid UTF-8 sequence
+func utf8Fault(b string) string {
+    c := b[0]
+    var n int
+    switch {
+    case 0xC2 <= c && c <= 0xDF:
+        n = 2
+    case 0xE0 <= c && c <= 0xEF:
+        n = 3
+    case 0xF0 <= c && c <= 0xF4:
+        n = 4
+    default:
+        return "invalid start byte"
+    }
+    if len(b) < n {
+        for i := 1; i < len(b); i++ {
+            if b[i] & 0xC0 != 0x80 {
+                return "invalid continuation byte"
+            }
+        }
+        return "unexpected end of data"
+    }
+    return "invalid continuation byte"
+}
+
+// escapeRune gives r escaped like \xhh, \uhhhh or \Uhhhhhhhh,
+// by how many hex digits it needs
+func escapeRune(r rune) string {
+    switch {
+    case r < 0x100:
+        return fmt.Sprintf("\\x%02x", r)
+    case r < 0x10000:
+        return fmt.Sprintf("\\u%04x", r)
+    }
+    return fmt.Sprintf("\\U%08x", r)
+}
+
+// bytesRepr gives b like b'...', where the bytes that aren't
+// printable ASCII are escaped
+func bytesRepr(b string) string {
+    quote := reprQuote(b)
+    var res strings.Builder
+    res.WriteString("b")
+    res.WriteByte(quote)
+    for i := 0; i < len(b); i++ {
+        switch c := b[i]; {
+        case c == quote || c == '\\':
+            res.WriteByte('\\')
+            res.WriteByte(c)
+        case c == '\t':
+            res.WriteString("\\t")
+        case c == '\n':
+            res.WriteString("\\n")
+        case c == '\r':
+            res.WriteString("\\r")
+        case c < ' ' || c >= 0x7f:
+            fmt.Fprintf(&res, "\\x%02x", c)
+        default:
+            res.WriteByte(c)
+        }
+    }
+    res.WriteByte(quote)
+    return res.String()
+}
+
+// reprQuote gives the quote that a repr of s is put in, which is the
+// single one unless s has it but not the double one
+func reprQuote(s string) byte {
+    if strings.Contains(s, "'") && !strings.Contains(s, "\"") {
+        return '"'
+    }
+    return '\''
+}
+
+type BytesInst struct {
+    *objectData
+    Value   string  // the bytes, which needn't be UTF-8
+    class   Class
+}
+
+func newBytesInst(b string) *BytesInst {
+    return &BytesInst{
+        objectData: &objectData{
+            d: newDictInst(),
+        },
+        Value: b,
+        class: Py_bytes,
+    }
+}
+
+func (b *BytesInst) otype() Class { return b.class }
+func (b *BytesInst) id() int64 { return int64(uintptr(unsafe.Pointer(b))) }
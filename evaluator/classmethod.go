@@ -0,0 +1,143 @@
+package evaluator
+
+import (
+    "fmt"
+    "unsafe"
+)
+
+/*
+    staticmethod and classmethod wrap a function defined in a class body,
+    changing what it's bound to when got through the class or an instance.
+    A staticmethod gives the function as it is, while a classmethod binds
+    it to the class, the type of the instance if it's got from one.
+*/
+
+type Pystaticmethod struct {
+    *objectData
+}
+
+func newPystaticmethod() *Pystaticmethod {
+    return &Pystaticmethod{
+        objectData: &objectData{
+            d: newDictInst(),
+        },
+    }
+}
+
+func (ps *Pystaticmethod) otype() Class { return Py_type }
+func (ps *Pystaticmethod) cbase() Class { return Py_object }
+func (ps *Pystaticmethod) id() int64 { return int64(uintptr(unsafe.Pointer(ps))) }
+
+var Py_staticmethod = newPystaticmethod()
+func init() {
+    Py_staticmethod.attrs().set(__name__, newStringInst("staticmethod"))
+
+    Py_staticmethod.attrs().set(__new__, newBuiltinFunc(__new__,
+            func(objs ...Object) Object {
+                if len(objs) != 2 {
+                    panic(newError(Py_TypeError, "staticmethod expected 1 argument, got %v", len(objs)-1))
+                }
+                return newWrappedMethod(Py_staticmethod, objs[1])
+            },
+        ),
+    )
+
+    Py_staticmethod.attrs().set(__get__, newBuiltinFunc(__get__,
+            func(objs ...Object) Object {
+                return objs[0].(*WrappedMethodInst).f
+            },
+        ),
+    )
+
+    // a staticmethod can be called as it is, like in the class body
+    Py_staticmethod.attrs().set(__call__, newBuiltinKwFunc(__call__,
+            func(objs []Object, kwargs *DictInst) Object {
+                return op_CALL_KW(objs[0].(*WrappedMethodInst).f, objs[1:], kwargs)
+            },
+        ),
+    )
+
+    Py_staticmethod.attrs().set(__repr__, wrappedMethodRepr)
+}
+
+type Pyclassmethod struct {
+    *objectData
+}
+
+func newPyclassmethod() *Pyclassmethod {
+    return &Pyclassmethod{
+        objectData: &objectData{
+            d: newDictInst(),
+        },
+    }
+}
+
+func (pc *Pyclassmethod) otype() Class { return Py_type }
+func (pc *Pyclassmethod) cbase() Class { return Py_object }
+func (pc *Pyclassmethod) id() int64 { return int64(uintptr(unsafe.Pointer(pc))) }
+
+var Py_classmethod = newPyclassmethod()
+func init() {
+    Py_classmethod.attrs().set(__name__, newStringInst("classmethod"))
+
+    Py_classmethod.attrs().set(__new__, newBuiltinFunc(__new__,
+            func(objs ...Object) Object {
+                if len(objs) != 2 {
+                    panic(newError(Py_TypeError, "classmethod expected 1 argument, got %v", len(objs)-1))
+                }
+                return newWrappedMethod(Py_classmethod, objs[1])
+            },
+        ),
+    )
+
+    Py_classmethod.attrs().set(__get__, newBuiltinFunc(__get__,
+            func(objs ...Object) Object {
+                self, obj := objs[0].(*WrappedMethodInst), objs[1]
+                var owner Object
+                if len(objs) > 2 {
+                    owner = objs[2]
+                }
+                if owner == nil || owner == Py_None {
+                    owner = obj.otype()
+                }
+                f, ok := self.f.(Function)
+                if !ok {
+                    panic(newError(Py_TypeError, "'%v' object is not callable", typeName(self.f)))
+                }
+                return newMethod(owner, f)
+            },
+        ),
+    )
+
+    Py_classmethod.attrs().set(__repr__, wrappedMethodRepr)
+}
+
+var wrappedMethodRepr = newBuiltinFunc(__repr__,
+    func(objs ...Object) Object {
+        self := objs[0].(*WrappedMethodInst)
+        return newStringInst(fmt.Sprintf("<%v(%v)>", typeName(self), ReprOf(self.f)))
+    },
+)
+
+// WrappedMethodInst is a staticmethod or classmethod, which are the same
+// but for their class
+type WrappedMethodInst struct {
+    *objectData
+    class   Class
+    f       Object
+}
+
+func newWrappedMethod(class Class, f Object) *WrappedMethodInst {
+    m := &WrappedMethodInst{
+        objectData: &objectData{
+            d: newDictInst(),
+        },
+        class: class,
+        f: f,
+    }
+    m.attrs().set(__func__, f)
+    return m
+}
+
+func (m *WrappedMethodInst) otype() Class { return m.class }
+func (m *WrappedMethodInst) id() int64 { return int64(uintptr(unsafe.Pointer(m))) }
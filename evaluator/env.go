@@ -1,22 +1,54 @@
 package evaluator
 
+import (
+    "github.com/realyixuan/gsubpy/ast"
+)
+
 var __builtins__ = map[string] Object{
     "object": Py_object,
     "True": Py_True,
     "False": Py_False,
     "None": Py_None,
+    "NotImplemented": Py_NotImplemented,
     "print": Py_print,
     "type": Py_type,
     "len": Py_len,
     "bool": Py_bool,
     "hash": Py_hash,
     "id": Py_id,
+    "BaseException": Py_BaseException,
+    "GeneratorExit": Py_GeneratorExit,
     "Exception": Py_Exception,
     "StopIteration": Py_StopIteration,
+    "ArithmeticError": Py_ArithmeticError,
+    "OverflowError": Py_OverflowError,
+    "ZeroDivisionError": Py_ZeroDivisionError,
+    "AssertionError": Py_AssertionError,
+    "AttributeError": Py_AttributeError,
+    "LookupError": Py_LookupError,
+    "IndexError": Py_IndexError,
+    "KeyError": Py_KeyError,
+    "NameError": Py_NameError,
+    "UnboundLocalError": Py_UnboundLocalError,
+    "RuntimeError": Py_RuntimeError,
+    "RecursionError": Py_RecursionError,
+    "SyntaxError": Py_SyntaxError,
+    "IndentationError": Py_IndentationError,
+    "TypeError": Py_TypeError,
+    "ValueError": Py_ValueError,
+    "UnicodeError": Py_UnicodeError,
+    "UnicodeDecodeError": Py_UnicodeDecodeError,
+    "UnicodeEncodeError": Py_UnicodeEncodeError,
 
     "int": Py_int,
+    "float": Py_float,
     "str": Py_str,
+    "bytes": Py_bytes,
     "list": Py_list,
+    "tuple": Py_tuple,
+    "slice": Py_slice,
+    "set": Py_set,
+    "frozenset": Py_frozenset,
     "dict": Py_dict,
 
     "isinstance": Py_isinstance,
@@ -28,13 +60,36 @@ var __builtins__ = map[string] Object{
 
     "max": Py_max,
     "min": Py_min,
+    "round": Py_round,
+    "sorted": Py_sorted,
+
+    "super": Py_super,
+    "property": Py_property,
+    "staticmethod": Py_staticmethod,
+    "classmethod": Py_classmethod,
 
     "dir": Py_dir,
+    "repr": Py_repr,
+    "ascii": Py_ascii,
+    "format": Py_format,
+    "getattr": Py_getattr,
+    "setattr": Py_setattr,
+    "hasattr": Py_hasattr,
+    "delattr": Py_delattr,
+    "ord": Py_ord,
+    "chr": Py_chr,
 }
 
+// Environment is where the names of a scope are bound. Builtins are at
+// the root, with the module env under them, and each call of a function
+// and each class body derive one of their own from where they're defined
 type Environment struct {
     store     *DictInst
     parent    *Environment
+    scope     *ast.Scope        // nil for the module and comprehensions
+    isClass   bool
+    generator *genFrame         // set on the local env of a generator
+    function  *FunctionInst     // set on the local env of a call
 }
 
 func NewEnvironment() *Environment {
@@ -57,25 +112,90 @@ func (e *Environment) SetFromString(key string, value Object) {
     e.Set(newStringInst(key), value)
 }
 
+// Set binds key in this env, or where it's declared global or nonlocal
 func (self *Environment) Set(key *StringInst, value Object) {
+    if self.scope != nil {
+        if self.scope.Globals[key.Value] {
+            self.globals().store.set(key, value)
+            return
+        }
+        if self.scope.Nonlocals[key.Value] {
+            for env := self.outer(); env.parent != nil; env = env.outer() {
+                if env.isLocal(key) {
+                    env.store.set(key, value)
+                    return
+                }
+            }
+        }
+    }
     self.store.set(key, value)
 }
 
+// Delete unbinds key where Set would bind it, it's false if key isn't bound there
+func (self *Environment) Delete(key *StringInst) bool {
+    if self.scope != nil {
+        if self.scope.Globals[key.Value] {
+            return self.globals().store.delete(key)
+        }
+        if self.scope.Nonlocals[key.Value] {
+            for env := self.outer(); env.parent != nil; env = env.outer() {
+                if env.isLocal(key) {
+                    return env.store.delete(key)
+                }
+            }
+        }
+    }
+    return self.store.delete(key)
+}
+
 func (e *Environment) GetFromString(key string) Object {
     return e.Get(newStringInst(key))
 }
 
+// Get looks key up by the LEGB rule, it's nil if key isn't bound,
+// including when it's a local of a function not assigned yet
 func (self *Environment) Get(key *StringInst) Object {
     if self.parent == nil {
         val := self.store.get(key)
         return val
     }
 
+    if self.scope != nil && self.scope.Globals[key.Value] {
+        return self.globals().Get(key)
+    }
+
     if obj := self.store.get(key); obj != nil {
         return obj
+    } else if self.isLocal(key) {
+        return nil
     } else {
-        return self.parent.Get(key)
+        return self.outer().Get(key)
+    }
+}
+
+// isLocal tells if key is a local of a function, which is never
+// looked up outside of it. Class bodies fall back on globals instead
+func (self *Environment) isLocal(key *StringInst) bool {
+    return self.scope != nil && !self.isClass && self.scope.Locals[key.Value]
+}
+
+// outer is the env enclosing this one, the names
+// of class bodies are not seen from inside them
+func (self *Environment) outer() *Environment {
+    env := self.parent
+    for env.isClass {
+        env = env.parent
+    }
+    return env
+}
+
+// globals is the env of the module
+func (self *Environment) globals() *Environment {
+    env := self
+    for env.parent.parent != nil {
+        env = env.parent
     }
+    return env
 }
 
 func (self *Environment) DeriveEnv() *Environment {
@@ -85,6 +205,22 @@ func (self *Environment) DeriveEnv() *Environment {
     }
 }
 
+// deriveScope derives the env of a call to a function or a class body
+func (self *Environment) deriveScope(scope *ast.Scope, isClass bool) *Environment {
+    env := self.DeriveEnv()
+    env.scope = scope
+    env.isClass = isClass
+    return env
+}
+
+// classCellScope is that of the env between a class body and the
+// env it's in, which has the class bound to `__class__` for super()
+var classCellScope = &ast.Scope{
+    Locals: map[string]bool{"__class__": true},
+    Globals: map[string]bool{},
+    Nonlocals: map[string]bool{},
+}
+
 func (self *Environment) Store() *DictInst {
     return self.store
 }
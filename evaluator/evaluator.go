@@ -1,8 +1,8 @@
 package evaluator
 
 import (
-    "fmt"
     "strconv"
+    "strings"
 
     "github.com/realyixuan/gsubpy/ast"
     "github.com/realyixuan/gsubpy/token"
@@ -21,8 +21,9 @@ func Exec(stmts []ast.Statement, env *Environment) (Object, quitType) {
     var s ast.Statement
     defer func() {
         if r := recover(); r != nil {
-            f := Frame{Literals: s.GetLiterals()}
-            Py_traceback.append(f)
+            if e, ok := r.(*ExceptionInst); ok {
+                e.Traceback.append(Frame{Literals: s.GetLiterals()})
+            }
             panic(r)
         }
     }()
@@ -32,6 +33,10 @@ func Exec(stmts []ast.Statement, env *Environment) (Object, quitType) {
         switch node := stmt.(type) {
         case *ast.AssignStatement:
             execAssignStatement(node, env)
+        case *ast.AugAssignStatement:
+            execAugAssignStatement(node, env)
+        case *ast.DelStatement:
+            deleteTarget(node.Target, env)
         case *ast.IfStatement:
             rv, why := execIfStatement(node, env)
             if why != END {
@@ -54,11 +59,21 @@ func Exec(stmts []ast.Statement, env *Environment) (Object, quitType) {
         case *ast.ExpressionStatement:
             Eval(node, env)
         case *ast.ReturnStatement:
+            if node.Value == nil {
+                return Py_None, RETURN
+            }
             return Eval(node.Value, env), RETURN
         case *ast.RaiseStatement:
              execRaiseStatement(node.Value, env)
         case *ast.AssertStatement:
             execAssertStatement(node, env)
+        case *ast.TryStatement:
+            rv, why := execTryStatement(node, env)
+            if why != END {
+                return rv, why
+            }
+        case *ast.GlobalStatement, *ast.NonlocalStatement:
+            // declarations are taken care of by the parser
         case *ast.BreakStatement:
             return nil, BREAK
         case *ast.ContinueStatement:
@@ -71,7 +86,16 @@ func Exec(stmts []ast.Statement, env *Environment) (Object, quitType) {
 func Eval(expression ast.Expression, env *Environment) Object {
     switch node := expression.(type) {
     case *ast.IdentifierExpression:
-        return env.Get(newStringInst(node.Identifier.Literals))
+        name := newStringInst(node.Identifier.Literals)
+        val := env.Get(name)
+        if val == nil {
+            if env.isLocal(name) {
+                panic(newError(Py_UnboundLocalError,
+                    "cannot access local variable '%v' where it is not associated with a value", name))
+            }
+            panic(newError(Py_NameError, "name '%v' is not defined", name))
+        }
+        return val
     case *ast.PlusExpression:
         left := Eval(node.Left, env)
         right := Eval(node.Right, env)
@@ -88,64 +112,128 @@ func Eval(expression ast.Expression, env *Environment) Object {
         left := Eval(node.Left, env)
         right := Eval(node.Right, env)
         return op_DIV(left, right)
+    case *ast.FloorDivExpression:
+        left := Eval(node.Left, env)
+        right := Eval(node.Right, env)
+        return op_FLOORDIV(left, right)
+    case *ast.ModExpression:
+        left := Eval(node.Left, env)
+        right := Eval(node.Right, env)
+        return op_MOD(left, right)
+    case *ast.PowExpression:
+        left := Eval(node.Left, env)
+        right := Eval(node.Right, env)
+        return op_POW(left, right)
+    case *ast.LShiftExpression:
+        left := Eval(node.Left, env)
+        right := Eval(node.Right, env)
+        return op_LSHIFT(left, right)
+    case *ast.RShiftExpression:
+        left := Eval(node.Left, env)
+        right := Eval(node.Right, env)
+        return op_RSHIFT(left, right)
+    case *ast.BitAndExpression:
+        left := Eval(node.Left, env)
+        right := Eval(node.Right, env)
+        return op_AND(left, right)
+    case *ast.BitOrExpression:
+        left := Eval(node.Left, env)
+        right := Eval(node.Right, env)
+        return op_OR(left, right)
+    case *ast.BitXorExpression:
+        left := Eval(node.Left, env)
+        right := Eval(node.Right, env)
+        return op_XOR(left, right)
+    case *ast.UnaryExpression:
+        obj := Eval(node.Expr, env)
+        switch node.Operator.Type {
+        case token.MINUS:
+            return op_NEG(obj)
+        case token.PLUS:
+            return op_POS(obj)
+        default:
+            return op_INVERT(obj)
+        }
     case *ast.ComparisonExpression:
+        // `a < b < c` is `a < b and b < c`, except that b is
+        // evaluated only once
+        var res Object
         leftObj := Eval(node.Left, env)
-        rightObj := Eval(node.Right, env)
-        switch node.Operator.Type {
-        case token.GT:
-            return op_GT(leftObj, rightObj)
-        case token.LT:
-            return op_LT(leftObj, rightObj)
-        case token.EQ:
-            return op_EQ(leftObj, rightObj)
-        case token.NEQ:
-            return op_NEQ(leftObj, rightObj)
-        case token.IN:
-            return op_IN(leftObj, rightObj)
-        case token.NIN:
-            return op_NIN(leftObj, rightObj)
-        case token.IS:
-            return op_IS(leftObj, rightObj)
-        case token.ISN:
-            return op_ISN(leftObj, rightObj)
+        for i, op := range node.Operators {
+            rightObj := Eval(node.Comparators[i], env)
+            res = evalComparison(op, leftObj, rightObj)
+            if i < len(node.Operators)-1 && !isTrue(res) {
+                return res
+            }
+            leftObj = rightObj
         }
-        return Py_True
+        return res
+    case *ast.YieldExpression:
+        if node.From {
+            return env.generator.yieldFrom(Eval(node.Value, env))
+        }
+        var value Object = Py_None
+        if node.Value != nil {
+            value = Eval(node.Value, env)
+        }
+        return env.generator.yield(value)
+    case *ast.LambdaExpression:
+        body := []ast.Statement{&ast.ReturnStatement{Value: node.Body, Literals: node.Literals}}
+        funcObj := newFunctionInst(newStringInst("<lambda>"), evalParameters(node.Params, env), body, env)
+        funcObj.scope = node.Scope
+        funcObj.isGenerator = node.IsGenerator
+        return funcObj
     case *ast.NotExpression:
         obj := Eval(node.Expr, env)
-        if op_CALL(Py_bool, obj) == Py_True {
+        if isTrue(obj) {
             return Py_False
         } else {
             return Py_True
         }
     case *ast.AndExpression:
         leftObj := Eval(node.Left, env)
-        rightObj := Eval(node.Right, env)
-
-        if op_CALL(Py_bool, leftObj) == Py_False {
+        if !isTrue(leftObj) {
             return leftObj
         }
-
-        return rightObj
+        return Eval(node.Right, env)
     case *ast.OrExpression:
         leftObj := Eval(node.Left, env)
-        rightObj := Eval(node.Right, env)
-
-        if op_CALL(Py_bool, leftObj) == Py_True {
+        if isTrue(leftObj) {
             return leftObj
         }
-
-        return rightObj
+        return Eval(node.Right, env)
     case *ast.NumberExpression:
-        val, _ := strconv.Atoi(node.Value.Literals)
-        return newIntegerInst(int64(val))
+        if node.Value.Type == token.FLOAT {
+            val, _ := strconv.ParseFloat(node.Value.Literals, 64)
+            return newFloatInst(val)
+        }
+        return newIntegerFromString(node.Value.Literals)
     case *ast.StringExpression:
+        if node.Value.Type == token.BYTES {
+            return newBytesInst(node.Value.Literals)
+        }
         return newStringInst(node.Value.Literals)
+    case *ast.FStringExpression:
+        return newStringInst(evalFString(node, env))
+    case *ast.FormattedExpression:
+        spec := ""
+        if node.Spec != nil {
+            spec = evalFString(node.Spec, env)
+        }
+        obj := convertValue(Eval(node.Value, env), node.Conversion)
+        return newStringInst(formatValue(obj, spec))
     case *ast.ListExpression:
         listObj := newListInst()
-        for _, item := range node.Items {
-            listObj.items = append(listObj.items, Eval(item, env))
-        }
+        listObj.items = append(listObj.items, evalItems(node.Items, env)...)
         return listObj
+    case *ast.TupleExpression:
+        return newTupleInst(evalItems(node.Items, env))
+    case *ast.SetExpression:
+        set := newSetInst()
+        for _, item := range evalItems(node.Items, env) {
+            set.add(item)
+        }
+        return set
     case *ast.DictExpression:
         dict := newDictInst()
         for i := 0; i < len(node.Keys); i++ {
@@ -153,8 +241,45 @@ func Eval(expression ast.Expression, env *Environment) Object {
             op_SUBSCR_SET(dict, k, v)
         }
         return dict
+    case *ast.ListCompExpression:
+        listObj := newListInst()
+        evalComprehension(node.Fors, env, func(scope *Environment) {
+            listObj.items = append(listObj.items, Eval(node.Elt, scope))
+        })
+        return listObj
+    case *ast.SetCompExpression:
+        set := newSetInst()
+        evalComprehension(node.Fors, env, func(scope *Environment) {
+            set.add(Eval(node.Elt, scope))
+        })
+        return set
+    case *ast.DictCompExpression:
+        dict := newDictInst()
+        evalComprehension(node.Fors, env, func(scope *Environment) {
+            k, v := Eval(node.Key, scope), Eval(node.Val, scope)
+            op_SUBSCR_SET(dict, k, v)
+        })
+        return dict
+    case *ast.GeneratorExpression:
+        // only the first iterable is evaluated right away,
+        // the rest is left until the generator is run
+        iterator := op_CALL(Py_iter, Eval(node.Fors[0].Iter, env))
+        return newGeneratorInst(newStringInst("<genexpr>"), func(g *genFrame) Object {
+            runComprehension(node.Fors, iterator, env.DeriveEnv(), func(scope *Environment) {
+                g.yield(Eval(node.Elt, scope))
+            })
+            return Py_None
+        })
     case *ast.SubscriptExpression:
         return op_SUBSCR_GET(Eval(node.Target, env), Eval(node.Val, env))
+    case *ast.SliceExpression:
+        bounds := []Object{Py_None, Py_None, Py_None}
+        for i, expr := range []ast.Expression{node.Start, node.Stop, node.Step} {
+            if expr != nil {
+                bounds[i] = Eval(expr, env)
+            }
+        }
+        return newSliceInst(bounds[0], bounds[1], bounds[2])
     case *ast.CallExpression:
         return evalCallExpression(node, env)
     case *ast.AttributeExpression:
@@ -166,9 +291,57 @@ func Eval(expression ast.Expression, env *Environment) Object {
     return Py_None
 }
 
+// evalItems evaluates the items of a list or tuple display,
+// spreading the starred ones
+func evalItems(exprs []ast.Expression, env *Environment) []Object {
+    items := []Object{}
+    for _, expr := range exprs {
+        if starred, ok := expr.(*ast.StarredExpression); ok {
+            items = append(items, iterItems(Eval(starred.Value, env))...)
+        } else {
+            items = append(items, Eval(expr, env))
+        }
+    }
+    return items
+}
+
+// evalFString gives the text of an f-string, its literals and the
+// formatted values of its fields joined together
+func evalFString(node *ast.FStringExpression, env *Environment) string {
+    var b strings.Builder
+    for _, part := range node.Parts {
+        b.WriteString(Eval(part, env).(*StringInst).Value)
+    }
+    return b.String()
+}
+
 func execAssignStatement(stmt *ast.AssignStatement, env *Environment) {
     val := Eval(stmt.Value, env)
-    switch attr := stmt.Target.(type) {
+    for _, target := range stmt.Targets {
+        assignTarget(target, val, env)
+    }
+}
+
+// execAugAssignStatement does `target op= value`, evaluating the
+// object and the key or attribute name of target only once
+func execAugAssignStatement(stmt *ast.AugAssignStatement, env *Environment) {
+    switch target := stmt.Target.(type) {
+    case *ast.SubscriptExpression:
+        obj, key := Eval(target.Target, env), Eval(target.Val, env)
+        val := inplaceOp(stmt.Operator, op_SUBSCR_GET(obj, key), Eval(stmt.Value, env))
+        op_SUBSCR_SET(obj, key, val)
+    case *ast.AttributeExpression:
+        obj, name := Eval(target.Expr, env), newStringInst(target.Attr.Literals)
+        val := inplaceOp(stmt.Operator, op_GETATTR(obj, name), Eval(stmt.Value, env))
+        op_SETATTR(obj, name, val)
+    default:
+        val := inplaceOp(stmt.Operator, Eval(target, env), Eval(stmt.Value, env))
+        assignTarget(target, val, env)
+    }
+}
+
+func assignTarget(target ast.Expression, val Object, env *Environment) {
+    switch attr := target.(type) {
     case *ast.SubscriptExpression:
         target := Eval(attr.Target, env)
         subscr := Eval(attr.Val, env)
@@ -180,13 +353,99 @@ func execAssignStatement(stmt *ast.AssignStatement, env *Environment) {
         op_SETATTR(inst, newStringInst(attr.Attr.Literals), val)
     case *ast.IdentifierExpression:
         env.SetFromString(attr.Identifier.Literals, val)
+    case *ast.TupleExpression:
+        unpackTargets(attr.Items, val, env)
+    case *ast.ListExpression:
+        unpackTargets(attr.Items, val, env)
     }
 }
 
+// deleteTarget unbinds the names and removes the items and attributes of
+// target, one by one from left to right when it's a tuple or list
+func deleteTarget(target ast.Expression, env *Environment) {
+    switch node := target.(type) {
+    case *ast.SubscriptExpression:
+        op_SUBSCR_DEL(Eval(node.Target, env), Eval(node.Val, env))
+    case *ast.AttributeExpression:
+        op_DELATTR(Eval(node.Expr, env), newStringInst(node.Attr.Literals))
+    case *ast.IdentifierExpression:
+        name := newStringInst(node.Identifier.Literals)
+        if !env.Delete(name) {
+            if env.isLocal(name) {
+                panic(newError(Py_UnboundLocalError,
+                    "cannot access local variable '%v' where it is not associated with a value", name))
+            }
+            panic(newError(Py_NameError, "name '%v' is not defined", name))
+        }
+    case *ast.TupleExpression:
+        for _, item := range node.Items {
+            deleteTarget(item, env)
+        }
+    case *ast.ListExpression:
+        for _, item := range node.Items {
+            deleteTarget(item, env)
+        }
+    }
+}
+
+// unpackTargets assigns the items of the iterable val to targets one
+// by one, a starred target takes whatever the others leave as a list
+func unpackTargets(targets []ast.Expression, val Object, env *Environment) {
+    items := iterItems(val)
+
+    starIdx := -1
+    for i, target := range targets {
+        if _, ok := target.(*ast.StarredExpression); ok {
+            starIdx = i
+        }
+    }
+
+    if starIdx == -1 {
+        if len(items) > len(targets) {
+            panic(newError(Py_ValueError, "too many values to unpack (expected %v)", len(targets)))
+        } else if len(items) < len(targets) {
+            panic(newError(Py_ValueError, "not enough values to unpack (expected %v, got %v)", len(targets), len(items)))
+        }
+        for i, target := range targets {
+            assignTarget(target, items[i], env)
+        }
+        return
+    }
+
+    nAfter := len(targets) - starIdx - 1
+    if len(items) < len(targets)-1 {
+        panic(newError(Py_ValueError, "not enough values to unpack (expected at least %v, got %v)", len(targets)-1, len(items)))
+    }
+
+    for i, target := range targets[:starIdx] {
+        assignTarget(target, items[i], env)
+    }
+
+    rest := newListInst()
+    rest.items = append(rest.items, items[starIdx:len(items)-nAfter]...)
+    assignTarget(targets[starIdx].(*ast.StarredExpression).Value, rest, env)
+
+    for i, target := range targets[starIdx+1:] {
+        assignTarget(target, items[len(items)-nAfter+i], env)
+    }
+}
+
+// isTrue tells the truth value of obj, calling bool() only
+// for what isn't already one
+func isTrue(obj Object) bool {
+    switch obj {
+    case Py_True:
+        return true
+    case Py_False, Py_None:
+        return false
+    }
+    return op_CALL(Py_bool, obj) == Py_True
+}
+
 func execIfStatement(stmt ast.Statement, env *Environment) (Object, quitType) {
     if stmt != nil {
         ifstmt := stmt.(*ast.IfStatement)
-        if ifstmt.Condition == nil || Eval(ifstmt.Condition, env) == Py_True {
+        if ifstmt.Condition == nil || isTrue(Eval(ifstmt.Condition, env)) {
             rv, why := Exec(ifstmt.Body, env)
             if why != END {
                 return rv, why
@@ -203,7 +462,7 @@ func execIfStatement(stmt ast.Statement, env *Environment) (Object, quitType) {
 }
 
 func execWhileStatement(stmt *ast.WhileStatement, env *Environment) (Object, quitType) {
-    for Eval(stmt.Condition, env) == Py_True {
+    for isTrue(Eval(stmt.Condition, env)) {
         rv, why := Exec(stmt.Body, env)
         if why == RETURN {
             return rv, why
@@ -217,12 +476,10 @@ func execWhileStatement(stmt *ast.WhileStatement, env *Environment) (Object, qui
 }
 
 func execForStatement(stmt *ast.ForStatement, env *Environment) (Object, quitType) {
-    target := Eval(stmt.Target, env)
-    iterator := op_CALL(Py_iter, target)
+    iterator := op_CALL(Py_iter, Eval(stmt.Iter, env))
 
     for val := iterationNext(iterator); val != nil; val = iterationNext(iterator) {
-        // not considering multi-values currently,
-        env.SetFromString(stmt.Identifiers[0].Literals, val)
+        assignTarget(stmt.Target, val, env)
         rv, why := Exec(stmt.Body, env)
         if why == RETURN {
             return rv, why
@@ -235,6 +492,41 @@ func execForStatement(stmt *ast.ForStatement, env *Environment) (Object, quitTyp
     return nil, END
 }
 
+// evalComprehension runs the for clauses of a comprehension in a scope
+// of its own, so that the loop variables don't leak into env, calling
+// emit with the scope each time the variables pass all of the ifs. The
+// first iterable is evaluated in env, as it is in CPython
+func evalComprehension(fors []*ast.ComprehensionFor, env *Environment, emit func(*Environment)) {
+    iterator := op_CALL(Py_iter, Eval(fors[0].Iter, env))
+    runComprehension(fors, iterator, env.DeriveEnv(), emit)
+}
+
+func runComprehension(fors []*ast.ComprehensionFor, iterator Object, scope *Environment, emit func(*Environment)) {
+    clause := fors[0]
+
+    for val := iterationNext(iterator); val != nil; val = iterationNext(iterator) {
+        assignTarget(clause.Target, val, scope)
+        if !evalConditions(clause.Ifs, scope) {
+            continue
+        }
+
+        if len(fors) == 1 {
+            emit(scope)
+        } else {
+            runComprehension(fors[1:], op_CALL(Py_iter, Eval(fors[1].Iter, scope)), scope, emit)
+        }
+    }
+}
+
+func evalConditions(conds []ast.Expression, env *Environment) bool {
+    for _, cond := range conds {
+        if !isTrue(Eval(cond, env)) {
+            return false
+        }
+    }
+    return true
+}
+
 func iterationNext(iterator Object) Object {
     defer func() {
         e := recover()
@@ -249,89 +541,479 @@ func iterationNext(iterator Object) Object {
 }
 
 func execDefStatement(stmt *ast.DefStatement, env *Environment) {
-
-    var params []*StringInst
-    for _, tok := range stmt.Params {
-        params = append(params, newStringInst(tok.Literals))
-    }
+    decorators := evalDecorators(stmt.Decorators, env)
 
     funcObj := newFunctionInst(
         newStringInst(stmt.Name.Literals),
-        params,
+        evalParameters(stmt.Params, env),
         stmt.Body,
         env,
     )
+    funcObj.scope = stmt.Scope
+    funcObj.isGenerator = stmt.IsGenerator
 
-    env.Set(funcObj.Name, funcObj)
+    env.Set(funcObj.Name, applyDecorators(decorators, funcObj))
 }
 
-func execClassStatement(node *ast.ClassStatement, env *Environment) {
-    clsEnv := env.DeriveEnv()
-    Exec(node.Body, clsEnv)
+// evalDecorators evaluates the decorators from top to bottom,
+// which is done before the function or class is made
+func evalDecorators(decorators []ast.Expression, env *Environment) []Object {
+    var objs []Object
+    for _, decorator := range decorators {
+        objs = append(objs, Eval(decorator, env))
+    }
+    return objs
+}
 
-    var base Class
-    if env.GetFromString(node.Parent.Literals) != nil {
-        base = env.GetFromString(node.Parent.Literals).(Class)
-    } else {
-        base = Py_object
+// applyDecorators calls the decorators on obj from bottom to top,
+// each one given what the one below it returned
+func applyDecorators(decorators []Object, obj Object) Object {
+    for i := len(decorators)-1; i >= 0; i-- {
+        obj = op_CALL(decorators[i], obj)
+    }
+    return obj
+}
+
+func evalParameters(params *ast.Parameters, env *Environment) *signature {
+    sig := &signature{kwDefaults: newDictInst()}
+
+    for _, param := range params.Args {
+        sig.params = append(sig.params, newStringInst(param.Name.Literals))
+        if param.Default != nil {
+            sig.defaults = append(sig.defaults, Eval(param.Default, env))
+        }
+    }
+
+    for _, param := range params.KwOnlyArgs {
+        name := newStringInst(param.Name.Literals)
+        sig.kwonlyParams = append(sig.kwonlyParams, name)
+        if param.Default != nil {
+            sig.kwDefaults.set(name, Eval(param.Default, env))
+        }
+    }
+
+    if params.VarArgs != nil {
+        sig.varargs = newStringInst(params.VarArgs.Name.Literals)
+    }
+    if params.KwArgs != nil {
+        sig.kwargs = newStringInst(params.KwArgs.Name.Literals)
     }
 
+    return sig
+}
+
+func execClassStatement(node *ast.ClassStatement, env *Environment) {
+    decorators := evalDecorators(node.Decorators, env)
+
+    bases := evalItems(node.Bases, env)
+
+    // the functions of the body see __class__ through the cell env
+    cellEnv := env.deriveScope(classCellScope, false)
+    clsEnv := cellEnv.deriveScope(node.Scope, true)
+    Exec(node.Body, clsEnv)
+
     clsObj := op_CALL(
         Py_type,
         newStringInst(node.Name.Literals),
-        base,
+        newTupleInst(bases),
         clsEnv.Store(),
     )
 
-    env.SetFromString(node.Name.Literals, clsObj)
+    cellEnv.Set(__class__, clsObj)
+
+    env.SetFromString(node.Name.Literals, applyDecorators(decorators, clsObj))
 }
 
 func execRaiseStatement(node ast.Expression, env *Environment) {
+    if node == nil {
+        if handlingException == nil {
+            panic(newError(Py_RuntimeError, "No active exception to reraise"))
+        }
+        panic(handlingException)
+    }
+
     rv := Eval(node, env)
-    if op_CALL(Py_isinstance, rv, Py_Exception) == Py_True {
+    if op_CALL(Py_isinstance, rv, Py_BaseException) == Py_True {
         panic(rv)
-    } else if op_CALL(Py_issubclass, rv, Py_Exception) == Py_True {
+    } else if _, ok := rv.(Class); ok && op_CALL(Py_issubclass, rv, Py_BaseException) == Py_True {
         panic(op_CALL(rv))
     } else {
-        panic(Error("SyntaxError: target of raise should be based on Exception class"))
+        panic(newError(Py_TypeError, "exceptions must derive from BaseException"))
+    }
+}
+
+// handlingException is the exception whose `except` clause is running,
+// it is what a bare `raise` re-raises
+var handlingException *ExceptionInst
+
+func execTryStatement(stmt *ast.TryStatement, env *Environment) (rv Object, why quitType) {
+    if stmt.Finally != nil {
+        defer func() {
+            r := recover()
+
+            // a return/break/continue inside finally discards
+            // whatever was going on, including a pending exception
+            if frv, fwhy := Exec(stmt.Finally, env); fwhy != END {
+                rv, why = frv, fwhy
+                return
+            }
+
+            if r != nil {
+                panic(r)
+            }
+        }()
+    }
+
+    rv, why, exc := execCatching(stmt.Body, env)
+    if exc == nil {
+        if why == END && stmt.Else != nil {
+            return Exec(stmt.Else, env)
+        }
+        return rv, why
+    }
+
+    for _, handler := range stmt.Handlers {
+        if handler.Type != nil && !exceptionMatches(exc, Eval(handler.Type, env)) {
+            continue
+        }
+
+        if handler.Name.Literals != "" {
+            env.SetFromString(handler.Name.Literals, exc)
+        }
+
+        prev := handlingException
+        handlingException = exc
+        defer func() {
+            handlingException = prev
+        }()
+
+        return Exec(handler.Body, env)
+    }
+
+    panic(exc)
+}
+
+// execCatching executes stmts, returning the exception raised
+// by them instead of letting it propagate
+func execCatching(stmts []ast.Statement, env *Environment) (rv Object, why quitType, exc *ExceptionInst) {
+    defer func() {
+        if r := recover(); r != nil {
+            e, ok := r.(*ExceptionInst)
+            if !ok {
+                panic(r)
+            }
+            exc = e
+        }
+    }()
+
+    rv, why = Exec(stmts, env)
+    return rv, why, nil
+}
+
+func exceptionMatches(exc *ExceptionInst, typ Object) bool {
+    if types, ok := typ.(*TupleInst); ok {
+        for _, t := range types.items {
+            if exceptionMatches(exc, t) {
+                return true
+            }
+        }
+        return false
     }
+
+    if _, ok := typ.(Class); !ok || op_CALL(Py_issubclass, typ, Py_BaseException) != Py_True {
+        panic(newError(Py_TypeError, "catching classes that do not inherit from BaseException is not allowed"))
+    }
+    return op_CALL(Py_isinstance, exc, typ) == Py_True
 }
 
 func execAssertStatement(stmt *ast.AssertStatement, env *Environment) {
-    for op_CALL(Py_bool, Eval(stmt.Condition, env)) == Py_False {
-        panic(Error(fmt.Sprintf("assert error:  %v", StringOf(Eval(stmt.Msg, env)))))
+    if !isTrue(Eval(stmt.Condition, env)) {
+        if stmt.Msg == nil {
+            panic(op_CALL(Py_AssertionError))
+        }
+        panic(op_CALL(Py_AssertionError, Eval(stmt.Msg, env)))
     }
 }
 
 func evalCallExpression(callNode *ast.CallExpression, parentEnv *Environment) Object {
     callObj := Eval(callNode.Name, parentEnv)
 
-    var args []Object
-    for _, param := range callNode.Params {
-        args = append(args, Eval(param, parentEnv))
+    args := evalItems(callNode.Params, parentEnv)
+    if callObj == Py_super && len(args) == 0 && len(callNode.Keywords) == 0 {
+        args = superArgs(parentEnv)
+    }
+
+    var kwargs *DictInst
+    if len(callNode.Keywords) > 0 {
+        kwargs = newDictInst()
+    }
+
+    for _, kw := range callNode.Keywords {
+        if kw.Name.Literals != "" {
+            name := newStringInst(kw.Name.Literals)
+            if kwargs.get(name) != nil {
+                panic(newError(Py_TypeError, "got multiple values for keyword argument '%v'", name))
+            }
+            kwargs.set(name, Eval(kw.Value, parentEnv))
+            continue
+        }
+
+        // **mapping
+        mapping := Eval(kw.Value, parentEnv)
+        iterator := op_CALL(Py_iter, mapping)
+        for key := iterationNext(iterator); key != nil; key = iterationNext(iterator) {
+            name, ok := key.(*StringInst)
+            if !ok {
+                panic(newError(Py_TypeError, "keywords must be strings"))
+            }
+            if kwargs.get(name) != nil {
+                panic(newError(Py_TypeError, "got multiple values for keyword argument '%v'", name))
+            }
+            kwargs.set(name, op_SUBSCR_GET(mapping, key))
+        }
     }
     
-    return op_CALL(callObj, args...)
+    return op_CALL_KW(callObj, args, kwargs)
+}
+
+// evalComparison applies the comparison operator op to left and right
+func evalComparison(op token.Token, left, right Object) Object {
+    switch op.Type {
+    case token.GT:
+        return op_GT(left, right)
+    case token.LT:
+        return op_LT(left, right)
+    case token.GE:
+        return op_GE(left, right)
+    case token.LE:
+        return op_LE(left, right)
+    case token.EQ:
+        return op_EQ(left, right)
+    case token.NEQ:
+        return op_NEQ(left, right)
+    case token.IN:
+        return op_IN(left, right)
+    case token.NIN:
+        return op_NIN(left, right)
+    case token.IS:
+        return op_IS(left, right)
+    default:
+        return op_ISN(left, right)
+    }
+}
+
+// binaryOp calls left's method name with right, and if that gives
+// NotImplemented, right's reflected method rname with left. The
+// reflected method goes first when right's type is a subclass of
+// left's overriding it, so that a subclass can take over its base
+func binaryOp(op string, name, rname *StringInst, left, right Object) Object {
+    if rv := callBinary(name, rname, left, right, left.otype() != right.otype()); rv != Py_NotImplemented {
+        return rv
+    }
+
+    panic(newError(Py_TypeError, "unsupported operand type(s) for %v: '%v' and '%v'",
+        op, typeName(left), typeName(right)))
+}
+
+// compareOp is binaryOp for comparisons, whose reflection of __lt__
+// is __gt__, of __le__ is __ge__ and of __eq__ is __eq__ itself. It is
+// tried even between the same types, and when neither side supports
+// it, == and != fall back to identity
+func compareOp(op string, name, rname *StringInst, left, right Object) Object {
+    if rv := callBinary(name, rname, left, right, true); rv != Py_NotImplemented {
+        return rv
+    }
+
+    switch name {
+    case __eq__:
+        return op_IS(left, right)
+    case __ne__:
+        return op_ISN(left, right)
+    }
+
+    panic(newError(Py_TypeError, "'%v' not supported between instances of '%v' and '%v'",
+        op, typeName(left), typeName(right)))
+}
+
+func callBinary(name, rname *StringInst, left, right Object, reflectable bool) Object {
+    lfn := attrItself(left.otype(), name)
+
+    var rfn Object
+    if reflectable {
+        rfn = attrItself(right.otype(), rname)
+    }
+
+    if rfn != nil && left.otype() != right.otype() && isSubclass(right.otype(), left.otype()) &&
+        rfn != attrItself(left.otype(), rname) {
+        if rv := op_CALL(rfn, right, left); rv != Py_NotImplemented {
+            return rv
+        }
+        rfn = nil
+    }
+
+    if lfn != nil {
+        if rv := op_CALL(lfn, left, right); rv != Py_NotImplemented {
+            return rv
+        }
+    }
+
+    if rfn != nil {
+        return op_CALL(rfn, right, left)
+    }
+
+    return Py_NotImplemented
+}
+
+// inplaceOps are the methods of the augmented assignments, the in-place
+// one of each and the binary ones it falls back to
+var inplaceOps = map[token.TokenType]struct{
+    op                  string
+    iname, name, rname  *StringInst
+}{
+    token.PLUSASSIGN:       {"+=", __iadd__, __add__, __radd__},
+    token.MINUSASSIGN:      {"-=", __isub__, __sub__, __rsub__},
+    token.MULASSIGN:        {"*=", __imul__, __mul__, __rmul__},
+    token.DIVIDEASSIGN:     {"/=", __itruediv__, __truediv__, __rtruediv__},
+    token.FLOORDIVASSIGN:   {"//=", __ifloordiv__, __floordiv__, __rfloordiv__},
+    token.MODASSIGN:        {"%=", __imod__, __mod__, __rmod__},
+    token.POWASSIGN:        {"**=", __ipow__, __pow__, __rpow__},
+    token.LSHIFTASSIGN:     {"<<=", __ilshift__, __lshift__, __rlshift__},
+    token.RSHIFTASSIGN:     {">>=", __irshift__, __rshift__, __rrshift__},
+    token.BITANDASSIGN:     {"&=", __iand__, __and__, __rand__},
+    token.BITORASSIGN:      {"|=", __ior__, __or__, __ror__},
+    token.BITXORASSIGN:     {"^=", __ixor__, __xor__, __rxor__},
+}
+
+// inplaceOp applies the augmented assignment operator to left and
+// right, calling left's in-place method if it has one and that doesn't
+// give NotImplemented, else the binary operator
+func inplaceOp(operator token.Token, left, right Object) Object {
+    m := inplaceOps[operator.Type]
+    if fn := attrItself(left.otype(), m.iname); fn != nil {
+        if rv := op_CALL(fn, left, right); rv != Py_NotImplemented {
+            return rv
+        }
+    }
+
+    if rv := callBinary(m.name, m.rname, left, right, left.otype() != right.otype()); rv != Py_NotImplemented {
+        return rv
+    }
+    if m.name == __mul__ {
+        checkRepeat(left, right)
+    }
+    panic(newError(Py_TypeError, "unsupported operand type(s) for %v: '%v' and '%v'",
+        m.op, typeName(left), typeName(right)))
 }
 
 func op_ADD(left Object, right Object) Object {
-    return typeCall(__add__, left, right)
+    return binaryOp("+", __add__, __radd__, left, right)
 }
 
 func op_SUB(left Object, right Object) Object {
-    return typeCall(__sub__, left, right)
+    return binaryOp("-", __sub__, __rsub__, left, right)
 }
 
 func op_MUL(left Object, right Object) Object {
-    return typeCall(__mul__, left, right)
+    if rv := callBinary(__mul__, __rmul__, left, right, left.otype() != right.otype()); rv != Py_NotImplemented {
+        return rv
+    }
+
+    checkRepeat(left, right)
+    panic(newError(Py_TypeError, "unsupported operand type(s) for *: '%v' and '%v'", typeName(left), typeName(right)))
+}
+
+// checkRepeat raises the error of multiplying a sequence by left or
+// right when the other one is a sequence, which only an int can repeat
+func checkRepeat(left, right Object) {
+    if isRepeatable(left) {
+        panic(newError(Py_TypeError, "can't multiply sequence by non-int of type '%v'", typeName(right)))
+    }
+    if isRepeatable(right) {
+        panic(newError(Py_TypeError, "can't multiply sequence by non-int of type '%v'", typeName(left)))
+    }
+}
+
+func isRepeatable(obj Object) bool {
+    switch obj.(type) {
+    case *StringInst, *BytesInst, *TupleInst:
+        return true
+    }
+    return false
 }
 
 func op_DIV(left Object, right Object) Object {
-    return typeCall(__floordiv__, left, right)
+    return binaryOp("/", __truediv__, __rtruediv__, left, right)
+}
+
+func op_FLOORDIV(left Object, right Object) Object {
+    return binaryOp("//", __floordiv__, __rfloordiv__, left, right)
+}
+
+func op_MOD(left Object, right Object) Object {
+    return binaryOp("%", __mod__, __rmod__, left, right)
+}
+
+func op_POW(left Object, right Object) Object {
+    return binaryOp("** or pow()", __pow__, __rpow__, left, right)
+}
+
+func op_LSHIFT(left Object, right Object) Object {
+    return binaryOp("<<", __lshift__, __rlshift__, left, right)
+}
+
+func op_RSHIFT(left Object, right Object) Object {
+    return binaryOp(">>", __rshift__, __rrshift__, left, right)
 }
 
+func op_AND(left Object, right Object) Object {
+    return binaryOp("&", __and__, __rand__, left, right)
+}
+
+func op_OR(left Object, right Object) Object {
+    return binaryOp("|", __or__, __ror__, left, right)
+}
+
+func op_XOR(left Object, right Object) Object {
+    return binaryOp("^", __xor__, __rxor__, left, right)
+}
+
+// unaryOp calls the method name of obj's type for the unary operator op
+func unaryOp(op string, name *StringInst, obj Object) Object {
+    if fn := attrItself(obj.otype(), name); fn != nil {
+        return op_CALL(fn, obj)
+    }
+    panic(newError(Py_TypeError, "bad operand type for unary %v: '%v'", op, typeName(obj)))
+}
+
+func op_NEG(obj Object) Object {
+    return unaryOp("-", __neg__, obj)
+}
+
+func op_POS(obj Object) Object {
+    return unaryOp("+", __pos__, obj)
+}
+
+func op_INVERT(obj Object) Object {
+    return unaryOp("~", __invert__, obj)
+}
+
+// op_IN asks the __contains__ of right, or else looks for left
+// among the items of right, which may be got with __getitem__ only
 func op_IN(left Object, right Object) Object {
-    return typeCall(__contains__, right, left)
+    if containsFn := attrItself(right.otype(), __contains__); containsFn != nil {
+        return op_CALL(Py_bool, op_CALL(containsFn, right, left))
+    }
+
+    if attrItself(right.otype(), __iter__) == nil && attrItself(right.otype(), __getitem__) == nil {
+        panic(newError(Py_TypeError, "argument of type '%v' is not iterable", typeName(right)))
+    }
+    iterator := op_CALL(Py_iter, right)
+    for item := iterationNext(iterator); item != nil; item = iterationNext(iterator) {
+        if op_IS(item, left) == Py_True || op_CALL(Py_bool, op_EQ(item, left)) == Py_True {
+            return Py_True
+        }
+    }
+    return Py_False
 }
 
 func op_NIN(left Object, right Object) Object {
@@ -359,23 +1041,27 @@ func op_ISN(left Object, right Object) Object {
 }
 
 func op_EQ(left Object, right Object) Object {
-    return typeCall(__eq__, left, right)
+    return compareOp("==", __eq__, __eq__, left, right)
 }
 
 func op_NEQ(left Object, right Object) Object {
-    if typeCall(__eq__, left, right) == Py_True {
-        return Py_False
-    } else {
-        return Py_True
-    }
+    return compareOp("!=", __ne__, __ne__, left, right)
 }
 
 func op_GT(left Object, right Object) Object {
-    return typeCall(__gt__, left, right)
+    return compareOp(">", __gt__, __lt__, left, right)
 }
 
 func op_LT(left Object, right Object) Object {
-    return typeCall(__lt__, left, right)
+    return compareOp("<", __lt__, __gt__, left, right)
+}
+
+func op_GE(left Object, right Object) Object {
+    return compareOp(">=", __ge__, __le__, left, right)
+}
+
+func op_LE(left Object, right Object) Object {
+    return compareOp("<=", __le__, __ge__, left, right)
 }
 
 func op_SETATTR(inst Object, attr *StringInst, value Object) {
@@ -383,7 +1069,42 @@ func op_SETATTR(inst Object, attr *StringInst, value Object) {
 }
 
 func op_GETATTR(inst Object, attr *StringInst) Object {
-    return typeCall(__getattribute__, inst, attr)
+    __getattr__Fn := attrItself(inst.otype(), __getattr__)
+    if __getattr__Fn == nil {
+        return typeCall(__getattribute__, inst, attr)
+    }
+
+    // __getattr__ is only called when the normal lookup fails
+    if rv := catchAttributeError(func() Object { return typeCall(__getattribute__, inst, attr) }); rv != nil {
+        return rv
+    }
+    return op_CALL(__getattr__Fn, inst, attr)
+}
+
+func op_DELATTR(inst Object, attr *StringInst) {
+    typeCall(__delattr__, inst, attr)
+}
+
+func op_SUBSCR_DEL(inst Object, item Object) Object {
+    if attrItself(inst.otype(), __delitem__) == nil {
+        panic(newError(Py_TypeError, "'%v' object doesn't support item deletion", typeName(inst)))
+    }
+    return typeCall(__delitem__, inst, item)
+}
+
+// catchAttributeError gives what fn returns, or nil if it raises AttributeError
+func catchAttributeError(fn func() Object) (rv Object) {
+    defer func() {
+        if r := recover(); r != nil {
+            e, ok := r.(*ExceptionInst)
+            if ok && op_CALL(Py_isinstance, e, Py_AttributeError) == Py_True {
+                rv = nil
+                return
+            }
+            panic(r)
+        }
+    }()
+    return fn()
 }
 
 func op_SUBSCR_GET(inst Object, item Object) Object {
@@ -395,26 +1116,33 @@ func op_SUBSCR_SET(inst Object, key Object, item Object) Object {
 }
 
 func op_CALL(obj Object, args ...Object) Object {
+    return op_CALL_KW(obj, args, nil)
+}
+
+func op_CALL_KW(obj Object, args []Object, kwargs *DictInst) Object {
     // eliminate obj.otype() here ?
     __call__Fn := attrItself(obj.otype(), __call__)
+    if __call__Fn == nil {
+        panic(newError(Py_TypeError, "'%v' object is not callable", typeName(obj)))
+    }
     args = append([]Object{obj}, args...)
 
     if __call__Fn != PyBuiltinFunction__call__ {
-        return op_CALL(__call__Fn, args...)
+        return op_CALL_KW(__call__Fn, args, kwargs)
     } else {
-        return __call__Fn.(Function).call(args...)
+        return __call__Fn.(Function).callKw(args, kwargs)
     }
 }
 
 func typeCall(attrName *StringInst, obj Object, args ...Object) Object {
     attr := attrItself(obj.otype(), attrName)
     if attr == nil {
-        panic(Error(fmt.Sprintf("%v object is not callable", StringOf(obj.otype()))))
+        panic(newError(Py_TypeError, "'%v' object does not support '%v'", typeName(obj), attrName))
     }
 
     fn, ok := attr.(Function) 
     if !ok {
-        panic(Error(fmt.Sprintf("%v object is not callable", StringOf(obj.otype()))))
+        panic(newError(Py_TypeError, "'%v' object is not callable", typeName(attr)))
     }
     
     args = append([]Object{obj}, args...)
@@ -423,7 +1151,24 @@ func typeCall(attrName *StringInst, obj Object, args ...Object) Object {
 
 func StringOf(obj Object) Object {
     __str__Fn := attrItself(obj.otype(), __str__)
-    return op_CALL(__str__Fn, obj)
+    rv := op_CALL(__str__Fn, obj)
+    if _, ok := rv.(*StringInst); !ok {
+        panic(newError(Py_TypeError, "__str__ returned non-string (type %v)", typeName(rv)))
+    }
+    return rv
+}
+
+func ReprOf(obj Object) Object {
+    __repr__Fn := attrItself(obj.otype(), __repr__)
+    rv := op_CALL(__repr__Fn, obj)
+    if _, ok := rv.(*StringInst); !ok {
+        panic(newError(Py_TypeError, "__repr__ returned non-string (type %v)", typeName(rv)))
+    }
+    return rv
+}
+
+func typeName(obj Object) string {
+    return StringOf(attrItself(obj.otype(), __name__)).(*StringInst).Value
 }
 
 type Frame struct {
@@ -438,5 +1183,3 @@ type Traceback struct {
 func (tb *Traceback) append(f Frame) {
     tb.Frames = append(tb.Frames, f)
 }
-
-var Py_traceback = &Traceback{}
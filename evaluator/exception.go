@@ -0,0 +1,214 @@
+package evaluator
+
+import (
+    "fmt"
+    "unsafe"
+
+    "github.com/realyixuan/gsubpy/ast"
+)
+
+/*
+    BaseException
+     +-- GeneratorExit
+     +-- Exception
+          +-- StopIteration
+          +-- ArithmeticError
+          |    +-- OverflowError
+          |    +-- ZeroDivisionError
+          +-- AssertionError
+          +-- AttributeError
+          +-- LookupError
+          |    +-- IndexError
+          |    +-- KeyError
+          +-- NameError
+          |    +-- UnboundLocalError
+          +-- RuntimeError
+          |    +-- RecursionError
+          +-- SyntaxError
+          |    +-- IndentationError
+          +-- TypeError
+          +-- ValueError
+               +-- UnicodeError
+                    +-- UnicodeDecodeError
+                    +-- UnicodeEncodeError
+*/
+
+type PyException struct {
+    *objectData
+    base    Class
+}
+
+func newPyException(name string, base Class) *PyException {
+    o := &PyException{
+        objectData: &objectData{
+            d: newDictInst(),
+        },
+        base: base,
+    }
+    o.attrs().set(__name__, newStringInst(name))
+    return o
+}
+
+func (pe *PyException) otype() Class { return Py_type }
+func (pe *PyException) cbase() Class { return pe.base }
+func (pe *PyException) id() int64 { return int64(uintptr(unsafe.Pointer(pe))) }
+
+var Py_BaseException = newPyException("BaseException", Py_object)
+func init() {
+    Py_BaseException.attrs().set(__new__, newBuiltinFunc(__new__,
+            func (objs ...Object) Object {
+                return newExceptionInst(objs[0].(Class), objs[1:]...)
+            },
+        ),
+    )
+
+    Py_BaseException.attrs().set(__init__, newBuiltinFunc(__init__,
+            func (objs ...Object) Object {
+                objs[0].(*ExceptionInst).setArgs(objs[1:])
+                return Py_None
+            },
+        ),
+    )
+
+    Py_BaseException.attrs().set(__str__, newBuiltinFunc(__str__,
+            func (objs ...Object) Object {
+                self := objs[0].(*ExceptionInst)
+                switch args := self.args(); len(args.items) {
+                case 0:
+                    return newStringInst("")
+                case 1:
+                    return StringOf(args.items[0])
+                default:
+                    return StringOf(args)
+                }
+            },
+        ),
+    )
+
+    Py_BaseException.attrs().set(__repr__, newBuiltinFunc(__repr__,
+            func (objs ...Object) Object {
+                self := objs[0].(*ExceptionInst)
+                s := StringOf(attrItself(self.otype(), __name__)).(*StringInst).Value + "("
+                for i, arg := range self.args().items {
+                    if i > 0 {
+                        s += ", "
+                    }
+                    s += ReprOf(arg).(*StringInst).Value
+                }
+                return newStringInst(s + ")")
+            },
+        ),
+    )
+}
+
+var Py_GeneratorExit = newPyException("GeneratorExit", Py_BaseException)
+var Py_Exception = newPyException("Exception", Py_BaseException)
+var Py_StopIteration = newPyException("StopIteration", Py_Exception)
+var Py_ArithmeticError = newPyException("ArithmeticError", Py_Exception)
+var Py_OverflowError = newPyException("OverflowError", Py_ArithmeticError)
+var Py_ZeroDivisionError = newPyException("ZeroDivisionError", Py_ArithmeticError)
+var Py_AssertionError = newPyException("AssertionError", Py_Exception)
+var Py_AttributeError = newPyException("AttributeError", Py_Exception)
+var Py_LookupError = newPyException("LookupError", Py_Exception)
+var Py_IndexError = newPyException("IndexError", Py_LookupError)
+var Py_KeyError = newPyException("KeyError", Py_LookupError)
+var Py_NameError = newPyException("NameError", Py_Exception)
+var Py_UnboundLocalError = newPyException("UnboundLocalError", Py_NameError)
+var Py_RuntimeError = newPyException("RuntimeError", Py_Exception)
+var Py_RecursionError = newPyException("RecursionError", Py_RuntimeError)
+var Py_SyntaxError = newPyException("SyntaxError", Py_Exception)
+var Py_IndentationError = newPyException("IndentationError", Py_SyntaxError)
+var Py_TypeError = newPyException("TypeError", Py_Exception)
+var Py_ValueError = newPyException("ValueError", Py_Exception)
+var Py_UnicodeError = newPyException("UnicodeError", Py_ValueError)
+var Py_UnicodeDecodeError = newPyException("UnicodeDecodeError", Py_UnicodeError)
+var Py_UnicodeEncodeError = newPyException("UnicodeEncodeError", Py_UnicodeError)
+
+func init() {
+    // value is what a generator returned, or None
+    Py_StopIteration.attrs().set(__init__, newBuiltinFunc(__init__,
+            func (objs ...Object) Object {
+                self := objs[0].(*ExceptionInst)
+                self.setArgs(objs[1:])
+                var value Object = Py_None
+                if len(objs) > 1 {
+                    value = objs[1]
+                }
+                self.attrs().set(newStringInst("value"), value)
+                return Py_None
+            },
+        ),
+    )
+
+    // a missing key is shown by its repr, so that KeyError('')
+    // does not print as an empty message
+    Py_KeyError.attrs().set(__str__, newBuiltinFunc(__str__,
+            func (objs ...Object) Object {
+                self := objs[0].(*ExceptionInst)
+                if args := self.args(); len(args.items) == 1 {
+                    return ReprOf(args.items[0])
+                }
+                return op_CALL(attrItself(Py_BaseException, __str__), self)
+            },
+        ),
+    )
+}
+
+type ExceptionInst struct {
+    *objectData
+    class       Class
+    Traceback   Traceback
+}
+
+func newExceptionInst(cls Class, args ...Object) *ExceptionInst {
+    e := &ExceptionInst{
+        objectData: &objectData{
+            d: newDictInst(),
+        },
+        class: cls,
+    }
+    e.setArgs(args)
+    return e
+}
+
+func (e *ExceptionInst) otype() Class { return e.class }
+func (e *ExceptionInst) id() int64 { return int64(uintptr(unsafe.Pointer(e))) }
+
+func (e *ExceptionInst) args() *TupleInst {
+    return e.attrs().get(newStringInst("args")).(*TupleInst)
+}
+
+func (e *ExceptionInst) setArgs(args []Object) {
+    e.attrs().set(newStringInst("args"), newTupleInst(append([]Object{}, args...)))
+}
+
+// String gives the last line of a traceback, like "KeyError: 'a'"
+func (e *ExceptionInst) String() string {
+    name := StringOf(attrItself(e.class, __name__)).(*StringInst).Value
+    if msg := StringOf(e).(*StringInst).Value; msg != "" {
+        return name + ": " + msg
+    }
+    return name
+}
+
+func newError(cls Class, format string, a ...interface{}) *ExceptionInst {
+    return newExceptionInst(cls, newStringInst(fmt.Sprintf(format, a...)))
+}
+
+// SyntaxError is what lexer and parser raise, the offending line
+// is recorded as the only frame of its traceback
+func SyntaxError(msg string, lineNum int, line string) *ExceptionInst {
+    return newSyntaxError(Py_SyntaxError, msg, lineNum, line)
+}
+
+func IndentationError(msg string, lineNum int, line string) *ExceptionInst {
+    return newSyntaxError(Py_IndentationError, msg, lineNum, line)
+}
+
+func newSyntaxError(cls Class, msg string, lineNum int, line string) *ExceptionInst {
+    e := newExceptionInst(cls, newStringInst(msg))
+    e.attrs().set(newStringInst("lineno"), newIntegerInst(int64(lineNum)))
+    e.attrs().set(newStringInst("text"), newStringInst(line))
+    e.Traceback.append(Frame{Literals: ast.Literals{LineNum: lineNum, Line: line}})
+    return e
+}
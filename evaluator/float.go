@@ -0,0 +1,412 @@
+package evaluator
+
+import (
+    "encoding/binary"
+    "math"
+    "math/big"
+    "regexp"
+    "strconv"
+    "strings"
+    "unsafe"
+)
+
+type Pyfloat struct {
+    *objectData
+}
+
+func newPyfloat() *Pyfloat {
+    return &Pyfloat{
+        objectData: &objectData{
+            d: newDictInst(),
+        },
+    }
+}
+
+func (pf *Pyfloat) otype() Class { return Py_type }
+func (pf *Pyfloat) cbase() Class { return Py_object }
+func (pf *Pyfloat) id() int64 { return int64(uintptr(unsafe.Pointer(pf))) }
+
+// floatString is what float() takes of a str: a decimal literal,
+// whose digits may be grouped by single underscores, or inf,
+// infinity or nan in any case, all with an optional sign
+var floatString = regexp.MustCompile(
+    `^[+-]?((\d(_?\d)*(\.(\d(_?\d)*)?)?|\.\d(_?\d)*)([eE][+-]?\d(_?\d)*)?|(?i:inf|infinity|nan))$`)
+
+// parseFloatString gives the value of s as float() reads it, or false
+// if it isn't one, like Go's own hex floats such as 0x1p-2
+func parseFloatString(s string) (float64, bool) {
+    s = asciiDecimals(strings.TrimSpace(s))
+    if !floatString.MatchString(s) {
+        return 0, false
+    }
+    // Go takes no sign before nan
+    negative := strings.HasPrefix(s, "-")
+    v, err := strconv.ParseFloat(strings.TrimLeft(strings.ReplaceAll(s, "_", ""), "+-"), 64)
+    if err != nil && !isOutOfRange(err) {
+        return 0, false
+    }
+    if negative {
+        v = -v
+    }
+    return v, true
+}
+
+var Py_float = newPyfloat()
+func init() {
+    Py_float.attrs().set(__name__, newStringInst("float"))
+
+    Py_float.attrs().set(__new__, newBuiltinFunc(__new__,
+            func(objs ...Object) Object {
+                if len(objs) > 2 {
+                    panic(newError(Py_TypeError, "float expected at most 1 argument, got %v", len(objs)-1))
+                }
+                inst := newFloatInst(0)
+
+                if len(objs[1:]) > 0 {
+                    switch o := objs[1].(type) {
+                    case *StringInst:
+                        v, ok := parseFloatString(o.Value)
+                        if !ok {
+                            panic(newError(Py_ValueError, "could not convert string to float: %v", ReprOf(o)))
+                        }
+                        inst.Value = v
+                    default:
+                        v, ok := floatOperand(o)
+                        if !ok {
+                            panic(newError(Py_TypeError, "float() argument must be a string or a number, not '%v'", typeName(o)))
+                        }
+                        inst.Value = v
+                    }
+                }
+
+                inst.class = objs[0].(Class)
+                return inst
+            },
+        ),
+    )
+
+    Py_float.attrs().set(__hash__, newBuiltinFunc(__hash__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 0)
+                v := objs[0].(*FloatInst).Value
+
+                // a float equal to an int hashes the same as the int,
+                // since they are the same key of a dict
+                if v == math.Trunc(v) && !math.IsInf(v, 0) {
+                    return op_CALL(Py_hash, newIntegerFromFloat(v))
+                }
+
+                buf := make([]byte, 8)
+                binary.LittleEndian.PutUint64(buf, math.Float64bits(v))
+                return newIntegerInst(hash(buf))
+            },
+        ),
+    )
+
+    floatRepr := newBuiltinFunc(__repr__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 0)
+                return newStringInst(formatFloat(objs[0].(*FloatInst).Value))
+            },
+        )
+    Py_float.attrs().set(__repr__, floatRepr)
+
+    Py_float.attrs().set(__bool__, newBuiltinFunc(__bool__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 0)
+                if objs[0].(*FloatInst).Value == 0 {
+                    return Py_False
+                }
+                return Py_True
+            },
+        ),
+    )
+
+    Py_float.attrs().set(__eq__, newFloatCompare(__eq__, func(a, b float64) bool { return a == b }))
+    Py_float.attrs().set(__lt__, newFloatCompare(__lt__, func(a, b float64) bool { return a < b }))
+    Py_float.attrs().set(__gt__, newFloatCompare(__gt__, func(a, b float64) bool { return a > b }))
+    Py_float.attrs().set(__le__, newFloatCompare(__le__, func(a, b float64) bool { return a <= b }))
+    Py_float.attrs().set(__ge__, newFloatCompare(__ge__, func(a, b float64) bool { return a >= b }))
+
+    add := func(a, b float64) float64 { return a + b }
+    sub := func(a, b float64) float64 { return a - b }
+    mul := func(a, b float64) float64 { return a * b }
+    Py_float.attrs().set(__add__, newFloatArith(__add__, add, false))
+    Py_float.attrs().set(__radd__, newFloatArith(__radd__, add, true))
+    Py_float.attrs().set(__sub__, newFloatArith(__sub__, sub, false))
+    Py_float.attrs().set(__rsub__, newFloatArith(__rsub__, sub, true))
+    Py_float.attrs().set(__mul__, newFloatArith(__mul__, mul, false))
+    Py_float.attrs().set(__rmul__, newFloatArith(__rmul__, mul, true))
+
+    truediv := func(a, b float64) float64 {
+        if b == 0 {
+            panic(newError(Py_ZeroDivisionError, "float division by zero"))
+        }
+        return a / b
+    }
+    Py_float.attrs().set(__truediv__, newFloatArith(__truediv__, truediv, false))
+    Py_float.attrs().set(__rtruediv__, newFloatArith(__rtruediv__, truediv, true))
+
+    floordiv := func(a, b float64) float64 {
+        if b == 0 {
+            panic(newError(Py_ZeroDivisionError, "float floor division by zero"))
+        }
+        div, _ := floatDivmod(a, b)
+        return div
+    }
+    Py_float.attrs().set(__floordiv__, newFloatArith(__floordiv__, floordiv, false))
+    Py_float.attrs().set(__rfloordiv__, newFloatArith(__rfloordiv__, floordiv, true))
+
+    mod := func(a, b float64) float64 {
+        if b == 0 {
+            panic(newError(Py_ZeroDivisionError, "float modulo"))
+        }
+        _, mod := floatDivmod(a, b)
+        return mod
+    }
+    Py_float.attrs().set(__mod__, newFloatArith(__mod__, mod, false))
+    Py_float.attrs().set(__rmod__, newFloatArith(__rmod__, mod, true))
+
+    pow := func(a, b float64) float64 {
+        if a == 0 && b < 0 {
+            panic(newError(Py_ZeroDivisionError, "0.0 cannot be raised to a negative power"))
+        }
+        // there is no complex, in which a negative number to
+        // a fractional power would be
+        if a < 0 && b != math.Trunc(b) && !math.IsInf(b, 0) {
+            panic(newError(Py_ValueError, "math domain error"))
+        }
+        v := math.Pow(a, b)
+        if math.IsInf(v, 0) && !math.IsInf(a, 0) && !math.IsInf(b, 0) {
+            panic(newError(Py_OverflowError, "(34, 'Numerical result out of range')"))
+        }
+        return v
+    }
+    Py_float.attrs().set(__pow__, newFloatArith(__pow__, pow, false))
+    Py_float.attrs().set(__rpow__, newFloatArith(__rpow__, pow, true))
+
+    Py_float.attrs().set(__neg__, newBuiltinFunc(__neg__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 0)
+                return newFloatInst(-objs[0].(*FloatInst).Value)
+            },
+        ),
+    )
+
+    Py_float.attrs().set(__pos__, newBuiltinFunc(__pos__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 0)
+                return newFloatInst(objs[0].(*FloatInst).Value)
+            },
+        ),
+    )
+
+    Py_float.attrs().set(__round__, newBuiltinFunc(__round__,
+            func(objs ...Object) Object {
+                if len(objs) > 2 {
+                    panic(newError(Py_TypeError, "__round__ expected at most 1 argument, got %v", len(objs)-1))
+                }
+                v := objs[0].(*FloatInst).Value
+
+                if len(objs) == 1 || objs[1] == Py_None {
+                    return newIntegerFromFloat(math.RoundToEven(v))
+                }
+
+                ndigits, ok := objs[1].(*IntegerInst)
+                if !ok {
+                    panic(newError(Py_TypeError, "'%v' object cannot be interpreted as an integer", typeName(objs[1])))
+                }
+                if math.IsNaN(v) || math.IsInf(v, 0) {
+                    return objs[0]
+                }
+                return newFloatInst(roundFloat(v, ndigits.Value))
+            },
+        ),
+    )
+
+    Py_float.attrs().set(newStringInst("is_integer"), newBuiltinFunc(newStringInst("is_integer"),
+            func(objs ...Object) Object {
+                if len(objs) != 1 {
+                    panic(newError(Py_TypeError, "float.is_integer() takes no arguments (%v given)", len(objs)-1))
+                }
+                v := objs[0].(*FloatInst).Value
+                if v == math.Trunc(v) && !math.IsInf(v, 0) {
+                    return Py_True
+                }
+                return Py_False
+            },
+        ),
+    )
+
+}
+
+func isOutOfRange(err error) bool {
+    numErr, ok := err.(*strconv.NumError)
+    return ok && numErr.Err == strconv.ErrRange
+}
+
+// toFloat gives the value of an int or a float as float64,
+// an int too large for it becomes an infinity
+func toFloat(obj Object) (float64, bool) {
+    switch o := obj.(type) {
+    case *FloatInst:
+        return o.Value, true
+    case *IntegerInst:
+        if o.big != nil {
+            v, _ := new(big.Float).SetInt(o.big).Float64()
+            return v, true
+        }
+        return float64(o.Value), true
+    }
+    return 0, false
+}
+
+// floatOperand is toFloat for arithmetic, which can't go on
+// with an int too large to be a float
+func floatOperand(obj Object) (float64, bool) {
+    v, ok := toFloat(obj)
+    if _, isInt := obj.(*IntegerInst); isInt && math.IsInf(v, 0) {
+        panic(newError(Py_OverflowError, "int too large to convert to float"))
+    }
+    return v, ok
+}
+
+// newFloatCompare makes a comparison method of float out of cmp. An
+// int is compared exactly, rather than as the float nearest to it
+func newFloatCompare(name *StringInst, cmp func(float64, float64) bool) *BuiltinFunctionInst {
+    return newBuiltinFunc(name,
+        func(objs ...Object) Object {
+            checkSlotArgs(objs, 1)
+            self := objs[0].(*FloatInst).Value
+
+            if i, ok := objs[1].(*IntegerInst); ok {
+                // an infinity is beyond every int, and a nan compares to none
+                if math.IsInf(self, 0) || math.IsNaN(self) {
+                    return newBoolInst(cmp(self, 0))
+                }
+                c := new(big.Float).SetFloat64(self).Cmp(new(big.Float).SetInt(i.bigValue()))
+                return newBoolInst(cmp(float64(c), 0))
+            }
+
+            other, ok := toFloat(objs[1])
+            if !ok {
+                return Py_NotImplemented
+            }
+
+            if cmp(self, other) {
+                return Py_True
+            }
+            return Py_False
+        },
+    )
+}
+
+// newFloatArith makes an arithmetic method of float out of fn, a
+// reflected one like __radd__ takes the operands in reverse order
+func newFloatArith(name *StringInst, fn func(float64, float64) float64, reflected bool) *BuiltinFunctionInst {
+    return newBuiltinFunc(name,
+        func(objs ...Object) Object {
+            checkSlotArgs(objs, 1)
+            self := objs[0].(*FloatInst).Value
+            other, ok := floatOperand(objs[1])
+            if !ok {
+                return Py_NotImplemented
+            }
+
+            if reflected {
+                return newFloatInst(fn(other, self))
+            }
+            return newFloatInst(fn(self, other))
+        },
+    )
+}
+
+// floatDivmod is the floor division and modulo of floats the way
+// CPython does it, the modulo has the same sign as b
+func floatDivmod(a, b float64) (float64, float64) {
+    mod := math.Mod(a, b)
+    div := (a - mod) / b
+    if mod != 0 {
+        if (b < 0) != (mod < 0) {
+            mod += b
+            div -= 1
+        }
+    } else {
+        mod = math.Copysign(0, b)
+    }
+
+    if div != 0 {
+        floordiv := math.Floor(div)
+        if div - floordiv > 0.5 {
+            floordiv += 1
+        }
+        div = floordiv
+    } else {
+        div = math.Copysign(0, a/b)
+    }
+
+    return div, mod
+}
+
+// roundFloat rounds v to ndigits decimal digits, halfway
+// cases of the exact binary value going to even
+func roundFloat(v float64, ndigits int64) float64 {
+    if ndigits >= 0 {
+        if ndigits > 323 {
+            return v
+        }
+        rv, _ := strconv.ParseFloat(strconv.FormatFloat(v, 'f', int(ndigits), 64), 64)
+        return rv
+    }
+
+    if ndigits < -308 {
+        return math.Copysign(0, v)
+    }
+    pow := math.Pow(10, float64(-ndigits))
+    return math.RoundToEven(v / pow) * pow
+}
+
+// formatFloat gives the repr of v as CPython does, the shortest
+// string that reads back as v, in scientific notation when the
+// exponent is less than -4 or not less than 16
+func formatFloat(v float64) string {
+    switch {
+    case math.IsNaN(v):
+        return "nan"
+    case math.IsInf(v, 1):
+        return "inf"
+    case math.IsInf(v, -1):
+        return "-inf"
+    }
+
+    s := strconv.FormatFloat(v, 'e', -1, 64)
+    exp, _ := strconv.Atoi(s[strings.IndexByte(s, 'e')+1:])
+    if exp < -4 || exp >= 16 {
+        return s
+    }
+
+    s = strconv.FormatFloat(v, 'f', -1, 64)
+    if !strings.ContainsRune(s, '.') {
+        s += ".0"
+    }
+    return s
+}
+
+type FloatInst struct {
+    *objectData
+    class   Class
+    Value   float64
+}
+
+func newFloatInst(v float64) *FloatInst {
+    return &FloatInst{
+        objectData: &objectData{
+            d: newDictInst(),
+        },
+        class: Py_float,
+        Value: v,
+    }
+}
+
+func (f *FloatInst) otype() Class { return f.class }
+func (f *FloatInst) id() int64 { return int64(uintptr(unsafe.Pointer(f))) }
@@ -0,0 +1,653 @@
+package evaluator
+
+import (
+    "math"
+    "math/big"
+    "strconv"
+    "strings"
+    "unicode/utf8"
+)
+
+/*
+    The format-spec mini-language, which is what follows the colon
+    in a replacement field like {x:>10.2f}:
+
+        [[fill]align][sign][z][#][0][width][grouping][.precision][type]
+
+    It's understood by the __format__ of int, float and str, which
+    format() calls, as do str.format and f-strings for each field.
+*/
+
+type formatSpec struct {
+    fill        rune
+    align       byte    // '<', '>', '=' or '^', or 0 if not given
+    sign        byte    // '+', '-' or ' ', or 0 if not given
+    noNegZero   bool
+    alternate   bool
+    width       int     // -1 if not given
+    grouping    byte    // ',' or '_', or 0 if not given
+    precision   int     // -1 if not given
+    typ         byte    // 0 if not given
+}
+
+// parseFormatSpec reads spec, for an object of the type typName,
+// which is numeric if it's aligned to the right by default
+func parseFormatSpec(spec string, typName string, numeric bool) *formatSpec {
+    fs := &formatSpec{fill: ' ', width: -1, precision: -1}
+    runes := []rune(spec)
+    i := 0
+
+    isAlign := func(r rune) bool { return r == '<' || r == '>' || r == '=' || r == '^' }
+    fillGiven := false
+    if len(runes) >= 2 && isAlign(runes[1]) {
+        fs.fill, fs.align = runes[0], byte(runes[1])
+        fillGiven = true
+        i = 2
+    } else if len(runes) >= 1 && isAlign(runes[0]) {
+        fs.align = byte(runes[0])
+        i = 1
+    }
+
+    if i < len(runes) && (runes[i] == '+' || runes[i] == '-' || runes[i] == ' ') {
+        fs.sign = byte(runes[i])
+        i++
+    }
+    if i < len(runes) && runes[i] == 'z' {
+        fs.noNegZero = true
+        i++
+    }
+    if i < len(runes) && runes[i] == '#' {
+        fs.alternate = true
+        i++
+    }
+    if i < len(runes) && runes[i] == '0' {
+        if !fillGiven {
+            fs.fill = '0'
+            if fs.align == 0 && numeric {
+                fs.align = '='
+            }
+        }
+        i++
+    }
+
+    readNumber := func() int {
+        start := i
+        for i < len(runes) && '0' <= runes[i] && runes[i] <= '9' {
+            i++
+        }
+        if start == i {
+            return -1
+        }
+        n, err := strconv.Atoi(string(runes[start:i]))
+        if err != nil || n > math.MaxInt32 {
+            panic(newError(Py_ValueError, "Too many decimal digits in format string"))
+        }
+        return n
+    }
+    fs.width = readNumber()
+
+    if i < len(runes) && (runes[i] == ',' || runes[i] == '_') {
+        fs.grouping = byte(runes[i])
+        i++
+        if i < len(runes) && (runes[i] == ',' || runes[i] == '_') {
+            panic(newError(Py_ValueError, "Cannot specify both ',' and '_'."))
+        }
+    }
+
+    if i < len(runes) && runes[i] == '.' {
+        i++
+        fs.precision = readNumber()
+        if fs.precision < 0 {
+            panic(newError(Py_ValueError, "Format specifier missing precision"))
+        }
+    }
+
+    if i < len(runes) {
+        if i+1 < len(runes) || runes[i] >= utf8.RuneSelf {
+            panic(newError(Py_ValueError, "Invalid format specifier '%v' for object of type '%v'", spec, typName))
+        }
+        fs.typ = byte(runes[i])
+    }
+
+    if fs.grouping != 0 {
+        switch fs.typ {
+        case 0, 'd', 'e', 'E', 'f', 'F', 'g', 'G', '%':
+        case 'b', 'o', 'x', 'X':
+            if fs.grouping == '_' {
+                break
+            }
+            fallthrough
+        default:
+            panic(newError(Py_ValueError, "Cannot specify '%c' with '%c'.", fs.grouping, fs.typ))
+        }
+    }
+
+    return fs
+}
+
+// pad fills the body of a formatted value up to the width. The sign and
+// prefix of a number come before the fill when it's aligned with `=`
+func (fs *formatSpec) pad(sign, body string, defaultAlign byte) string {
+    n := fs.width - utf8.RuneCountInString(sign) - utf8.RuneCountInString(body)
+    if n <= 0 {
+        return sign + body
+    }
+
+    fill := strings.Repeat(string(fs.fill), n)
+    align := fs.align
+    if align == 0 {
+        align = defaultAlign
+    }
+    switch align {
+    case '<':
+        return sign + body + fill
+    case '^':
+        left := strings.Repeat(string(fs.fill), n/2)
+        return left + sign + body + fill[len(left):]
+    case '=':
+        return sign + fill + body
+    }
+    return fill + sign + body
+}
+
+// signOf gives what comes before a number, negative or not
+func (fs *formatSpec) signOf(negative bool) string {
+    if negative {
+        return "-"
+    }
+    if fs.sign == '+' || fs.sign == ' ' {
+        return string(fs.sign)
+    }
+    return ""
+}
+
+// group puts the separator between each size digits of the whole
+// number digits, and zeros in front of them if they're to be padded
+// with zeros, whose width is that left to the digits
+func (fs *formatSpec) group(digits string, size int, width int) string {
+    zeroFill := fs.align == '=' && fs.fill == '0'
+    if fs.grouping == 0 {
+        if zeroFill && len(digits) < width {
+            digits = strings.Repeat("0", width-len(digits)) + digits
+        }
+        return digits
+    }
+
+    sep := string(fs.grouping)
+    for {
+        var b strings.Builder
+        for i, d := range digits {
+            if i > 0 && (len(digits)-i) % size == 0 {
+                b.WriteString(sep)
+            }
+            b.WriteRune(d)
+        }
+        if res := b.String(); !zeroFill || len(res) >= width {
+            return res
+        }
+        digits = "0" + digits
+    }
+}
+
+// formatStr formats s by the spec of str.__format__
+func formatStr(s *StringInst, spec string) string {
+    fs := parseFormatSpec(spec, typeName(s), false)
+    switch {
+    case fs.typ != 0 && fs.typ != 's':
+        panic(newError(Py_ValueError, "Unknown format code '%c' for object of type '%v'", fs.typ, typeName(s)))
+    case fs.sign != 0:
+        panic(newError(Py_ValueError, "Sign not allowed in string format specifier"))
+    case fs.alternate:
+        panic(newError(Py_ValueError, "Alternate form (#) not allowed in string format specifier"))
+    case fs.align == '=':
+        panic(newError(Py_ValueError, "'=' alignment not allowed in string format specifier"))
+    }
+
+    if fs.precision >= 0 && s.length() > fs.precision {
+        return fs.pad("", string([]rune(s.Value)[:fs.precision]), '<')
+    }
+    return fs.pad("", s.Value, '<')
+}
+
+// formatInt formats n by the spec of int.__format__
+func formatInt(n *IntegerInst, spec string) string {
+    fs := parseFormatSpec(spec, typeName(n), true)
+    switch fs.typ {
+    case 'e', 'E', 'f', 'F', 'g', 'G', '%':
+        v, _ := new(big.Float).SetInt(n.bigValue()).Float64()
+        return formatFloatBySpec(v, fs)
+    }
+
+    if fs.precision >= 0 {
+        panic(newError(Py_ValueError, "Precision not allowed in integer format specifier"))
+    }
+    if fs.noNegZero {
+        panic(newError(Py_ValueError, "Negative zero coercion (z) not allowed in integer format specifier"))
+    }
+
+    v := n.bigValue()
+    negative := v.Sign() < 0
+    abs := new(big.Int).Abs(v)
+    prefix := ""
+    var digits string
+    size := 3
+    switch fs.typ {
+    case 0, 'd', 'n':
+        digits = abs.String()
+    case 'b', 'o', 'x', 'X':
+        base := map[byte]int{'b': 2, 'o': 8, 'x': 16, 'X': 16}[fs.typ]
+        digits = abs.Text(base)
+        if fs.typ == 'X' {
+            digits = strings.ToUpper(digits)
+        }
+        if fs.alternate {
+            prefix = "0" + string(fs.typ)
+        }
+        size = 4
+    case 'c':
+        if fs.sign != 0 {
+            panic(newError(Py_ValueError, "Sign not allowed with integer format specifier 'c'"))
+        }
+        if fs.alternate {
+            panic(newError(Py_ValueError, "Alternate form (#) not allowed with integer format specifier 'c'"))
+        }
+        if !v.IsInt64() || v.Int64() < 0 || v.Int64() > utf8.MaxRune {
+            panic(newError(Py_OverflowError, "%%c arg not in range(0x110000)"))
+        }
+        return fs.pad("", string(rune(v.Int64())), '<')
+    default:
+        panic(newError(Py_ValueError, "Unknown format code '%c' for object of type '%v'", fs.typ, typeName(n)))
+    }
+
+    sign := fs.signOf(negative) + prefix
+    return fs.pad(sign, fs.group(digits, size, fs.width-len(sign)), '>')
+}
+
+// formatFloatSpec formats f by the spec of float.__format__
+func formatFloatSpec(f *FloatInst, spec string) string {
+    fs := parseFormatSpec(spec, typeName(f), true)
+    switch fs.typ {
+    case 0, 'e', 'E', 'f', 'F', 'g', 'G', 'n', '%':
+    default:
+        panic(newError(Py_ValueError, "Unknown format code '%c' for object of type '%v'", fs.typ, typeName(f)))
+    }
+    return formatFloatBySpec(f.Value, fs)
+}
+
+func formatFloatBySpec(v float64, fs *formatSpec) string {
+    negative := math.Signbit(v) && !math.IsNaN(v)
+    abs := math.Abs(v)
+
+    var body string
+    switch {
+    case math.IsInf(abs, 0):
+        body = "inf"
+    case math.IsNaN(abs):
+        body = "nan"
+    default:
+        body = floatDigits(abs, fs.typ, fs.precision, fs.alternate)
+    }
+    if fs.typ == 'E' || fs.typ == 'F' || fs.typ == 'G' {
+        body = strings.ToUpper(body)
+    }
+    if fs.typ == '%' {
+        body += "%"
+    }
+    if negative && fs.noNegZero && strings.Trim(body, "0.%") == "" {
+        negative = false
+    }
+
+    sign := fs.signOf(negative)
+    if strings.ContainsAny(body, "0123456789") {
+        // the whole number digits are grouped and padded, but not
+        // the fraction and exponent after them
+        end := strings.IndexAny(body, ".eE%")
+        if end < 0 {
+            end = len(body)
+        }
+        rest := body[end:]
+        body = fs.group(body[:end], 3, fs.width-len(sign)-len(rest)) + rest
+    }
+    return fs.pad(sign, body, '>')
+}
+
+// floatDigits gives the non-negative v in the presentation typ,
+// with prec digits after the point, or significant ones for g
+func floatDigits(v float64, typ byte, prec int, alternate bool) string {
+    switch typ {
+    case 0:
+        if prec < 0 {
+            return formatFloat(v)
+        }
+        // like g, but always with a digit after the point, and
+        // in scientific notation one digit earlier
+        return floatGeneral(v, prec, alternate, true)
+    case 'g', 'G', 'n':
+        if prec < 0 {
+            prec = 6
+        }
+        return floatGeneral(v, prec, alternate, false)
+    }
+
+    if prec < 0 {
+        prec = 6
+    }
+    var s string
+    switch typ {
+    case 'e', 'E':
+        s = strconv.FormatFloat(v, 'e', prec, 64)
+    case 'f', 'F':
+        s = strconv.FormatFloat(v, 'f', prec, 64)
+    case '%':
+        s = strconv.FormatFloat(v*100, 'f', prec, 64)
+    }
+    if alternate && !strings.ContainsRune(s, '.') {
+        if i := strings.IndexByte(s, 'e'); i >= 0 {
+            s = s[:i] + "." + s[i:]
+        } else {
+            s += "."
+        }
+    }
+    return s
+}
+
+// floatGeneral gives v with prec significant digits, in scientific
+// notation if its exponent is less than -4 or too big for them
+func floatGeneral(v float64, prec int, alternate, addDot0 bool) string {
+    if prec == 0 {
+        prec = 1
+    }
+    s := strconv.FormatFloat(v, 'e', prec-1, 64)
+    exp, _ := strconv.Atoi(s[strings.IndexByte(s, 'e')+1:])
+
+    limit := prec
+    if addDot0 {
+        limit = prec - 1
+    }
+    if exp < -4 || exp >= limit {
+        if !alternate {
+            mantissa, e, _ := strings.Cut(s, "e")
+            s = trimFraction(mantissa) + "e" + e
+        }
+        return s
+    }
+
+    s = strconv.FormatFloat(v, 'f', prec-1-exp, 64)
+    if !alternate {
+        s = trimFraction(s)
+    }
+    if addDot0 && !strings.ContainsRune(s, '.') {
+        s += ".0"
+    }
+    return s
+}
+
+// trimFraction drops the trailing zeros of the fraction of s,
+// and the point too if nothing is left after it
+func trimFraction(s string) string {
+    if !strings.ContainsRune(s, '.') {
+        return s
+    }
+    return strings.TrimSuffix(strings.TrimRight(s, "0"), ".")
+}
+
+// formatValue gives obj formatted by spec, as format(obj, spec) does
+func formatValue(obj Object, spec string) string {
+    __format__Fn := attrItself(obj.otype(), __format__)
+    rv := op_CALL(__format__Fn, obj, newStringInst(spec))
+    s, ok := rv.(*StringInst)
+    if !ok {
+        panic(newError(Py_TypeError, "__format__ must return a str, not %v", typeName(rv)))
+    }
+    return s.Value
+}
+
+// convertValue applies the conversion of a field, which is !r, !s or
+// !a, or none at all if it's 0
+func convertValue(obj Object, conversion byte) Object {
+    switch conversion {
+    case 'r':
+        return ReprOf(obj)
+    case 's':
+        return StringOf(obj)
+    case 'a':
+        return op_CALL(Py_ascii, obj)
+    }
+    return obj
+}
+
+var Py_format = newBuiltinFunc(
+    newStringInst("format"),
+    func(objs ...Object) Object {
+        if len(objs) < 1 || len(objs) > 2 {
+            panic(newError(Py_TypeError, "format expected at most 2 arguments, got %v", len(objs)))
+        }
+        spec := ""
+        if len(objs) == 2 {
+            spec = strArg(objs[1], "format() argument 2 must be str, not %v")
+        }
+        return newStringInst(formatValue(objs[0], spec))
+    },
+)
+
+func init() {
+    Py_object.attrs().set(__format__, newBuiltinFunc(__format__,
+            func(objs ...Object) Object {
+                spec := strArg(objs[1], "__format__() argument must be str, not %v")
+                if spec != "" {
+                    panic(newError(Py_TypeError, "unsupported format string passed to %v.__format__", typeName(objs[0])))
+                }
+                return StringOf(objs[0])
+            },
+        ),
+    )
+
+    Py_int.attrs().set(__format__, newBuiltinFunc(__format__,
+            func(objs ...Object) Object {
+                spec := strArg(objs[1], "__format__() argument must be str, not %v")
+                if spec == "" {
+                    return StringOf(objs[0])
+                }
+                return newStringInst(formatInt(objs[0].(*IntegerInst), spec))
+            },
+        ),
+    )
+
+    Py_float.attrs().set(__format__, newBuiltinFunc(__format__,
+            func(objs ...Object) Object {
+                spec := strArg(objs[1], "__format__() argument must be str, not %v")
+                if spec == "" {
+                    return StringOf(objs[0])
+                }
+                return newStringInst(formatFloatSpec(objs[0].(*FloatInst), spec))
+            },
+        ),
+    )
+
+    Py_str.attrs().set(__format__, newBuiltinFunc(__format__,
+            func(objs ...Object) Object {
+                spec := strArg(objs[1], "__format__() argument must be str, not %v")
+                return newStringInst(formatStr(objs[0].(*StringInst), spec))
+            },
+        ),
+    )
+
+    setStrMethod("format", func(objs []Object, kwargs *DictInst) Object {
+        f := &strFormatter{args: objs[1:], kwargs: kwargs}
+        return newStringInst(f.format(objs[0].(*StringInst).Value, 2))
+    })
+}
+
+// strFormatter is what str.format fills the fields of its format
+// string with, which are numbered automatically or manually
+type strFormatter struct {
+    args        []Object
+    kwargs      *DictInst
+    next        int     // the number of the next automatic field
+    manual      bool
+}
+
+// format replaces the fields of s, where depth tells how many more
+// levels of fields can be nested in their format specs
+func (f *strFormatter) format(s string, depth int) string {
+    if depth < 0 {
+        panic(newError(Py_ValueError, "Max string recursion exceeded"))
+    }
+
+    var res strings.Builder
+    for i := 0; i < len(s); i++ {
+        c := s[i]
+        if c == '}' {
+            if i+1 < len(s) && s[i+1] == '}' {
+                res.WriteByte('}')
+                i++
+                continue
+            }
+            panic(newError(Py_ValueError, "Single '}' encountered in format string"))
+        }
+        if c != '{' {
+            res.WriteByte(c)
+            continue
+        }
+        if i+1 < len(s) && s[i+1] == '{' {
+            res.WriteByte('{')
+            i++
+            continue
+        }
+
+        // the field ends at the matching brace, as its spec
+        // may have fields of its own
+        end, nested := i+1, 1
+        for ; end < len(s); end++ {
+            if s[end] == '{' {
+                nested++
+            } else if s[end] == '}' {
+                nested--
+                if nested == 0 {
+                    break
+                }
+            }
+        }
+        if end == len(s) {
+            if i+1 == len(s) {
+                panic(newError(Py_ValueError, "Single '{' encountered in format string"))
+            }
+            panic(newError(Py_ValueError, "expected '}' before end of string"))
+        }
+        res.WriteString(f.field(s[i+1:end], depth))
+        i = end
+    }
+    return res.String()
+}
+
+// field gives the value of a replacement field, with its name,
+// conversion and spec like `0.name!r:>10`, but not the braces
+func (f *strFormatter) field(field string, depth int) string {
+    name, spec := field, ""
+    conversion := byte(0)
+    hasSpec := false
+    for i, inBracket := 0, false; i < len(field); i++ {
+        if field[i] == '[' {
+            inBracket = true
+        } else if field[i] == ']' {
+            inBracket = false
+        } else if !inBracket && (field[i] == '!' || field[i] == ':') {
+            name = field[:i]
+            rest := field[i:]
+            if rest[0] == '!' {
+                if len(rest) < 2 {
+                    panic(newError(Py_ValueError, "unmatched '{' in format spec"))
+                }
+                conversion = rest[1]
+                rest = rest[2:]
+                if rest != "" && rest[0] != ':' {
+                    panic(newError(Py_ValueError, "expected ':' after conversion specifier"))
+                }
+            }
+            if rest != "" {
+                spec, hasSpec = rest[1:], true
+            }
+            break
+        }
+    }
+
+    // the fields of the spec come after that of the field itself
+    obj := f.lookup(name)
+    if hasSpec {
+        spec = f.format(spec, depth-1)
+    }
+    if conversion != 0 && strings.IndexByte("rsa", conversion) < 0 {
+        panic(newError(Py_ValueError, "Unknown conversion specifier %c", conversion))
+    }
+    return formatValue(convertValue(obj, conversion), spec)
+}
+
+// lookup gives the object a field name refers to, like `0`, `name`,
+// or either followed by attributes and items, like `0.real` or `x[0]`
+func (f *strFormatter) lookup(name string) Object {
+    end := strings.IndexAny(name, ".[")
+    if end < 0 {
+        end = len(name)
+    }
+    first, rest := name[:end], name[end:]
+
+    var obj Object
+    if index, err := strconv.Atoi(first); first == "" || err == nil {
+        if first == "" {
+            if f.manual {
+                panic(newError(Py_ValueError, "cannot switch from manual field specification to automatic field numbering"))
+            }
+            index = f.next
+            f.next++
+        } else {
+            if f.next > 0 {
+                panic(newError(Py_ValueError, "cannot switch from automatic field numbering to manual field specification"))
+            }
+            f.manual = true
+        }
+        if index >= len(f.args) {
+            panic(newError(Py_IndexError, "Replacement index %v out of range for positional args tuple", index))
+        }
+        obj = f.args[index]
+    } else {
+        if f.kwargs != nil {
+            obj = f.kwargs.get(newStringInst(first))
+        }
+        if obj == nil {
+            panic(op_CALL(Py_KeyError, newStringInst(first)))
+        }
+    }
+
+    for rest != "" {
+        if rest[0] == '.' {
+            end := strings.IndexAny(rest[1:], ".[") + 1
+            if end == 0 {
+                end = len(rest)
+            }
+            if end == 1 {
+                panic(newError(Py_ValueError, "Empty attribute in format string"))
+            }
+            obj = op_GETATTR(obj, newStringInst(rest[1:end]))
+            rest = rest[end:]
+            continue
+        }
+        if rest[0] != '[' {
+            panic(newError(Py_ValueError, "Only '.' or '[' may follow ']' in format field specifier"))
+        }
+        end := strings.IndexByte(rest, ']')
+        if end < 0 {
+            panic(newError(Py_ValueError, "Missing ']' in format string"))
+        }
+        key := rest[1:end]
+        if key == "" {
+            panic(newError(Py_ValueError, "Empty attribute in format string"))
+        }
+        if n, err := strconv.Atoi(key); err == nil && n >= 0 {
+            obj = op_SUBSCR_GET(obj, newIntegerInst(int64(n)))
+        } else {
+            obj = op_SUBSCR_GET(obj, newStringInst(key))
+        }
+        rest = rest[end+1:]
+    }
+    return obj
+}
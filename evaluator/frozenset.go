@@ -0,0 +1,79 @@
+package evaluator
+
+import (
+    "encoding/binary"
+    "unsafe"
+)
+
+/*
+    A frozenset is a set that can't be changed, which makes it
+    hashable, so that it can be in a set or be the key of a dict.
+*/
+
+type Pyfrozenset struct {
+    *objectData
+}
+
+func newPyfrozenset() *Pyfrozenset {
+    return &Pyfrozenset{
+        objectData: &objectData{
+            d: newDictInst(),
+        },
+    }
+}
+
+func (pf *Pyfrozenset) otype() Class { return Py_type }
+func (pf *Pyfrozenset) cbase() Class { return Py_object }
+func (pf *Pyfrozenset) id() int64 { return int64(uintptr(unsafe.Pointer(pf))) }
+
+var Py_frozenset = newPyfrozenset()
+func init() {
+    Py_frozenset.attrs().set(__name__, newStringInst("frozenset"))
+
+    Py_frozenset.attrs().set(__new__, newBuiltinKwFunc(__new__,
+            func(objs []Object, kwargs *DictInst) Object {
+                if len(objs) > 2 {
+                    panic(newError(Py_TypeError, "frozenset expected at most 1 argument, got %v", len(objs)-1))
+                }
+                if kwargs != nil && kwargs.len() > 0 {
+                    panic(newError(Py_TypeError, "frozenset() takes no keyword arguments"))
+                }
+
+                cls := objs[0].(Class)
+                if len(objs) == 2 {
+                    if set, ok := objs[1].(*SetInst); ok && set.class == Py_frozenset && cls == Py_frozenset {
+                        return set
+                    }
+                }
+
+                set := newSetInst()
+                set.class = cls
+                if len(objs) == 2 {
+                    set.update(objs[1])
+                }
+                return set
+            },
+        ),
+    )
+
+    Py_frozenset.attrs().set(__hash__, newBuiltinFunc(__hash__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 0)
+                self := objs[0].(*SetInst)
+
+                // the elements are in no order, so their hashes
+                // are mixed each on its own and xor-ed together
+                var h uint64
+                buf := make([]byte, 8)
+                for _, item := range self.elements() {
+                    binary.LittleEndian.PutUint64(buf, uint64(op_CALL(Py_hash, item).(*IntegerInst).Value))
+                    h ^= uint64(hash(buf))
+                }
+                binary.LittleEndian.PutUint64(buf, h^uint64(self.items.len()))
+                return newIntegerInst(hash(buf))
+            },
+        ),
+    )
+
+    initSetMethods(Py_frozenset)
+}
@@ -0,0 +1,183 @@
+//go:build ignore
+
+// gen_unicodetables writes unicodetables.go, the parts of the Unicode
+// Character Database which str needs and the unicode package lacks.
+// It reads the files of the database from the directory given, or
+// else downloads them from unicode.org:
+//
+//     go run gen_unicodetables.go [ucd-dir]
+package main
+
+import (
+    "bufio"
+    "bytes"
+    "fmt"
+    "io"
+    "log"
+    "net/http"
+    "os"
+    "path/filepath"
+    "sort"
+    "strconv"
+    "strings"
+    "unicode"
+)
+
+const ucdURL = "https://www.unicode.org/Public/14.0.0/ucd/"
+
+func main() {
+    dir := ""
+    if len(os.Args) > 1 {
+        dir = os.Args[1]
+    }
+
+    var b bytes.Buffer
+    fmt.Fprintf(&b, "// Code generated by gen_unicodetables.go from %v; DO NOT EDIT.\n\n", ucdURL)
+    fmt.Fprintf(&b, "package evaluator\n\nimport \"unicode\"\n")
+
+    lower, title, upper := specialCasing(open(dir, "SpecialCasing.txt"))
+    writeMapping(&b, "specialLower", "lower", lower)
+    writeMapping(&b, "specialTitle", "title", title)
+    writeMapping(&b, "specialUpper", "upper", upper)
+
+    fmt.Fprintf(&b, "\n// otherDigits are the digits that aren't decimal ones, like '²',\n")
+    fmt.Fprintf(&b, "// those of Numeric_Type=Digit\n")
+    writeRangeTable(&b, "otherDigits", otherDigits(open(dir, "UnicodeData.txt")))
+
+    if err := os.WriteFile("unicodetables.go", b.Bytes(), 0644); err != nil {
+        log.Fatal(err)
+    }
+}
+
+func open(dir, name string) io.ReadCloser {
+    if dir != "" {
+        f, err := os.Open(filepath.Join(dir, name))
+        if err != nil {
+            log.Fatal(err)
+        }
+        return f
+    }
+
+    resp, err := http.Get(ucdURL + name)
+    if err != nil {
+        log.Fatal(err)
+    }
+    if resp.StatusCode != http.StatusOK {
+        log.Fatalf("%v: %v", ucdURL + name, resp.Status)
+    }
+    return resp.Body
+}
+
+// eachLine calls fn with the ;-separated fields of each line of a file
+// of the database, leaving out comments and blank lines
+func eachLine(r io.ReadCloser, fn func(fields []string)) {
+    defer r.Close()
+
+    sc := bufio.NewScanner(r)
+    for sc.Scan() {
+        line := sc.Text()
+        if i := strings.IndexByte(line, '#'); i >= 0 {
+            line = line[:i]
+        }
+        if strings.TrimSpace(line) == "" {
+            continue
+        }
+
+        fields := strings.Split(line, ";")
+        for i := range fields {
+            fields[i] = strings.TrimSpace(fields[i])
+        }
+        fn(fields)
+    }
+    if err := sc.Err(); err != nil {
+        log.Fatal(err)
+    }
+}
+
+func parseCode(s string) rune {
+    v, err := strconv.ParseUint(s, 16, 32)
+    if err != nil {
+        log.Fatal(err)
+    }
+    return rune(v)
+}
+
+// specialCasing gives the unconditional mappings of SpecialCasing.txt
+// to more than one character, the others being the simple mappings
+func specialCasing(r io.ReadCloser) (lower, title, upper map[rune]string) {
+    lower, title, upper = map[rune]string{}, map[rune]string{}, map[rune]string{}
+    eachLine(r, func(fields []string) {
+        // the mappings of a language or a context are left to the code
+        if len(fields) > 4 && fields[4] != "" {
+            return
+        }
+
+        code := parseCode(fields[0])
+        for i, m := range []map[rune]string{lower, title, upper} {
+            var s []rune
+            for _, c := range strings.Fields(fields[i+1]) {
+                s = append(s, parseCode(c))
+            }
+            if len(s) != 1 {
+                m[code] = string(s)
+            }
+        }
+    })
+    return lower, title, upper
+}
+
+// otherDigits gives the characters with a digit value but no decimal
+// one in UnicodeData.txt
+func otherDigits(r io.ReadCloser) []rune {
+    var digits []rune
+    eachLine(r, func(fields []string) {
+        if fields[6] == "" && fields[7] != "" {
+            digits = append(digits, parseCode(fields[0]))
+        }
+    })
+    return digits
+}
+
+func writeMapping(b *bytes.Buffer, name, kind string, m map[rune]string) {
+    codes := make([]rune, 0, len(m))
+    for code := range m {
+        codes = append(codes, code)
+    }
+    sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+
+    fmt.Fprintf(b, "\n// %v is the full %v case mapping where it isn't one to one\n", name, kind)
+    fmt.Fprintf(b, "var %v = map[rune]string{\n", name)
+    for _, code := range codes {
+        fmt.Fprintf(b, "    0x%04X: %v,\n", code, strconv.QuoteToASCII(m[code]))
+    }
+    fmt.Fprintf(b, "}\n")
+}
+
+// writeRangeTable writes the sorted runes rs as a unicode.RangeTable,
+// with a range for each run of consecutive ones
+func writeRangeTable(b *bytes.Buffer, name string, rs []rune) {
+    var r16, r32 []string
+    latinOffset := 0
+    for i := 0; i < len(rs); {
+        j := i + 1
+        for j < len(rs) && rs[j] == rs[j-1]+1 {
+            j++
+        }
+        lo, hi := rs[i], rs[j-1]
+        if hi <= 0xFFFF {
+            r16 = append(r16, fmt.Sprintf("        {0x%04X, 0x%04X, 1},\n", lo, hi))
+            if hi <= unicode.MaxLatin1 {
+                latinOffset++
+            }
+        } else {
+            r32 = append(r32, fmt.Sprintf("        {0x%X, 0x%X, 1},\n", lo, hi))
+        }
+        i = j
+    }
+
+    fmt.Fprintf(b, "var %v = &unicode.RangeTable{\n", name)
+    fmt.Fprintf(b, "    R16: []unicode.Range16{\n%v    },\n", strings.Join(r16, ""))
+    fmt.Fprintf(b, "    R32: []unicode.Range32{\n%v    },\n", strings.Join(r32, ""))
+    fmt.Fprintf(b, "    LatinOffset: %v,\n", latinOffset)
+    fmt.Fprintf(b, "}\n")
+}
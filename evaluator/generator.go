@@ -0,0 +1,390 @@
+package evaluator
+
+import (
+    "fmt"
+    "runtime"
+    "sync"
+    "unsafe"
+
+    "github.com/realyixuan/gsubpy/ast"
+)
+
+/*
+    A generator runs its body in a goroutine of its own, which takes
+    turns with its caller through a pair of unbuffered channels, so
+    that only one of them is ever running. When the body reaches a
+    `yield`, it hands the value out and blocks until it is resumed,
+    keeping the whole state of Exec on its go stack meanwhile.
+
+    The goroutine only holds the genFrame of the generator, never the
+    GeneratorInst, so a generator abandoned at a yield is still
+    collected. Its finalizer queues the frame, and the next generator
+    made closes it, which unwinds the goroutine and frees its env.
+*/
+
+type PyGenerator struct {
+    *objectData
+}
+
+func newPyGenerator() *PyGenerator {
+    return &PyGenerator{
+        objectData: &objectData{
+            d: newDictInst(),
+        },
+    }
+}
+
+func (pg *PyGenerator) otype() Class { return Py_type }
+func (pg *PyGenerator) cbase() Class { return Py_object }
+func (pg *PyGenerator) id() int64 { return int64(uintptr(unsafe.Pointer(pg))) }
+
+var Py_generator = newPyGenerator()
+func init() {
+    Py_generator.attrs().set(__name__, newStringInst("generator"))
+
+    Py_generator.attrs().set(__iter__, newBuiltinFunc(__iter__,
+            func(objs ...Object) Object {
+                return objs[0]
+            },
+        ),
+    )
+
+    Py_generator.attrs().set(__next__, newBuiltinFunc(__next__,
+            func(objs ...Object) Object {
+                self := objs[0].(*GeneratorInst)
+                return self.resume(genMessage{value: Py_None})
+            },
+        ),
+    )
+
+    Py_generator.attrs().set(newStringInst("send"), newBuiltinFunc(newStringInst("send"),
+            func(objs ...Object) Object {
+                if len(objs) != 2 {
+                    panic(newError(Py_TypeError, "generator.send() takes exactly one argument (%v given)", len(objs)-1))
+                }
+                self := objs[0].(*GeneratorInst)
+                if !self.started && objs[1] != Py_None {
+                    panic(newError(Py_TypeError, "can't send non-None value to a just-started generator"))
+                }
+                return self.resume(genMessage{value: objs[1]})
+            },
+        ),
+    )
+
+    Py_generator.attrs().set(newStringInst("throw"), newBuiltinFunc(newStringInst("throw"),
+            func(objs ...Object) Object {
+                self := objs[0].(*GeneratorInst)
+                if len(objs) < 2 {
+                    panic(newError(Py_TypeError, "throw expected at least 1 argument, got 0"))
+                }
+                return self.throw(thrownException(objs[1:]))
+            },
+        ),
+    )
+
+    Py_generator.attrs().set(newStringInst("close"), newBuiltinFunc(newStringInst("close"),
+            func(objs ...Object) Object {
+                objs[0].(*GeneratorInst).close()
+                return Py_None
+            },
+        ),
+    )
+
+    Py_generator.attrs().set(__repr__, newBuiltinFunc(__repr__,
+            func(objs ...Object) Object {
+                self := objs[0].(*GeneratorInst)
+                return newStringInst(fmt.Sprintf("<generator object %v at 0x%x>", self.name, self.id()))
+            },
+        ),
+    )
+}
+
+// thrownException makes the exception of `throw(type[, value])`,
+// where type is either an exception class or an instance of one
+func thrownException(args []Object) *ExceptionInst {
+    typ := args[0]
+    var value Object = Py_None
+    if len(args) > 1 {
+        value = args[1]
+    }
+
+    if e, ok := typ.(*ExceptionInst); ok {
+        if value != Py_None {
+            panic(newError(Py_TypeError, "instance exception may not have a separate value"))
+        }
+        return e
+    }
+
+    if _, ok := typ.(Class); ok && op_CALL(Py_issubclass, typ, Py_BaseException) == Py_True {
+        if value == Py_None {
+            return op_CALL(typ).(*ExceptionInst)
+        }
+        if op_CALL(Py_isinstance, value, typ) == Py_True {
+            return value.(*ExceptionInst)
+        }
+        return op_CALL(typ, value).(*ExceptionInst)
+    }
+
+    panic(newError(Py_TypeError, "exceptions must be classes or instances deriving from BaseException, not %v", typeName(typ)))
+}
+
+// genMessage is what passes between a generator and its caller. Going
+// in, it's a value sent or an exception thrown at the suspended yield.
+// Coming out, it's a value yielded, or with done set, what the body
+// returned or the panic it ended with
+type genMessage struct {
+    value   Object
+    exc     interface{}
+    done    bool
+}
+
+type GeneratorInst struct {
+    *objectData
+    *genFrame
+}
+
+// genFrame is the running state of a generator, all that its goroutine
+// refers to
+type genFrame struct {
+    name        *StringInst
+    code        func(*genFrame) Object  // runs the body, giving what it returns
+
+    started     bool
+    running     bool
+    finished    bool
+
+    in          chan genMessage
+    out         chan genMessage
+}
+
+func newGeneratorInst(name *StringInst, code func(*genFrame) Object) *GeneratorInst {
+    closeAbandoned()
+
+    g := &GeneratorInst{
+        objectData: &objectData{
+            d: newDictInst(),
+        },
+        genFrame: &genFrame{
+            name: name,
+            code: code,
+            in: make(chan genMessage),
+            out: make(chan genMessage),
+        },
+    }
+    runtime.SetFinalizer(g, abandon)
+    return g
+}
+
+// newFunctionGenerator makes the generator of a call to a function with
+// `yield` in its body, env being the local env the arguments are bound in
+func newFunctionGenerator(name *StringInst, body []ast.Statement, env *Environment) *GeneratorInst {
+    return newGeneratorInst(name, func(f *genFrame) Object {
+        env.generator = f
+        rv, _ := Exec(body, env)
+        return rv
+    })
+}
+
+func (g *GeneratorInst) otype() Class { return Py_generator }
+func (g *GeneratorInst) id() int64 { return int64(uintptr(unsafe.Pointer(g))) }
+
+var (
+    abandonedMu sync.Mutex
+    abandoned   []*genFrame
+)
+
+// abandon is the finalizer of a generator. It runs on a goroutine of
+// the go runtime, so it only queues a suspended frame for closing
+func abandon(g *GeneratorInst) {
+    if g.started && !g.finished {
+        abandonedMu.Lock()
+        abandoned = append(abandoned, g.genFrame)
+        abandonedMu.Unlock()
+    }
+}
+
+// closeAbandoned closes the frames of the generators collected while
+// suspended. What the close raises is ignored, there being nobody to
+// raise it to
+func closeAbandoned() {
+    abandonedMu.Lock()
+    frames := abandoned
+    abandoned = nil
+    abandonedMu.Unlock()
+
+    for _, f := range frames {
+        func() {
+            defer func() { recover() }()
+            f.close()
+        }()
+    }
+}
+
+// run is the goroutine of the body, it starts on the first resume
+func (g *genFrame) run() {
+    msg := genMessage{done: true}
+    defer func() {
+        if r := recover(); r != nil {
+            msg.exc = r
+        }
+        g.out <- msg
+    }()
+
+    <-g.in
+    msg.value = g.code(g)
+}
+
+// resume runs the body until its next yield and gives the value
+// yielded. The end of the body is StopIteration carrying what it
+// returned, while an exception from it propagates to the caller
+func (g *genFrame) resume(msg genMessage) Object {
+    if g.running {
+        panic(newError(Py_ValueError, "generator already executing"))
+    }
+    if g.finished {
+        if msg.exc != nil {
+            panic(msg.exc)
+        }
+        panic(op_CALL(Py_StopIteration))
+    }
+
+    callDepth++
+    handling := handlingException
+    defer func() {
+        callDepth--
+        handlingException = handling
+    }()
+    if callDepth > recursionLimit {
+        panic(newError(Py_RecursionError, "maximum recursion depth exceeded"))
+    }
+
+    if !g.started {
+        g.started = true
+        go g.run()
+    }
+
+    g.running = true
+    g.in <- msg
+    reply := <-g.out
+    g.running = false
+
+    if !reply.done {
+        return reply.value
+    }
+
+    g.finished = true
+    if reply.exc != nil {
+        if e, ok := reply.exc.(*ExceptionInst); ok && op_CALL(Py_isinstance, e, Py_StopIteration) == Py_True {
+            panic(newError(Py_RuntimeError, "generator raised StopIteration"))
+        }
+        panic(reply.exc)
+    }
+    if reply.value == Py_None {
+        panic(op_CALL(Py_StopIteration))
+    }
+    panic(op_CALL(Py_StopIteration, reply.value))
+}
+
+// throw raises exc at the yield the generator is suspended at, one
+// that hasn't started is finished without running any of its body
+func (g *genFrame) throw(exc *ExceptionInst) Object {
+    if !g.started {
+        g.finished = true
+    }
+    return g.resume(genMessage{exc: exc})
+}
+
+// close raises GeneratorExit at the suspended yield, which the body
+// is expected to let propagate, or to return after catching it
+func (g *genFrame) close() {
+    if !g.started || g.finished {
+        g.finished = true
+        return
+    }
+
+    defer func() {
+        if r := recover(); r != nil {
+            e, ok := r.(*ExceptionInst)
+            if ok && (op_CALL(Py_isinstance, e, Py_GeneratorExit) == Py_True ||
+                op_CALL(Py_isinstance, e, Py_StopIteration) == Py_True) {
+                return
+            }
+            panic(r)
+        }
+    }()
+
+    g.resume(genMessage{exc: op_CALL(Py_GeneratorExit)})
+    panic(newError(Py_RuntimeError, "generator ignored GeneratorExit"))
+}
+
+// suspend is called from the goroutine of the body, handing value
+// out and blocking until the generator is resumed with msg
+func (g *genFrame) suspend(value Object) genMessage {
+    handling := handlingException
+    g.out <- genMessage{value: value}
+    msg := <-g.in
+    handlingException = handling
+    return msg
+}
+
+// yield evaluates `yield value`, which is the value sent in on resume
+func (g *genFrame) yield(value Object) Object {
+    msg := g.suspend(value)
+    if msg.exc != nil {
+        panic(msg.exc)
+    }
+    return msg.value
+}
+
+// yieldFrom evaluates `yield from iterable`, passing everything sent
+// or thrown in on to its iterator, and gives the value of the
+// StopIteration the iterator ends with
+func (g *genFrame) yieldFrom(iterable Object) Object {
+    iterator := op_CALL(Py_iter, iterable)
+
+    msg := genMessage{value: Py_None}
+    for {
+        value, stop := delegate(iterator, msg)
+        if stop != nil {
+            if value := stop.attrs().get(newStringInst("value")); value != nil {
+                return value
+            }
+            return Py_None
+        }
+        msg = g.suspend(value)
+    }
+}
+
+// delegate passes msg on to the iterator of a `yield from`, giving
+// what it yields next, or the StopIteration it finishes with
+func delegate(iterator Object, msg genMessage) (value Object, stop *ExceptionInst) {
+    defer func() {
+        if r := recover(); r != nil {
+            e, ok := r.(*ExceptionInst)
+            if !ok || op_CALL(Py_isinstance, e, Py_StopIteration) != Py_True {
+                panic(r)
+            }
+            stop = e
+        }
+    }()
+
+    if msg.exc != nil {
+        if op_CALL(Py_isinstance, msg.exc.(Object), Py_GeneratorExit) == Py_True {
+            if close := attrFromAll(iterator, newStringInst("close")); close != nil {
+                op_CALL(close)
+            }
+            panic(msg.exc)
+        }
+
+        throw := attrFromAll(iterator, newStringInst("throw"))
+        if throw == nil {
+            panic(msg.exc)
+        }
+        return op_CALL(throw, msg.exc.(Object)), nil
+    }
+
+    if msg.value == Py_None {
+        return op_CALL(Py_next, iterator), nil
+    }
+    return op_CALL(Getattr(iterator, newStringInst("send")), msg.value), nil
+}
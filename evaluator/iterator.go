@@ -0,0 +1,152 @@
+package evaluator
+
+import (
+    "unsafe"
+)
+
+/*
+    An object with __getitem__ but no __iter__ is still iterable, as a
+    sequence: its iterator gets the items at 0, 1, 2 and so on, until
+    __getitem__ raises IndexError or StopIteration.
+
+    iter(fn, sentinel) gives a callable_iterator instead, which calls
+    fn with no arguments until it returns the sentinel.
+*/
+
+type Pyiterator struct {
+    *objectData
+}
+
+func newPyiterator() *Pyiterator {
+    return &Pyiterator{
+        objectData: &objectData{
+            d: newDictInst(),
+        },
+    }
+}
+
+func (pi *Pyiterator) otype() Class { return Py_type }
+func (pi *Pyiterator) cbase() Class { return Py_object }
+func (pi *Pyiterator) id() int64 { return int64(uintptr(unsafe.Pointer(pi))) }
+
+var Py_iterator = newPyiterator()
+func init() {
+    Py_iterator.attrs().set(__name__, newStringInst("iterator"))
+
+    Py_iterator.attrs().set(__iter__, newBuiltinFunc(__iter__,
+            func(objs ...Object) Object {
+                return objs[0]
+            },
+        ),
+    )
+
+    Py_iterator.attrs().set(__next__, newBuiltinFunc(__next__,
+            func(objs ...Object) Object {
+                return objs[0].(*SeqIteratorInst).next()
+            },
+        ),
+    )
+}
+
+type SeqIteratorInst struct {
+    *objectData
+    seq     Object  // nil once exhausted
+    index   int64
+}
+
+func newSeqIteratorInst(seq Object) *SeqIteratorInst {
+    return &SeqIteratorInst{
+        objectData: &objectData{
+            d: newDictInst(),
+        },
+        seq: seq,
+    }
+}
+
+func (it *SeqIteratorInst) otype() Class { return Py_iterator }
+func (it *SeqIteratorInst) id() int64 { return int64(uintptr(unsafe.Pointer(it))) }
+
+func (it *SeqIteratorInst) next() (item Object) {
+    if it.seq == nil {
+        panic(op_CALL(Py_StopIteration))
+    }
+
+    defer func() {
+        if r := recover(); r != nil {
+            e, ok := r.(*ExceptionInst)
+            if ok && (op_CALL(Py_isinstance, e, Py_IndexError) == Py_True ||
+                op_CALL(Py_isinstance, e, Py_StopIteration) == Py_True) {
+                it.seq = nil
+                panic(op_CALL(Py_StopIteration))
+            }
+            panic(r)
+        }
+    }()
+
+    item = op_SUBSCR_GET(it.seq, newIntegerInst(it.index))
+    it.index++
+    return item
+}
+
+type Pycallable_iterator struct {
+    *objectData
+}
+
+func newPycallable_iterator() *Pycallable_iterator {
+    return &Pycallable_iterator{
+        objectData: &objectData{
+            d: newDictInst(),
+        },
+    }
+}
+
+func (pci *Pycallable_iterator) otype() Class { return Py_type }
+func (pci *Pycallable_iterator) cbase() Class { return Py_object }
+func (pci *Pycallable_iterator) id() int64 { return int64(uintptr(unsafe.Pointer(pci))) }
+
+var Py_callable_iterator = newPycallable_iterator()
+func init() {
+    Py_callable_iterator.attrs().set(__name__, newStringInst("callable_iterator"))
+
+    Py_callable_iterator.attrs().set(__iter__, newBuiltinFunc(__iter__,
+            func(objs ...Object) Object {
+                return objs[0]
+            },
+        ),
+    )
+
+    Py_callable_iterator.attrs().set(__next__, newBuiltinFunc(__next__,
+            func(objs ...Object) Object {
+                it := objs[0].(*CallIteratorInst)
+                if it.fn == nil {
+                    panic(op_CALL(Py_StopIteration))
+                }
+                item := op_CALL(it.fn)
+                if op_CALL(Py_bool, op_EQ(item, it.sentinel)) == Py_True {
+                    it.fn = nil
+                    panic(op_CALL(Py_StopIteration))
+                }
+                return item
+            },
+        ),
+    )
+}
+
+type CallIteratorInst struct {
+    *objectData
+    fn          Object  // nil once exhausted
+    sentinel    Object
+}
+
+func newCallIteratorInst(fn, sentinel Object) *CallIteratorInst {
+    return &CallIteratorInst{
+        objectData: &objectData{
+            d: newDictInst(),
+        },
+        fn: fn,
+        sentinel: sentinel,
+    }
+}
+
+func (it *CallIteratorInst) otype() Class { return Py_callable_iterator }
+func (it *CallIteratorInst) id() int64 { return int64(uintptr(unsafe.Pointer(it))) }
@@ -0,0 +1,149 @@
+package evaluator
+
+import (
+    "strings"
+)
+
+/*
+    The method resolution order of a class is the order its attributes
+    are looked up in its bases. It's the C3 linearization, which keeps
+    every class ahead of its bases, and the bases of every class in the
+    order they are listed, or fails if the hierarchy allows no such
+    order, like `class C(A, B)` where B is a subclass of A.
+*/
+
+// mroOf gives the method resolution order of cls, the builtin
+// classes have a single base each, so theirs is the chain of them
+func mroOf(cls Class) []Class {
+    if pc, ok := cls.(*Pyclass); ok {
+        return pc.mro
+    }
+
+    var mro []Class
+    for c := cls; c != nil; c = c.cbase() {
+        mro = append(mro, c)
+    }
+    return mro
+}
+
+// linearize computes the method resolution order of cls out of
+// its bases, raising TypeError if there isn't a consistent one
+func linearize(cls Class, bases []Class) []Class {
+    for i, base := range bases {
+        for _, other := range bases[:i] {
+            if base == other {
+                panic(newError(Py_TypeError, "duplicate base class %v", attrItself(base, __name__)))
+            }
+        }
+    }
+    checkLayouts(bases)
+
+    // the sequences to merge, emptied from their heads
+    var seqs [][]Class
+    for _, base := range bases {
+        seqs = append(seqs, append([]Class{}, mroOf(base)...))
+    }
+    seqs = append(seqs, append([]Class{}, bases...))
+
+    mro := []Class{cls}
+    for {
+        var nonempty [][]Class
+        for _, seq := range seqs {
+            if len(seq) > 0 {
+                nonempty = append(nonempty, seq)
+            }
+        }
+        seqs = nonempty
+        if len(seqs) == 0 {
+            return mro
+        }
+
+        // the next one is the first head that isn't in
+        // the tail of any sequence, so no base precedes it
+        var next Class
+        for _, seq := range seqs {
+            if !inTails(seq[0], seqs) {
+                next = seq[0]
+                break
+            }
+        }
+        if next == nil {
+            var names []string
+            for _, seq := range seqs {
+                name := StringOf(attrItself(seq[0], __name__)).(*StringInst).Value
+                if !containsString(names, name) {
+                    names = append(names, name)
+                }
+            }
+            panic(newError(Py_TypeError, "Cannot create a consistent method resolution\norder (MRO) for bases %v",
+                strings.Join(names, ", ")))
+        }
+
+        mro = append(mro, next)
+        for i, seq := range seqs {
+            if seq[0] == next {
+                seqs[i] = seq[1:]
+            }
+        }
+    }
+}
+
+func inTails(cls Class, seqs [][]Class) bool {
+    for _, seq := range seqs {
+        for _, c := range seq[1:] {
+            if c == cls {
+                return true
+            }
+        }
+    }
+    return false
+}
+
+func containsString(strs []string, s string) bool {
+    for _, str := range strs {
+        if str == s {
+            return true
+        }
+    }
+    return false
+}
+
+// checkLayouts raises TypeError if the bases derive from builtin
+// classes whose instances are made differently, like int and str,
+// as an instance of the class can't be both of them
+func checkLayouts(bases []Class) {
+    var solid Class = Py_object
+    for _, base := range bases {
+        s := solidBase(base)
+        switch {
+        case isSubclass(solid, s):
+        case isSubclass(s, solid):
+            solid = s
+        default:
+            panic(newError(Py_TypeError, "multiple bases have instance lay-out conflict"))
+        }
+    }
+}
+
+// solidBase is the builtin class the instances of cls are made by,
+// all the exceptions are made by that of BaseException
+func solidBase(cls Class) Class {
+    for _, c := range mroOf(cls) {
+        switch c.(type) {
+        case *Pyclass:
+        case *PyException:
+            return Py_BaseException
+        default:
+            return c
+        }
+    }
+    return Py_object
+}
+
+func classTuple(classes []Class) *TupleInst {
+    var items []Object
+    for _, cls := range classes {
+        items = append(items, cls)
+    }
+    return newTupleInst(items)
+}
@@ -24,7 +24,13 @@ package evaluator
 
 import (
     "fmt"
+    "math"
+    "math/big"
+    "sort"
     "strconv"
+    "strings"
+    "unicode"
+    "unicode/utf8"
     "unsafe"
     "crypto/sha1"
     "encoding/binary"
@@ -33,23 +39,38 @@ import (
 )
 
 var __name__ = newStringInst("__name__")
+var __bases__ = newStringInst("__bases__")
+var __mro__ = newStringInst("__mro__")
+var __class__ = newStringInst("__class__")
 var __new__ = newStringInst("__new__")
 var __init__ = newStringInst("__init__")
 var __repr__ = newStringInst("__repr__")
 var __str__ = newStringInst("__str__")
 var __eq__ = newStringInst("__eq__")
+var __ne__ = newStringInst("__ne__")
 var __lt__ = newStringInst("__lt__")
 var __gt__ = newStringInst("__gt__")
+var __le__ = newStringInst("__le__")
+var __ge__ = newStringInst("__ge__")
 var __hash__ = newStringInst("__hash__")
 var __getattribute__ = newStringInst("__getattribute__")
+var __getattr__ = newStringInst("__getattr__")
 var __setattr__ = newStringInst("__setattr__")
+var __delattr__ = newStringInst("__delattr__")
+var __get__ = newStringInst("__get__")
+var __set__ = newStringInst("__set__")
+var __delete__ = newStringInst("__delete__")
+var __set_name__ = newStringInst("__set_name__")
+var __func__ = newStringInst("__func__")
 var __call__ = newStringInst("__call__")
 var __len__ = newStringInst("__len__")
 var __bool__ = newStringInst("__bool__")
 
 var __getitem__ = newStringInst("__getitem__")
 var __setitem__ = newStringInst("__setitem__")
+var __delitem__ = newStringInst("__delitem__")
 var __contains__ = newStringInst("__contains__")
+var __missing__ = newStringInst("__missing__")
 
 var __iter__ = newStringInst("__iter__")
 var __next__ = newStringInst("__next__")
@@ -57,7 +78,48 @@ var __next__ = newStringInst("__next__")
 var __add__ = newStringInst("__add__")
 var __sub__ = newStringInst("__sub__")
 var __mul__ = newStringInst("__mul__")
+var __truediv__ = newStringInst("__truediv__")
 var __floordiv__ = newStringInst("__floordiv__")
+var __mod__ = newStringInst("__mod__")
+var __format__ = newStringInst("__format__")
+var __pow__ = newStringInst("__pow__")
+var __lshift__ = newStringInst("__lshift__")
+var __rshift__ = newStringInst("__rshift__")
+var __and__ = newStringInst("__and__")
+var __or__ = newStringInst("__or__")
+var __xor__ = newStringInst("__xor__")
+
+var __radd__ = newStringInst("__radd__")
+var __rsub__ = newStringInst("__rsub__")
+var __rmul__ = newStringInst("__rmul__")
+var __rtruediv__ = newStringInst("__rtruediv__")
+var __rfloordiv__ = newStringInst("__rfloordiv__")
+var __rmod__ = newStringInst("__rmod__")
+var __rpow__ = newStringInst("__rpow__")
+var __rlshift__ = newStringInst("__rlshift__")
+var __rrshift__ = newStringInst("__rrshift__")
+var __rand__ = newStringInst("__rand__")
+var __ror__ = newStringInst("__ror__")
+var __rxor__ = newStringInst("__rxor__")
+
+var __iadd__ = newStringInst("__iadd__")
+var __isub__ = newStringInst("__isub__")
+var __imul__ = newStringInst("__imul__")
+var __itruediv__ = newStringInst("__itruediv__")
+var __ifloordiv__ = newStringInst("__ifloordiv__")
+var __imod__ = newStringInst("__imod__")
+var __ipow__ = newStringInst("__ipow__")
+var __ilshift__ = newStringInst("__ilshift__")
+var __irshift__ = newStringInst("__irshift__")
+var __iand__ = newStringInst("__iand__")
+var __ior__ = newStringInst("__ior__")
+var __ixor__ = newStringInst("__ixor__")
+
+var __neg__ = newStringInst("__neg__")
+var __pos__ = newStringInst("__pos__")
+var __invert__ = newStringInst("__invert__")
+
+var __round__ = newStringInst("__round__")
 
 type Object interface {
     otype()          Class
@@ -73,6 +135,7 @@ type Class interface {
 type Function interface {
     Object
     call(...Object) Object
+    callKw([]Object, *DictInst) Object
 }
 
 type objectData struct {
@@ -80,31 +143,42 @@ type objectData struct {
 }
 func (o *objectData) attrs() *DictInst { return o.d }
 
-var Pyobject__new__ = newBuiltinFunc(
+// object.__new__ and object.__init__ take the arguments of a call
+// of the class only if the other one of them is overridden to
+var Pyobject__new__ = newBuiltinKwFunc(
     __new__,
-    func(objs ...Object) Object {
+    func(objs []Object, kwargs *DictInst) Object {
         cls := objs[0].(Class)
+        if len(objs) > 1 || kwargs != nil && kwargs.len() > 0 {
+            if attrItself(cls, __new__) != attrItself(Py_object, __new__) {
+                panic(newError(Py_TypeError, "object.__new__() takes exactly one argument (the type to instantiate)"))
+            }
+            if attrItself(cls, __init__) == attrItself(Py_object, __init__) {
+                panic(newError(Py_TypeError, "%v() takes no arguments", attrItself(cls, __name__)))
+            }
+        }
         return newPyInst(cls)
     },
 )
 
-var Pyobject__init__ = newBuiltinFunc(
+var Pyobject__init__ = newBuiltinKwFunc(
     __init__,
-    func(objs ...Object) Object {return nil},
+    func(objs []Object, kwargs *DictInst) Object {
+        cls := objs[0].otype()
+        if len(objs) > 1 || kwargs != nil && kwargs.len() > 0 {
+            if attrItself(cls, __init__) != attrItself(Py_object, __init__) {
+                panic(newError(Py_TypeError, "object.__init__() takes exactly one argument (the instance to initialize)"))
+            }
+            if attrItself(cls, __new__) == attrItself(Py_object, __new__) {
+                panic(newError(Py_TypeError, "%v() takes no arguments", attrItself(cls, __name__)))
+            }
+        }
+        return nil
+    },
 )
 
 var Pyobject__repr__ = newBuiltinFunc(
     __repr__,
-    func(objs ...Object) Object {
-        self := objs[0]
-        s := fmt.Sprintf("<%v object at 0x%x>",
-            op_GETATTR(self.otype(), __name__), self.id())
-        return newStringInst(s)
-    },
-)
-
-var Pyobject__str__ = newBuiltinFunc(
-    __str__,
     func(objs ...Object) Object {
         self := objs[0]
 
@@ -112,11 +186,20 @@ var Pyobject__str__ = newBuiltinFunc(
         case Class:
             return newStringInst(fmt.Sprintf("<class '%v'>", op_GETATTR(self, __name__)))
         default:
-            return Pyobject__repr__.call(self)
+            return newStringInst(fmt.Sprintf("<%v object at 0x%x>",
+                op_GETATTR(self.otype(), __name__), self.id()))
         }
     },
 )
 
+// the str of an object is its repr, unless its type tells otherwise
+var Pyobject__str__ = newBuiltinFunc(
+    __str__,
+    func(objs ...Object) Object {
+        return ReprOf(objs[0])
+    },
+)
+
 var Pyobject__eq__ = newBuiltinFunc(__eq__,
     func(objs ...Object) Object {
         self := objs[0]
@@ -124,18 +207,26 @@ var Pyobject__eq__ = newBuiltinFunc(__eq__,
         if self.id() == other.id() {
             return Py_True
         } else {
-            return Py_False
+            return Py_NotImplemented
         }
     },
 )
 
 var Pyobject__lt__ = newBuiltinFunc(__lt__,
-    func(objs ...Object) Object {return nil},
+    func(objs ...Object) Object {return Py_NotImplemented},
 )
 
 
 var Pyobject__gt__ = newBuiltinFunc(__gt__,
-    func(objs ...Object) Object {return nil},
+    func(objs ...Object) Object {return Py_NotImplemented},
+)
+
+var Pyobject__le__ = newBuiltinFunc(__le__,
+    func(objs ...Object) Object {return Py_NotImplemented},
+)
+
+var Pyobject__ge__ = newBuiltinFunc(__ge__,
+    func(objs ...Object) Object {return Py_NotImplemented},
 )
 
 var Pyobject__hash__ = newBuiltinFunc(__hash__,
@@ -155,20 +246,47 @@ var Pyobject__getattribute__ = newBuiltinFunc(__getattribute__,
         self := objs[0]
         name := objs[1]
         attr := attrFromAll(self, name.(*StringInst))
+        if attr == nil {
+            panic(newError(Py_AttributeError, "'%v' object has no attribute '%v'", typeName(self), name))
+        }
         return attr
     },
 )
 
+// a data descriptor on the type takes the assignment instead of the instance
 var Pyobject__setattr__ = newBuiltinFunc(__setattr__,
     func(objs ...Object) Object {
         self := objs[0]
         name := objs[1].(*StringInst)
         val := objs[2]
+        if attr := attrItself(self.otype(), name); attr != nil {
+            if set := attrItself(attr.otype(), __set__); set != nil {
+                op_CALL(set, attr, self, val)
+                return Py_None
+            }
+        }
         self.attrs().set(name, val)
         return Py_None
     },
 )
 
+var Pyobject__delattr__ = newBuiltinFunc(__delattr__,
+    func(objs ...Object) Object {
+        self := objs[0]
+        name := objs[1].(*StringInst)
+        if attr := attrItself(self.otype(), name); attr != nil {
+            if del := attrItself(attr.otype(), __delete__); del != nil {
+                op_CALL(del, attr, self)
+                return Py_None
+            }
+        }
+        if !self.attrs().delete(name) {
+            panic(newError(Py_AttributeError, "'%v' object has no attribute '%v'", typeName(self), name))
+        }
+        return Py_None
+    },
+)
+
 type Pyobject struct {
     *objectData
 }
@@ -195,10 +313,30 @@ func init() {
     Py_object.attrs().set(__repr__, Pyobject__repr__)
     Py_object.attrs().set(__str__, Pyobject__str__)
     Py_object.attrs().set(__eq__, Pyobject__eq__)
+
+    // __ne__ is the inverse of whatever __eq__ the type has
+    Py_object.attrs().set(__ne__, newBuiltinFunc(__ne__,
+            func(objs ...Object) Object {
+                self, other := objs[0], objs[1]
+                rv := op_CALL(attrItself(self.otype(), __eq__), self, other)
+                if rv == Py_NotImplemented {
+                    return rv
+                }
+                if op_CALL(Py_bool, rv) == Py_True {
+                    return Py_False
+                }
+                return Py_True
+            },
+        ),
+    )
+
     Py_object.attrs().set(__lt__, Pyobject__lt__)
     Py_object.attrs().set(__gt__, Pyobject__gt__)
+    Py_object.attrs().set(__le__, Pyobject__le__)
+    Py_object.attrs().set(__ge__, Pyobject__ge__)
     Py_object.attrs().set(__getattribute__, Pyobject__getattribute__)
     Py_object.attrs().set(__setattr__, Pyobject__setattr__)
+    Py_object.attrs().set(__delattr__, Pyobject__delattr__)
 }
 
 type Pytype struct {
@@ -226,10 +364,52 @@ func init()  {
                 if len(objs[1:]) == 1 {
                     return objs[1].otype()
                 }
-                var name *StringInst = objs[1].(*StringInst)
-                var base = objs[2].(Class)
-                var attrs = (objs[3]).(*DictInst)
-                return newPyclass(name, base, attrs)
+                if len(objs[1:]) != 3 {
+                    panic(newError(Py_TypeError, "type() takes 1 or 3 arguments"))
+                }
+
+                name, ok := objs[1].(*StringInst)
+                if !ok {
+                    panic(newError(Py_TypeError, "type.__new__() argument 1 must be str, not %v", typeName(objs[1])))
+                }
+                bases, ok := objs[2].(*TupleInst)
+                if !ok {
+                    panic(newError(Py_TypeError, "type.__new__() argument 2 must be tuple, not %v", typeName(objs[2])))
+                }
+                attrs, ok := objs[3].(*DictInst)
+                if !ok {
+                    panic(newError(Py_TypeError, "type.__new__() argument 3 must be dict, not %v", typeName(objs[3])))
+                }
+
+                var classes []Class
+                for _, base := range bases.items {
+                    cls, ok := base.(Class)
+                    if !ok {
+                        panic(newError(Py_TypeError, "bases must be types"))
+                    }
+                    if isFinal(cls) {
+                        panic(newError(Py_TypeError, "type '%v' is not an acceptable base type", attrItself(cls, __name__)))
+                    }
+                    classes = append(classes, cls)
+                }
+                if len(classes) == 0 {
+                    classes = []Class{Py_object}
+                }
+
+                // a class defining __eq__ alone can't keep the inherited __hash__
+                if attrs.get(__eq__) != nil && attrs.get(__hash__) == nil {
+                    attrs.set(__hash__, Py_None)
+                }
+
+                cls := newPyclass(name, classes, attrs)
+                for _, pairs := range attrs.store {
+                    for _, pair := range pairs {
+                        if setName := attrItself(pair.Value.otype(), __set_name__); setName != nil {
+                            op_CALL(setName, pair.Value, cls, pair.Key)
+                        }
+                    }
+                }
+                return cls
             },
         ),
     )
@@ -241,12 +421,15 @@ func init()  {
         ),
     )
 
-    Py_type.attrs().set(__call__, newBuiltinFunc(__call__,
-            func(objs ...Object) Object {
+    Py_type.attrs().set(__call__, newBuiltinKwFunc(__call__,
+            func(objs []Object, kwargs *DictInst) Object {
                 cls := objs[0]
-                self := op_CALL(attrItself(cls, __new__), objs...)
+                self := op_CALL_KW(attrItself(cls, __new__), objs, kwargs)
+                if !isSubclass(self.otype(), cls.(Class)) {
+                    return self
+                }
                 args := append([]Object{self}, objs[1:]...)
-                op_CALL(attrItself(cls, __init__), args...)
+                op_CALL_KW(attrItself(self.otype(), __init__), args, kwargs)
                 return self
             },
         ),
@@ -257,21 +440,54 @@ func init()  {
                 cls := objs[0]
                 name := objs[1]
                 attr := attrFromAll(cls, name.(*StringInst))
+                // a class made by a class statement has its own __mro__,
+                // for the builtin ones it's worked out
+                if attr == nil && name.(*StringInst).Value == __mro__.Value {
+                    attr = classTuple(mroOf(cls.(Class)))
+                }
+                if attr == nil {
+                    panic(newError(Py_AttributeError, "type object '%v' has no attribute '%v'",
+                        attrItself(cls, __name__), name))
+                }
                 return attr
             },
         ),
     )
+
+    Py_type.attrs().set(__delattr__, newBuiltinFunc(__delattr__,
+            func(objs ...Object) Object {
+                cls, name := objs[0], objs[1].(*StringInst)
+                if !cls.attrs().delete(name) {
+                    panic(newError(Py_AttributeError, "type object '%v' has no attribute '%v'",
+                        attrItself(cls, __name__), name))
+                }
+                return Py_None
+            },
+        ),
+    )
+}
+
+// isFinal tells the builtin classes that can't be subclassed
+func isFinal(cls Class) bool {
+    switch cls {
+    case Py_bool, Py_Noneotype, Py_NotImplementedType, Py_function, Py_builtin_function,
+        Py_generator, Py_range, Py_slice, Py_iterator, Py_str_iterator, Py_list_iterator,
+        Py_tuple_iterator, Py_range_iterator, Py_dict_keyiterator:
+        return true
+    }
+    return false
 }
 
 type Pyclass struct {
     *objectData
-    base    Class
+    bases   []Class
+    mro     []Class
     name    *StringInst
 }
 
 func newPyclass(
     name    *StringInst,
-    base    Class,
+    bases   []Class,
     dict    *DictInst,
 ) *Pyclass {
     o := &Pyclass{
@@ -279,18 +495,21 @@ func newPyclass(
             d: dict,
         },
         name: name,
-        base: base,
+        bases: bases,
     }
+    o.mro = linearize(o, bases)
     o.init()
     return o
 }
 
 func (pc *Pyclass) init() {
     pc.attrs().set(__name__, pc.name)
+    pc.attrs().set(__bases__, classTuple(pc.bases))
+    pc.attrs().set(__mro__, classTuple(pc.mro))
 }
 
 func (pc *Pyclass) otype() Class { return Py_type }
-func (pc *Pyclass) cbase() Class { return pc.base }
+func (pc *Pyclass) cbase() Class { return pc.bases[0] }
 func (pc *Pyclass) id() int64 { return int64(uintptr(unsafe.Pointer(pc))) }
 
 type PyInst struct {
@@ -330,19 +549,19 @@ func (pf *PyFunction) id() int64 { return int64(uintptr(unsafe.Pointer(pf))) }
 var Py_function = newPyFunction()
 func init() {
     Py_function.attrs().set(__name__, newStringInst("function"))
-    Py_function.attrs().set(__call__, newBuiltinFunc(__call__,
-            func(objs ...Object) Object {
+    Py_function.attrs().set(__call__, newBuiltinKwFunc(__call__,
+            func(objs []Object, kwargs *DictInst) Object {
                 self := objs[0]
-                return self.(Function).call(objs[1:]...)
+                return self.(Function).callKw(objs[1:], kwargs)
             },
         ),
     )
 }
 
-var PyBuiltinFunction__call__ = newBuiltinFunc(__call__,
-    func(objs ...Object) Object {
+var PyBuiltinFunction__call__ = newBuiltinKwFunc(__call__,
+    func(objs []Object, kwargs *DictInst) Object {
         self := objs[0]
-        return self.(Function).call(objs[1:]...)
+        return self.(Function).callKw(objs[1:], kwargs)
     },
 )
 
@@ -369,17 +588,44 @@ func init() {
     Py_builtin_function.attrs().set(__call__, PyBuiltinFunction__call__)
 }
 
+// signature is the parameter list of a function, with
+// the default values evaluated at definition time
+type signature struct {
+    params          []*StringInst
+    defaults        []Object        // defaults of the trailing params
+    varargs         *StringInst
+    kwonlyParams    []*StringInst
+    kwDefaults      *DictInst
+    kwargs          *StringInst
+}
+
+func (sig *signature) hasParam(name *StringInst) bool {
+    for _, param := range sig.params {
+        if param.Value == name.Value {
+            return true
+        }
+    }
+    for _, param := range sig.kwonlyParams {
+        if param.Value == name.Value {
+            return true
+        }
+    }
+    return false
+}
+
 type FunctionInst struct {
     *objectData
-    Name    *StringInst
-    Params  []*StringInst
-    Body    []ast.Statement
-    env     *Environment
+    Name        *StringInst
+    Params      *signature
+    Body        []ast.Statement
+    env         *Environment
+    scope       *ast.Scope
+    isGenerator bool
 }
 
 func newFunctionInst(
     name    *StringInst,
-    params  []*StringInst,
+    params  *signature,
     body    []ast.Statement,
     env     *Environment,
 ) *FunctionInst {
@@ -400,24 +646,196 @@ func (f *FunctionInst) init() {
     f.attrs().set(__name__, f.Name)
 }
 
+// recursionLimit bounds the depth of nested python calls, so that
+// runaway recursion is a RecursionError rather than a go stack overflow
+const recursionLimit = 1000
+
+var callDepth = 0
+
 func (f *FunctionInst) call(objs ...Object) Object {
-    env := f.env.DeriveEnv()
-    for i := 0; i < len(f.Params); i++ {
-        env.Set(f.Params[i], objs[i])
+    return f.callKw(objs, nil)
+}
+
+func (f *FunctionInst) callKw(objs []Object, kwargs *DictInst) Object {
+    callDepth++
+    defer func() {
+        callDepth--
+    }()
+    if callDepth > recursionLimit {
+        panic(newError(Py_RecursionError, "maximum recursion depth exceeded"))
+    }
+
+    env := f.env.deriveScope(f.scope, false)
+    env.function = f
+    f.bindArgs(env, objs, kwargs)
+    if f.isGenerator {
+        return newFunctionGenerator(f.Name, f.Body, env)
     }
     rv, _ := Exec(f.Body, env)
     return rv
 }
 
+// bindArgs assigns the arguments of a call to the parameters
+// in the local env of f, with the same rules and errors as CPython
+func (f *FunctionInst) bindArgs(env *Environment, args []Object, kwargs *DictInst) {
+    sig := f.Params
+    locals := env.Store()
+
+    if len(args) > len(sig.params) && sig.varargs == nil {
+        takes := fmt.Sprint(len(sig.params))
+        if len(sig.defaults) > 0 {
+            takes = fmt.Sprintf("from %v to %v", len(sig.params)-len(sig.defaults), len(sig.params))
+        }
+        panic(newError(Py_TypeError, "%v() takes %v positional %v but %v %v given",
+            f.Name, takes, plural(len(sig.params), "argument", "arguments"),
+            len(args), plural(len(args), "was", "were")))
+    }
+
+    for i, arg := range args {
+        if i < len(sig.params) {
+            env.Set(sig.params[i], arg)
+        }
+    }
+
+    if sig.varargs != nil {
+        var rest []Object
+        if len(args) > len(sig.params) {
+            rest = append(rest, args[len(sig.params):]...)
+        }
+        env.Set(sig.varargs, newTupleInst(rest))
+    }
+
+    var extra *DictInst
+    if sig.kwargs != nil {
+        extra = newDictInst()
+    }
+
+    if kwargs != nil {
+        for _, pairs := range kwargs.store {
+            for _, pair := range pairs {
+                name := pair.Key.(*StringInst)
+                if sig.hasParam(name) {
+                    if locals.get(name) != nil {
+                        panic(newError(Py_TypeError, "%v() got multiple values for argument '%v'", f.Name, name))
+                    }
+                    env.Set(name, pair.Value)
+                } else if extra != nil {
+                    extra.set(name, pair.Value)
+                } else {
+                    panic(newError(Py_TypeError, "%v() got an unexpected keyword argument '%v'", f.Name, name))
+                }
+            }
+        }
+    }
+
+    if extra != nil {
+        env.Set(sig.kwargs, extra)
+    }
+
+    var missing []string
+    for i, param := range sig.params {
+        if locals.get(param) != nil {
+            continue
+        }
+        if j := i - (len(sig.params) - len(sig.defaults)); j >= 0 {
+            env.Set(param, sig.defaults[j])
+        } else {
+            missing = append(missing, "'" + param.Value + "'")
+        }
+    }
+    if len(missing) > 0 {
+        panic(newError(Py_TypeError, "%v() missing %v required positional %v: %v",
+            f.Name, len(missing), plural(len(missing), "argument", "arguments"), joinNames(missing)))
+    }
+
+    for _, param := range sig.kwonlyParams {
+        if locals.get(param) != nil {
+            continue
+        }
+        if val := sig.kwDefaults.get(param); val != nil {
+            env.Set(param, val)
+        } else {
+            missing = append(missing, "'" + param.Value + "'")
+        }
+    }
+    if len(missing) > 0 {
+        panic(newError(Py_TypeError, "%v() missing %v required keyword-only %v: %v",
+            f.Name, len(missing), plural(len(missing), "argument", "arguments"), joinNames(missing)))
+    }
+}
+
+func plural(n int, singular, plural string) string {
+    if n == 1 {
+        return singular
+    }
+    return plural
+}
+
+// joinNames joins names the way CPython lists them in error
+// messages: 'a', 'a' and 'b', 'a', 'b', and 'c'
+func joinNames(names []string) string {
+    switch len(names) {
+    case 1:
+        return names[0]
+    case 2:
+        return names[0] + " and " + names[1]
+    default:
+        return strings.Join(names[:len(names)-1], ", ") + ", and " + names[len(names)-1]
+    }
+}
+
 func (f *FunctionInst) otype() Class { return Py_function }
 func (f *FunctionInst) id() int64 { return int64(uintptr(unsafe.Pointer(f))) }
 
 type builtinFn func(...Object) Object
 
+// builtinKwFn is for builtins accepting keyword arguments,
+// kwargs is nil when the call has none
+type builtinKwFn func([]Object, *DictInst) Object
+
+// builtinKwargs picks the keyword arguments names of a call to the
+// builtin fname out of kwargs, giving nil for those not passed
+func builtinKwargs(fname string, kwargs *DictInst, names ...string) []Object {
+    vals := make([]Object, len(names))
+    if kwargs == nil {
+        return vals
+    }
+
+    for _, pairs := range kwargs.store {
+        for _, pair := range pairs {
+            name := pair.Key.(*StringInst)
+            found := false
+            for i := range names {
+                if names[i] == name.Value {
+                    vals[i] = pair.Value
+                    found = true
+                }
+            }
+            if !found {
+                panic(newError(Py_TypeError, "'%v' is an invalid keyword argument for %v()", name, fname))
+            }
+        }
+    }
+    return vals
+}
+
+// checkSlotArgs checks that a special method like __add__ got n
+// arguments besides self, in the words CPython has for those
+func checkSlotArgs(objs []Object, n int) {
+    if len(objs)-1 == n {
+        return
+    }
+    if n == 1 {
+        panic(newError(Py_TypeError, "expected 1 argument, got %v", len(objs)-1))
+    }
+    panic(newError(Py_TypeError, "expected %v arguments, got %v", n, len(objs)-1))
+}
+
 type BuiltinFunctionInst struct {
     *objectData
     name    *StringInst
     gfunc   builtinFn
+    kwfunc  builtinKwFn
 }
 
 func newBuiltinFunc(name *StringInst, f builtinFn) *BuiltinFunctionInst {
@@ -430,7 +848,26 @@ func newBuiltinFunc(name *StringInst, f builtinFn) *BuiltinFunctionInst {
     }
 }
 
-func (f *BuiltinFunctionInst) call(objs ...Object) Object { return f.gfunc(objs...) }
+func newBuiltinKwFunc(name *StringInst, f builtinKwFn) *BuiltinFunctionInst {
+    return &BuiltinFunctionInst{
+        objectData: &objectData{
+            d: newDictInst(),
+        },
+        name: name,
+        kwfunc: f,
+    }
+}
+
+func (f *BuiltinFunctionInst) call(objs ...Object) Object { return f.callKw(objs, nil) }
+func (f *BuiltinFunctionInst) callKw(objs []Object, kwargs *DictInst) Object {
+    if f.kwfunc != nil {
+        return f.kwfunc(objs, kwargs)
+    }
+    if kwargs != nil && kwargs.len() > 0 {
+        panic(newError(Py_TypeError, "%v() takes no keyword arguments", f.name))
+    }
+    return f.gfunc(objs...)
+}
 func (f *BuiltinFunctionInst) otype() Class { return Py_builtin_function }
 func (f *BuiltinFunctionInst) id() int64 { return int64(uintptr(unsafe.Pointer(f))) }
 
@@ -449,70 +886,156 @@ var Py_print = newBuiltinFunc(
 var Py_len = newBuiltinFunc(
     newStringInst("len"),
     func(objs ...Object) Object {
+        if len(objs) != 1 {
+            panic(newError(Py_TypeError, "len() takes exactly one argument (%v given)", len(objs)))
+        }
         lenFn := attrItself(objs[0].otype(), __len__)
-        return op_CALL(lenFn, objs[0])
+        if lenFn == nil {
+            panic(newError(Py_TypeError, "object of type '%v' has no len()", typeName(objs[0])))
+        }
+
+        rv := op_CALL(lenFn, objs[0])
+        n, ok := rv.(*IntegerInst)
+        if !ok {
+            panic(newError(Py_TypeError, "'%v' object cannot be interpreted as an integer", typeName(rv)))
+        }
+        if n.bigValue().Sign() < 0 {
+            panic(newError(Py_ValueError, "__len__() should return >= 0"))
+        }
+        return rv
     },
 )
 
-var Py_max = newBuiltinFunc(
-    newStringInst("max"),
-    func(objs ...Object) Object {
-        var res Object
-        if len(objs) > 1 {
-            res = objs[0]
-            for _, item := range objs[1:] {
-                if op_GT(item, res) == Py_True {
-                    res = item
+var Py_max = newMinMax("max", op_GT)
+var Py_min = newMinMax("min", op_LT)
+
+// newMinMax makes max() or min(), which keep the item whose key
+// compares better than all before it by cmp
+func newMinMax(name string, cmp func(Object, Object) Object) *BuiltinFunctionInst {
+    return newBuiltinKwFunc(newStringInst(name),
+        func(objs []Object, kwargs *DictInst) Object {
+            opts := builtinKwargs(name, kwargs, "key", "default")
+            key, dflt := opts[0], opts[1]
+
+            var items []Object
+            switch {
+            case len(objs) == 0:
+                panic(newError(Py_TypeError, "%v expected at least 1 argument, got 0", name))
+            case len(objs) == 1:
+                items = iterItems(objs[0])
+            case dflt != nil:
+                panic(newError(Py_TypeError, "Cannot specify a default for %v() with multiple positional arguments", name))
+            default:
+                items = objs
+            }
+
+            if len(items) == 0 {
+                if dflt != nil {
+                    return dflt
                 }
+                panic(newError(Py_ValueError, "%v() arg is an empty sequence", name))
             }
-        } else {
-            iterator := op_CALL(Py_iter, objs[0])
-            res = iterationNext(iterator)
-            for item := iterationNext(iterator); item != nil; item = iterationNext(iterator) {
-                if op_GT(item, res) == Py_True {
-                    res = item
+
+            res, resKey := items[0], keyOf(key, items[0])
+            for _, item := range items[1:] {
+                if k := keyOf(key, item); op_CALL(Py_bool, cmp(k, resKey)) == Py_True {
+                    res, resKey = item, k
                 }
             }
+            return res
+        },
+    )
+}
+
+var Py_sorted = newBuiltinKwFunc(
+    newStringInst("sorted"),
+    func(objs []Object, kwargs *DictInst) Object {
+        if len(objs) != 1 {
+            panic(newError(Py_TypeError, "sorted expected 1 argument, got %v", len(objs)))
         }
-        return res
+        opts := builtinKwargs("sort", kwargs, "key", "reverse")
+
+        li := newListInst()
+        li.items = append(li.items, iterItems(objs[0])...)
+        sortItems(li.items, opts[0], opts[1])
+        return li
     },
 )
 
-var Py_min = newBuiltinFunc(
-    newStringInst("min"),
+// keyOf gives what item is compared by, key being a key function or None
+func keyOf(key Object, item Object) Object {
+    if key == nil || key == Py_None {
+        return item
+    }
+    return op_CALL(key, item)
+}
+
+// sortItems sorts items in place by their keys, stable even if reversed,
+// the key of each item is computed only once
+func sortItems(items []Object, key Object, reverse Object) {
+    keys := make([]Object, len(items))
+    for i, item := range items {
+        keys[i] = keyOf(key, item)
+    }
+
+    descending := reverse != nil && op_CALL(Py_bool, reverse) == Py_True
+    idx := make([]int, len(items))
+    for i := range idx {
+        idx[i] = i
+    }
+    sort.SliceStable(idx, func(i, j int) bool {
+        if descending {
+            return op_CALL(Py_bool, op_LT(keys[idx[j]], keys[idx[i]])) == Py_True
+        }
+        return op_CALL(Py_bool, op_LT(keys[idx[i]], keys[idx[j]])) == Py_True
+    })
+
+    sorted := make([]Object, len(items))
+    for i, k := range idx {
+        sorted[i] = items[k]
+    }
+    copy(items, sorted)
+}
+
+var Py_round = newBuiltinFunc(
+    newStringInst("round"),
     func(objs ...Object) Object {
-        var res Object
-        if len(objs) > 1 {
-            res = objs[0]
-            for _, item := range objs[1:] {
-                if op_LT(item, res) == Py_True {
-                    res = item
-                }
-            }
-        } else {
-            iterator := op_CALL(Py_iter, objs[0])
-            res = iterationNext(iterator)
-            for item := iterationNext(iterator); item != nil; item = iterationNext(iterator) {
-                if op_LT(item, res) == Py_True {
-                    res = item
-                }
-            }
+        if len(objs) == 0 || len(objs) > 2 {
+            panic(newError(Py_TypeError, "round() takes 1 or 2 arguments (%v given)", len(objs)))
         }
-        return res
+        roundFn := attrItself(objs[0].otype(), __round__)
+        if roundFn == nil {
+            panic(newError(Py_TypeError, "type %v doesn't define __round__ method", typeName(objs[0])))
+        }
+        return op_CALL(roundFn, objs...)
     },
 )
 
 var Py_hash = newBuiltinFunc(
     newStringInst("hash"),
     func(objs ...Object) Object {
-        hashFn := attrItself(objs[0].otype(), __hash__).(Function)
-        return op_CALL(hashFn, objs[0])
+        if len(objs) != 1 {
+            panic(newError(Py_TypeError, "hash() takes exactly one argument (%v given)", len(objs)))
+        }
+        hashFn, ok := attrItself(objs[0].otype(), __hash__).(Function)
+        if !ok {
+            panic(newError(Py_TypeError, "unhashable type: '%v'", typeName(objs[0])))
+        }
+
+        rv, ok := op_CALL(hashFn, objs[0]).(*IntegerInst)
+        if !ok {
+            panic(newError(Py_TypeError, "__hash__ method should return an integer"))
+        }
+        return rv
     },
 )
 
 var Py_id = newBuiltinFunc(
     newStringInst("id"),
     func(objs ...Object) Object {
+        if len(objs) != 1 {
+            panic(newError(Py_TypeError, "id() takes exactly one argument (%v given)", len(objs)))
+        }
         return newIntegerInst(objs[0].id())
     },
 )
@@ -520,38 +1043,113 @@ var Py_id = newBuiltinFunc(
 var Py_iter = newBuiltinFunc(
     newStringInst("iter"),
     func(objs ...Object) Object {
+        switch {
+        case len(objs) == 0:
+            panic(newError(Py_TypeError, "iter expected at least 1 argument, got 0"))
+        case len(objs) > 2:
+            panic(newError(Py_TypeError, "iter expected at most 2 arguments, got %v", len(objs)))
+        case len(objs) == 2:
+            if attrItself(objs[0].otype(), __call__) == nil {
+                panic(newError(Py_TypeError, "iter(v, w): v must be callable"))
+            }
+            return newCallIteratorInst(objs[0], objs[1])
+        }
+
         iterFn := attrItself(objs[0].otype(), __iter__)
-        return op_CALL(iterFn, objs[0])
+        if iterFn == nil || iterFn == Py_None {
+            // the sequence protocol, indexing from 0 until IndexError
+            if getitemFn := attrItself(objs[0].otype(), __getitem__); getitemFn != nil && iterFn == nil {
+                return newSeqIteratorInst(objs[0])
+            }
+            panic(newError(Py_TypeError, "'%v' object is not iterable", typeName(objs[0])))
+        }
+
+        rv := op_CALL(iterFn, objs[0])
+        if attrItself(rv.otype(), __next__) == nil {
+            panic(newError(Py_TypeError, "iter() returned non-iterator of type '%v'", typeName(rv)))
+        }
+        return rv
     },
 )
 
 var Py_next = newBuiltinFunc(
     newStringInst("next"),
     func(objs ...Object) Object {
+        if len(objs) == 0 {
+            panic(newError(Py_TypeError, "next expected at least 1 argument, got 0"))
+        }
+        if len(objs) > 2 {
+            panic(newError(Py_TypeError, "next expected at most 2 arguments, got %v", len(objs)))
+        }
+
         nextFn := attrItself(objs[0].otype(), __next__)
+        if nextFn == nil {
+            panic(newError(Py_TypeError, "'%v' object is not an iterator", typeName(objs[0])))
+        }
+        if len(objs) == 2 {
+            return nextOrDefault(nextFn, objs[0], objs[1])
+        }
         return op_CALL(nextFn, objs[0])
     },
 )
 
+// nextOrDefault gives the next item of iterator, or dflt once the
+// iterator is exhausted
+func nextOrDefault(nextFn, iterator, dflt Object) (item Object) {
+    defer func() {
+        if r := recover(); r != nil {
+            e, ok := r.(*ExceptionInst)
+            if !ok || op_CALL(Py_isinstance, e, Py_StopIteration) != Py_True {
+                panic(r)
+            }
+            item = dflt
+        }
+    }()
+    return op_CALL(nextFn, iterator)
+}
+
 var Py_issubclass = newBuiltinFunc(
     newStringInst("issubclass"),
     func(objs ...Object) Object {
+        if len(objs) != 2 {
+            panic(newError(Py_TypeError, "issubclass expected 2 arguments, got %v", len(objs)))
+        }
         cls, ok := objs[0].(Class)
         if !ok {
-            panic(Error("SyntaxError: first arguments should be class"))
+            panic(newError(Py_TypeError, "issubclass() arg 1 must be a class"))
         }
-        for ; cls != nil; cls = cls.cbase() {
-            if cls == objs[1] {
-                return Py_True
-            }
+        if isSubclass(cls, objs[1]) {
+            return Py_True
         }
         return Py_False
     },
 )
 
+// isSubclass tells if cls derives from base,
+// or from any class of base if it is a tuple
+func isSubclass(cls Class, base Object) bool {
+    if classes, ok := base.(*TupleInst); ok {
+        for _, item := range classes.items {
+            if isSubclass(cls, item) {
+                return true
+            }
+        }
+        return false
+    }
+    for _, c := range mroOf(cls) {
+        if c == base {
+            return true
+        }
+    }
+    return false
+}
+
 var Py_isinstance = newBuiltinFunc(
     newStringInst("isinstance"),
     func(objs ...Object) Object {
+        if len(objs) != 2 {
+            panic(newError(Py_TypeError, "isinstance expected 2 arguments, got %v", len(objs)))
+        }
         typ := objs[0].otype()
         return op_CALL(Py_issubclass, typ, objs[1])
     },
@@ -560,6 +1158,12 @@ var Py_isinstance = newBuiltinFunc(
 var Py_dir = newBuiltinFunc(
     newStringInst("dir"),
     func(objs ...Object) Object {
+        if len(objs) > 1 {
+            panic(newError(Py_TypeError, "dir expected at most 1 argument, got %v", len(objs)))
+        }
+        if len(objs) == 0 {
+            panic(newError(Py_TypeError, "dir() of the local scope is not supported"))
+        }
         _, ok := objs[0].(Class)
         d := newDictInst()
         if !ok {
@@ -568,7 +1172,7 @@ var Py_dir = newBuiltinFunc(
                 d.set(key.(*StringInst), nil)
             }
         } else {
-            for cls := objs[0].(Class); cls != nil; cls = cls.cbase() {
+            for _, cls := range mroOf(objs[0].(Class)) {
                 iterator := op_CALL(Py_iter, cls.attrs())
                 for key := iterationNext(iterator); key != nil; key = iterationNext(iterator) {
                     d.set(key.(*StringInst), nil)
@@ -576,7 +1180,7 @@ var Py_dir = newBuiltinFunc(
             }
         }
 
-        for cls := objs[0].otype(); cls != nil; cls = cls.cbase() {
+        for _, cls := range mroOf(objs[0].otype()) {
             iterator := op_CALL(Py_iter, cls.attrs())
             for key := iterationNext(iterator); key != nil; key = iterationNext(iterator) {
                 d.set(key.(*StringInst), nil)
@@ -596,16 +1200,153 @@ var Py_dir = newBuiltinFunc(
     },
 )
 
-type MethodInst struct {
-    *objectData
-    inst       Object
-    f          Function
-} 
+var Py_repr = newBuiltinFunc(
+    newStringInst("repr"),
+    func(objs ...Object) Object {
+        if len(objs) != 1 {
+            panic(newError(Py_TypeError, "repr() takes exactly one argument (%v given)", len(objs)))
+        }
+        return ReprOf(objs[0])
+    },
+)
 
-func newMethod(inst Object, f Function) *MethodInst {
-    return &MethodInst{
-        objectData: &objectData{
-            d: newDictInst(),
+// Py_ascii is like repr, but escapes the non-ASCII chars too
+var Py_ascii = newBuiltinFunc(
+    newStringInst("ascii"),
+    func(objs ...Object) Object {
+        if len(objs) != 1 {
+            panic(newError(Py_TypeError, "ascii() takes exactly one argument (%v given)", len(objs)))
+        }
+        s := ReprOf(objs[0]).(*StringInst)
+        if s.codePoints() == nil {
+            return s
+        }
+
+        var b strings.Builder
+        for _, r := range s.Value {
+            if r < utf8.RuneSelf {
+                b.WriteRune(r)
+            } else {
+                b.WriteString(escapeRune(r))
+            }
+        }
+        return newStringInst(b.String())
+    },
+)
+
+var Py_getattr = newBuiltinFunc(
+    newStringInst("getattr"),
+    func(objs ...Object) Object {
+        if len(objs) < 2 || len(objs) > 3 {
+            panic(newError(Py_TypeError, "getattr expected 2 or 3 arguments, got %v", len(objs)))
+        }
+
+        name := attrName(objs[1])
+        if len(objs) == 2 {
+            return op_GETATTR(objs[0], name)
+        }
+        if rv := catchAttributeError(func() Object { return op_GETATTR(objs[0], name) }); rv != nil {
+            return rv
+        }
+        return objs[2]
+    },
+)
+
+var Py_setattr = newBuiltinFunc(
+    newStringInst("setattr"),
+    func(objs ...Object) Object {
+        if len(objs) != 3 {
+            panic(newError(Py_TypeError, "setattr expected 3 arguments, got %v", len(objs)))
+        }
+        op_SETATTR(objs[0], attrName(objs[1]), objs[2])
+        return Py_None
+    },
+)
+
+var Py_hasattr = newBuiltinFunc(
+    newStringInst("hasattr"),
+    func(objs ...Object) Object {
+        if len(objs) != 2 {
+            panic(newError(Py_TypeError, "hasattr expected 2 arguments, got %v", len(objs)))
+        }
+
+        name := attrName(objs[1])
+        if catchAttributeError(func() Object { return op_GETATTR(objs[0], name) }) == nil {
+            return Py_False
+        }
+        return Py_True
+    },
+)
+
+var Py_delattr = newBuiltinFunc(
+    newStringInst("delattr"),
+    func(objs ...Object) Object {
+        if len(objs) != 2 {
+            panic(newError(Py_TypeError, "delattr expected 2 arguments, got %v", len(objs)))
+        }
+        op_DELATTR(objs[0], attrName(objs[1]))
+        return Py_None
+    },
+)
+
+var Py_ord = newBuiltinFunc(
+    newStringInst("ord"),
+    func(objs ...Object) Object {
+        if len(objs) != 1 {
+            panic(newError(Py_TypeError, "ord() takes exactly one argument (%v given)", len(objs)))
+        }
+        s, ok := objs[0].(*StringInst)
+        if !ok {
+            panic(newError(Py_TypeError, "ord() expected string of length 1, but %v found", typeName(objs[0])))
+        }
+        if n := s.length(); n != 1 {
+            panic(newError(Py_TypeError, "ord() expected a character, but string of length %v found", n))
+        }
+        r, _ := utf8.DecodeRuneInString(s.Value)
+        return newIntegerInst(int64(r))
+    },
+)
+
+var Py_chr = newBuiltinFunc(
+    newStringInst("chr"),
+    func(objs ...Object) Object {
+        if len(objs) != 1 {
+            panic(newError(Py_TypeError, "chr() takes exactly one argument (%v given)", len(objs)))
+        }
+        i, ok := objs[0].(*IntegerInst)
+        if !ok {
+            panic(newError(Py_TypeError, "'%v' object cannot be interpreted as an integer", typeName(objs[0])))
+        }
+        if i.big != nil {
+            panic(newError(Py_OverflowError, "Python int too large to convert to C int"))
+        }
+        if i.Value < 0 || i.Value > unicode.MaxRune {
+            panic(newError(Py_ValueError, "chr() arg not in range(0x110000)"))
+        }
+        // surrogates can't be in UTF-8, they turn into U+FFFD
+        return newStringInst(string(rune(i.Value)))
+    },
+)
+
+// attrName is the name given to getattr() and the like, which must be a str
+func attrName(obj Object) *StringInst {
+    name, ok := obj.(*StringInst)
+    if !ok {
+        panic(newError(Py_TypeError, "attribute name must be string, not '%v'", typeName(obj)))
+    }
+    return name
+}
+
+type MethodInst struct {
+    *objectData
+    inst       Object
+    f          Function
+} 
+
+func newMethod(inst Object, f Function) *MethodInst {
+    return &MethodInst{
+        objectData: &objectData{
+            d: newDictInst(),
             },
         f: f,
         inst: inst,
@@ -614,9 +1355,10 @@ func newMethod(inst Object, f Function) *MethodInst {
 
 func (m *MethodInst) otype() Class { return Py_function }
 func (m *MethodInst) id() int64 { return int64(uintptr(unsafe.Pointer(m))) }
-func (m *MethodInst) call(objs ...Object) Object {
+func (m *MethodInst) call(objs ...Object) Object { return m.callKw(objs, nil) }
+func (m *MethodInst) callKw(objs []Object, kwargs *DictInst) Object {
     objs = append([]Object{m.inst}, objs...)
-    return op_CALL(m.f, objs...)
+    return op_CALL_KW(m.f, objs, kwargs)
 }
 
 type PyNoneotype struct {
@@ -639,12 +1381,12 @@ func (pn *PyNoneotype) id() int64 { return int64(uintptr(unsafe.Pointer(pn))) }
 var Py_Noneotype = newPyNoneotype()
 func init() {
     Py_Noneotype.attrs().set(__name__, newStringInst("Noneotype"))
-    Py_Noneotype.attrs().set(__str__, newBuiltinFunc(__str__,
+    noneRepr := newBuiltinFunc(__repr__,
             func(objs ...Object) Object {
                 return newStringInst("None")
             },
-        ),
-    )
+        )
+    Py_Noneotype.attrs().set(__repr__, noneRepr)
 }
 
 type PyNone struct {
@@ -662,6 +1404,170 @@ func (n *PyNone) id() int64 { return int64(uintptr(unsafe.Pointer(n))) }
 
 var Py_None = newPyNone()
 
+type PyNotImplementedType struct {
+    *objectData
+}
+
+func newPyNotImplementedType() *PyNotImplementedType {
+    return &PyNotImplementedType{
+        objectData: &objectData{
+            d: newDictInst(),
+        },
+    }
+}
+
+func (pn *PyNotImplementedType) otype() Class { return Py_type }
+func (pn *PyNotImplementedType) cbase() Class { return Py_object }
+func (pn *PyNotImplementedType) id() int64 { return int64(uintptr(unsafe.Pointer(pn))) }
+
+var Py_NotImplementedType = newPyNotImplementedType()
+func init() {
+    Py_NotImplementedType.attrs().set(__name__, newStringInst("NotImplementedType"))
+
+    notImplementedRepr := newBuiltinFunc(__repr__,
+            func(objs ...Object) Object {
+                return newStringInst("NotImplemented")
+            },
+        )
+    Py_NotImplementedType.attrs().set(__repr__, notImplementedRepr)
+}
+
+// NotImplementedInst is the type of Py_NotImplemented, which a binary
+// method returns when it doesn't support the other operand, so that
+// the operator can try the reflected method of the other operand
+type NotImplementedInst struct {
+    *objectData
+}
+
+func (n *NotImplementedInst) otype() Class { return Py_NotImplementedType }
+func (n *NotImplementedInst) id() int64 { return int64(uintptr(unsafe.Pointer(n))) }
+
+var Py_NotImplemented = &NotImplementedInst{
+    objectData: &objectData{},
+}
+
+// intFromArgs makes the int of `int(args...)`
+func intFromArgs(args []Object) *IntegerInst {
+    if len(args) > 2 {
+        panic(newError(Py_TypeError, "int() takes at most 2 arguments (%v given)", len(args)))
+    }
+    if len(args) == 0 {
+        return newIntegerInst(int64(0))
+    }
+
+    if len(args) == 2 {
+        base := asInt(args[1])
+        if base != 0 && (base < 2 || base > 36) {
+            panic(newError(Py_ValueError, "int() base must be >= 2 and <= 36, or 0"))
+        }
+        var s string
+        switch o := args[0].(type) {
+        case *StringInst:
+            s = o.Value
+        case *BytesInst:
+            s = o.Value
+        default:
+            panic(newError(Py_TypeError, "int() can't convert non-string with explicit base"))
+        }
+        inst := parseIntString(s, base)
+        if inst == nil {
+            panic(newError(Py_ValueError, "invalid literal for int() with base %v: %v", base, ReprOf(args[0])))
+        }
+        return inst
+    }
+
+    switch o := args[0].(type) {
+    case *StringInst:
+        inst := parseIntString(o.Value, 10)
+        if inst == nil {
+            panic(newError(Py_ValueError, "invalid literal for int() with base 10: %v", ReprOf(o)))
+        }
+        return inst
+    case *IntegerInst:
+        if o.big != nil {
+            return newIntegerFromBig(o.big)
+        }
+        return newIntegerInst(o.Value)
+    case *FloatInst:
+        return newIntegerFromFloat(o.Value)
+    }
+
+    panic(newError(Py_TypeError, "int() argument must be a string or a number, not '%v'", typeName(args[0])))
+}
+
+// parseIntString reads s as int() does in the given base, 0 meaning
+// the base comes from the prefix; it gives nil if s isn't valid
+func parseIntString(s string, base int) *IntegerInst {
+    s = strings.TrimSpace(asciiDecimals(s))
+    neg := false
+    if s != "" && (s[0] == '+' || s[0] == '-') {
+        neg = s[0] == '-'
+        s = s[1:]
+    }
+
+    prefixed := false
+    if len(s) >= 2 && s[0] == '0' {
+        prefixBase := 0
+        switch s[1] {
+        case 'x', 'X':
+            prefixBase = 16
+        case 'o', 'O':
+            prefixBase = 8
+        case 'b', 'B':
+            prefixBase = 2
+        }
+        if prefixBase != 0 && (base == 0 || base == prefixBase) {
+            base, s, prefixed = prefixBase, s[2:], true
+        }
+    }
+    if base == 0 {
+        // like the literal, a decimal can't have leading zeros
+        if s != "" && s[0] == '0' && strings.Trim(s, "0_") != "" {
+            return nil
+        }
+        base = 10
+    }
+
+    digits := make([]byte, 0, len(s))
+    for i := 0; i < len(s); i++ {
+        c := s[i]
+        if c == '_' {
+            // a single underscore may follow a digit or the prefix
+            if i+1 == len(s) || s[i+1] == '_' || (i == 0 && !prefixed) {
+                return nil
+            }
+            continue
+        }
+        if digitValue(c) >= base {
+            return nil
+        }
+        digits = append(digits, c)
+    }
+    if len(digits) == 0 {
+        return nil
+    }
+
+    b, _ := new(big.Int).SetString(string(digits), base)
+    if neg {
+        b.Neg(b)
+    }
+    return newIntegerFromBig(b)
+}
+
+// digitValue gives the value of an ASCII digit or letter as a digit,
+// or 36 for anything else
+func digitValue(c byte) int {
+    switch {
+    case '0' <= c && c <= '9':
+        return int(c - '0')
+    case 'a' <= c && c <= 'z':
+        return int(c - 'a') + 10
+    case 'A' <= c && c <= 'Z':
+        return int(c - 'A') + 10
+    }
+    return 36
+}
+
 type Pyint struct {
     *objectData
 }
@@ -685,149 +1591,355 @@ func init() {
 
     Py_int.attrs().set(__new__, newBuiltinFunc(__new__,
             func(objs ...Object) Object {
-                if len(objs[1:]) == 0 {
-                    return newIntegerInst(int64(0))
-                }
-
-                switch o := objs[1].(type) {
-                case *StringInst:
-                    v, _ := strconv.Atoi(o.Value)
-                    return newIntegerInst(int64(v))
-                case *IntegerInst:
-                    return o
-                }
-
-                return nil
+                inst := intFromArgs(objs[1:])
+                inst.class = objs[0].(Class)
+                return inst
             },
         ),
     )
 
     Py_int.attrs().set(__hash__, newBuiltinFunc(__hash__,
             func(objs ...Object) Object {
-                return objs[0]
+                self := objs[0].(*IntegerInst)
+                if self.big == nil {
+                    return newIntegerInst(self.Value)
+                }
+
+                v := hash(self.big.Bytes())
+                if self.big.Sign() < 0 {
+                    v = -v
+                }
+                return newIntegerInst(v)
             },
         ),
     )
 
     Py_int.attrs().set(__repr__, newBuiltinFunc(__repr__,
             func(objs ...Object) Object {
-                v := strconv.FormatInt(objs[0].(*IntegerInst).Value, 10)
-                return newStringInst(v)
+                return newStringInst(objs[0].(*IntegerInst).String())
             },
         ),
     )
 
-    Py_int.attrs().set(__str__, newBuiltinFunc(__str__,
-            func(objs ...Object) Object {
-                v := strconv.FormatInt(objs[0].(*IntegerInst).Value, 10)
-                return newStringInst(v)
-            },
-        ),
-    )
+    Py_int.attrs().set(__eq__, newIntCompare(__eq__, func(c int) bool { return c == 0 }))
+    Py_int.attrs().set(__gt__, newIntCompare(__gt__, func(c int) bool { return c > 0 }))
+    Py_int.attrs().set(__lt__, newIntCompare(__lt__, func(c int) bool { return c < 0 }))
+    Py_int.attrs().set(__ge__, newIntCompare(__ge__, func(c int) bool { return c >= 0 }))
+    Py_int.attrs().set(__le__, newIntCompare(__le__, func(c int) bool { return c <= 0 }))
 
-    Py_int.attrs().set(__eq__, newBuiltinFunc(__eq__,
+    Py_int.attrs().set(__bool__, newBuiltinFunc(__bool__,
             func(objs ...Object) Object {
-                v1 := objs[0].(*IntegerInst).Value
-                v2 := objs[1].(*IntegerInst).Value
-                
-                if v1 == v2 {
-                    return Py_True
-                } else {
+                self := objs[0].(*IntegerInst)
+                if self.Value == 0 {
                     return Py_False
+                } else {
+                    return Py_True
                 }
             },
         ),
     )
 
-    Py_int.attrs().set(__gt__, newBuiltinFunc(__gt__,
-            func(objs ...Object) Object {
-                v1 := objs[0].(*IntegerInst).Value
-                v2 := objs[1].(*IntegerInst).Value
-                
-                if v1 > v2 {
-                    return Py_True
-                } else {
-                    return Py_False
+    Py_int.attrs().set(__add__, newIntArith(__add__,
+        func(a, b int64) (int64, bool) {
+            r := a + b
+            return r, (r > a) == (b > 0)
+        },
+        func(a, b *big.Int) Object {
+            return newIntegerFromBig(new(big.Int).Add(a, b))
+        },
+    ))
+
+    Py_int.attrs().set(__sub__, newIntArith(__sub__,
+        func(a, b int64) (int64, bool) {
+            r := a - b
+            return r, (r < a) == (b > 0)
+        },
+        func(a, b *big.Int) Object {
+            return newIntegerFromBig(new(big.Int).Sub(a, b))
+        },
+    ))
+
+    Py_int.attrs().set(__mul__, newIntArith(__mul__,
+        func(a, b int64) (int64, bool) {
+            if a == 0 || b == 0 {
+                return 0, true
+            }
+            r := a * b
+            return r, r/b == a && !(a == -1 && b == math.MinInt64) && !(b == -1 && a == math.MinInt64)
+        },
+        func(a, b *big.Int) Object {
+            return newIntegerFromBig(new(big.Int).Mul(a, b))
+        },
+    ))
+
+    Py_int.attrs().set(__truediv__, newIntArith(__truediv__,
+        nil,
+        func(a, b *big.Int) Object {
+            if b.Sign() == 0 {
+                panic(newError(Py_ZeroDivisionError, "division by zero"))
+            }
+            if a.IsInt64() && b.IsInt64() && isExactFloat(a.Int64()) && isExactFloat(b.Int64()) {
+                return newFloatInst(float64(a.Int64()) / float64(b.Int64()))
+            }
+
+            v, _ := new(big.Rat).SetFrac(a, b).Float64()
+            if math.IsInf(v, 0) {
+                panic(newError(Py_OverflowError, "integer division result too large for a float"))
+            }
+            return newFloatInst(v)
+        },
+    ))
+
+    Py_int.attrs().set(__floordiv__, newIntArith(__floordiv__,
+        func(a, b int64) (int64, bool) {
+            if b == 0 {
+                panic(newError(Py_ZeroDivisionError, "integer division or modulo by zero"))
+            }
+            return floorDiv(a, b), !(a == math.MinInt64 && b == -1)
+        },
+        func(a, b *big.Int) Object {
+            if b.Sign() == 0 {
+                panic(newError(Py_ZeroDivisionError, "integer division or modulo by zero"))
+            }
+            q, _ := bigFloorDivmod(a, b)
+            return newIntegerFromBig(q)
+        },
+    ))
+
+    Py_int.attrs().set(__mod__, newIntArith(__mod__,
+        func(a, b int64) (int64, bool) {
+            if b == 0 {
+                panic(newError(Py_ZeroDivisionError, "integer division or modulo by zero"))
+            }
+            r := a % b
+            if r != 0 && (r < 0) != (b < 0) {
+                r += b
+            }
+            return r, true
+        },
+        func(a, b *big.Int) Object {
+            if b.Sign() == 0 {
+                panic(newError(Py_ZeroDivisionError, "integer division or modulo by zero"))
+            }
+            _, r := bigFloorDivmod(a, b)
+            return newIntegerFromBig(r)
+        },
+    ))
+
+    Py_int.attrs().set(__pow__, newIntArith(__pow__,
+        nil,
+        func(a, b *big.Int) Object {
+            // a negative exponent gives a float, like 2 ** -1 == 0.5
+            if b.Sign() < 0 {
+                x, _ := new(big.Float).SetInt(a).Float64()
+                y, _ := new(big.Float).SetInt(b).Float64()
+                if x == 0 {
+                    panic(newError(Py_ZeroDivisionError, "0.0 cannot be raised to a negative power"))
                 }
+                return newFloatInst(math.Pow(x, y))
+            }
+            return newIntegerFromBig(new(big.Int).Exp(a, b, nil))
+        },
+    ))
 
-            },
-        ),
-    )
+    Py_int.attrs().set(__lshift__, newIntArith(__lshift__,
+        nil,
+        func(a, b *big.Int) Object {
+            if b.Sign() < 0 {
+                panic(newError(Py_ValueError, "negative shift count"))
+            }
+            if a.Sign() == 0 {
+                return newIntegerInst(0)
+            }
+            if !b.IsInt64() || b.Int64() > math.MaxInt32 {
+                panic(newError(Py_OverflowError, "too many digits in integer"))
+            }
+            return newIntegerFromBig(new(big.Int).Lsh(a, uint(b.Int64())))
+        },
+    ))
 
-    Py_int.attrs().set(__lt__, newBuiltinFunc(__lt__,
-            func(objs ...Object) Object {
-                v1 := objs[0].(*IntegerInst).Value
-                v2 := objs[1].(*IntegerInst).Value
-                
-                if v1 < v2 {
-                    return Py_True
-                } else {
-                    return Py_False
+    Py_int.attrs().set(__rshift__, newIntArith(__rshift__,
+        nil,
+        func(a, b *big.Int) Object {
+            if b.Sign() < 0 {
+                panic(newError(Py_ValueError, "negative shift count"))
+            }
+            // shifting out all the bits leaves the sign only
+            if !b.IsInt64() || b.Int64() > int64(a.BitLen()) {
+                if a.Sign() < 0 {
+                    return newIntegerInst(-1)
                 }
-            },
-        ),
-    )
+                return newIntegerInst(0)
+            }
+            return newIntegerFromBig(new(big.Int).Rsh(a, uint(b.Int64())))
+        },
+    ))
 
-    Py_int.attrs().set(__bool__, newBuiltinFunc(__bool__,
+    Py_int.attrs().set(__and__, newIntArith(__and__,
+        func(a, b int64) (int64, bool) { return a & b, true },
+        func(a, b *big.Int) Object { return newIntegerFromBig(new(big.Int).And(a, b)) },
+    ))
+
+    Py_int.attrs().set(__or__, newIntArith(__or__,
+        func(a, b int64) (int64, bool) { return a | b, true },
+        func(a, b *big.Int) Object { return newIntegerFromBig(new(big.Int).Or(a, b)) },
+    ))
+
+    Py_int.attrs().set(__xor__, newIntArith(__xor__,
+        func(a, b int64) (int64, bool) { return a ^ b, true },
+        func(a, b *big.Int) Object { return newIntegerFromBig(new(big.Int).Xor(a, b)) },
+    ))
+
+    Py_int.attrs().set(__neg__, newBuiltinFunc(__neg__,
             func(objs ...Object) Object {
                 self := objs[0].(*IntegerInst)
-                if self.Value == 0 {
-                    return Py_False
-                } else {
-                    return Py_True
+                if self.big == nil && self.Value != math.MinInt64 {
+                    return newIntegerInst(-self.Value)
                 }
+                return newIntegerFromBig(new(big.Int).Neg(self.bigValue()))
             },
         ),
     )
 
-    Py_int.attrs().set(__add__, newBuiltinFunc(__add__,
+    Py_int.attrs().set(__pos__, newBuiltinFunc(__pos__,
             func(objs ...Object) Object {
-                if objs[0].otype() != objs[1].otype() {
-                    panic(Error("otypeError: two different types"))
-                }
-
-                self, other := objs[0].(*IntegerInst), objs[1].(*IntegerInst)
-                return newIntegerInst(self.Value + other.Value)
+                return newIntegerFromBig(objs[0].(*IntegerInst).bigValue())
             },
         ),
     )
 
-    Py_int.attrs().set(__sub__, newBuiltinFunc(__sub__,
+    Py_int.attrs().set(__invert__, newBuiltinFunc(__invert__,
             func(objs ...Object) Object {
-                self, other := objs[0].(*IntegerInst), objs[1].(*IntegerInst)
-                return newIntegerInst(self.Value - other.Value)
+                self := objs[0].(*IntegerInst)
+                if self.big == nil {
+                    return newIntegerInst(^self.Value)
+                }
+                return newIntegerFromBig(new(big.Int).Not(self.big))
             },
         ),
     )
 
-    Py_int.attrs().set(__mul__, newBuiltinFunc(__mul__,
+    Py_int.attrs().set(__round__, newBuiltinFunc(__round__,
             func(objs ...Object) Object {
-                self, other := objs[0].(*IntegerInst), objs[1].(*IntegerInst)
-                return newIntegerInst(self.Value * other.Value)
+                self := objs[0].(*IntegerInst)
+                if len(objs) == 1 || objs[1] == Py_None {
+                    return newIntegerFromBig(self.bigValue())
+                }
+
+                ndigits, ok := objs[1].(*IntegerInst)
+                if !ok {
+                    panic(newError(Py_TypeError, "'%v' object cannot be interpreted as an integer", typeName(objs[1])))
+                }
+                if ndigits.Value >= 0 {
+                    return newIntegerFromBig(self.bigValue())
+                }
+
+                // halfway goes to the even multiple
+                pow := new(big.Int).Exp(big.NewInt(10), new(big.Int).Neg(ndigits.bigValue()), nil)
+                q, r := bigFloorDivmod(self.bigValue(), pow)
+                if c := new(big.Int).Lsh(r, 1).Cmp(pow); c > 0 || c == 0 && q.Bit(0) == 1 {
+                    q.Add(q, big.NewInt(1))
+                }
+                return newIntegerFromBig(q.Mul(q, pow))
             },
         ),
     )
 
-    Py_int.attrs().set(__floordiv__, newBuiltinFunc(__floordiv__,
-            func(objs ...Object) Object {
-                self, other := objs[0].(*IntegerInst), objs[1].(*IntegerInst)
+}
+
+// newIntCompare makes a comparison method of int, which tells by
+// the sign of the comparison result. It gives up on other types,
+// leaving them to the reflected method
+func newIntCompare(name *StringInst, cmp func(int) bool) *BuiltinFunctionInst {
+    return newBuiltinFunc(name,
+        func(objs ...Object) Object {
+            self := objs[0].(*IntegerInst)
+            other, ok := objs[1].(*IntegerInst)
+            if !ok {
+                return Py_NotImplemented
+            }
 
-                if other.Value == 0 {
-                    panic(Error("ZeroDivisionError: division by zero"))
+            var c int
+            if self.big == nil && other.big == nil {
+                switch {
+                case self.Value < other.Value:
+                    c = -1
+                case self.Value > other.Value:
+                    c = 1
                 }
+            } else {
+                c = self.bigValue().Cmp(other.bigValue())
+            }
 
-                return newIntegerInst(self.Value / other.Value)
-            },
-        ),
+            if cmp(c) {
+                return Py_True
+            }
+            return Py_False
+        },
+    )
+}
+
+// newIntArith makes an arithmetic method of int, small works on int64
+// and reports false on overflow, in which case, or when any operand
+// is already big, large takes over with math/big
+func newIntArith(
+    name    *StringInst,
+    small   func(int64, int64) (int64, bool),
+    large   func(*big.Int, *big.Int) Object,
+) *BuiltinFunctionInst {
+    return newBuiltinFunc(name,
+        func(objs ...Object) Object {
+            self := objs[0].(*IntegerInst)
+            other, ok := objs[1].(*IntegerInst)
+            if !ok {
+                return Py_NotImplemented
+            }
+
+            if small != nil && self.big == nil && other.big == nil {
+                if r, ok := small(self.Value, other.Value); ok {
+                    return newIntegerInst(r)
+                }
+            }
+            return large(self.bigValue(), other.bigValue())
+        },
     )
+}
+
+// floorDiv rounds the quotient toward negative infinity like python,
+// rather than toward zero like go
+func floorDiv(a, b int64) int64 {
+    q := a / b
+    if a%b != 0 && (a < 0) != (b < 0) {
+        q--
+    }
+    return q
+}
 
+// bigFloorDivmod is floorDiv for big.Int along with the modulo,
+// which has the same sign as b
+func bigFloorDivmod(a, b *big.Int) (*big.Int, *big.Int) {
+    q, r := new(big.Int).QuoRem(a, b, new(big.Int))
+    if r.Sign() != 0 && r.Sign() != b.Sign() {
+        q.Sub(q, big.NewInt(1))
+        r.Add(r, b)
+    }
+    return q, r
 }
 
+// isExactFloat tells if v converts to float64 without rounding
+func isExactFloat(v int64) bool {
+    return -1<<53 <= v && v <= 1<<53
+}
 
+// IntegerInst is an int of any size, Value holds it as long as it fits
+// in int64, otherwise big does, and Value is clamped to the int64 range,
+// so that code only caring about small ints, like indexing, can keep
+// reading Value
 type IntegerInst struct {
     *objectData
     class   Class
     Value   int64
+    big     *big.Int
 }
 
 func newIntegerInst(v int64) *IntegerInst {
@@ -840,9 +1952,63 @@ func newIntegerInst(v int64) *IntegerInst {
     }
 }
 
+func newIntegerFromBig(b *big.Int) *IntegerInst {
+    if b.IsInt64() {
+        return newIntegerInst(b.Int64())
+    }
+
+    i := newIntegerInst(math.MaxInt64)
+    if b.Sign() < 0 {
+        i.Value = math.MinInt64
+    }
+    i.big = b
+    return i
+}
+
+// newIntegerFromString parses a decimal int of any size,
+// it gives nil if s is not one
+func newIntegerFromString(s string) *IntegerInst {
+    if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+        return newIntegerInst(v)
+    }
+    if b, ok := new(big.Int).SetString(s, 10); ok {
+        return newIntegerFromBig(b)
+    }
+    return nil
+}
+
+func newIntegerFromFloat(v float64) *IntegerInst {
+    if math.IsNaN(v) {
+        panic(newError(Py_ValueError, "cannot convert float NaN to integer"))
+    } else if math.IsInf(v, 0) {
+        panic(newError(Py_OverflowError, "cannot convert float infinity to integer"))
+    }
+
+    if math.Abs(v) < 1<<63 {
+        return newIntegerInst(int64(v))
+    }
+    b, _ := big.NewFloat(v).Int(nil)
+    return newIntegerFromBig(b)
+}
+
 func (i *IntegerInst) otype() Class { return i.class }
 func (i *IntegerInst) id() int64 { return int64(uintptr(unsafe.Pointer(i))) }
 
+// bigValue gives the value as a big.Int, which is not to be modified
+func (i *IntegerInst) bigValue() *big.Int {
+    if i.big != nil {
+        return i.big
+    }
+    return big.NewInt(i.Value)
+}
+
+func (i *IntegerInst) String() string {
+    if i.big != nil {
+        return i.big.String()
+    }
+    return strconv.FormatInt(i.Value, 10)
+}
+
 type Pystr_iterator struct {
     *objectData
 }
@@ -870,11 +2036,11 @@ func (psi *Pystr_iterator) init() {
     psi.attrs().set(__next__, newBuiltinFunc(__next__,
             func(objs ...Object) Object {
                 self := objs[0].(*StringIteratorInst)
-                if self.idx >= op_CALL(Py_len, self.stringInst).(*IntegerInst).Value {
+                if self.idx >= int64(self.stringInst.length()) {
                     panic(op_CALL(Py_StopIteration))
                 }
                 self.idx += 1
-                return op_SUBSCR_GET(self.stringInst, newIntegerInst(self.idx-1))
+                return newStringInst(self.stringInst.at(int(self.idx-1)))
             },
         ),
     )
@@ -914,17 +2080,41 @@ var Pystr__hash__ = newBuiltinFunc(__hash__,
 
 var Pystr__eq__ = newBuiltinFunc(__eq__,
     func(objs ...Object) Object {
-        if s2, ok := objs[1].(*StringInst); ok {
-            s1 := objs[0].(*StringInst)
-            if s1.Value == s2.Value {
-                return Py_True
-            }
+        s2, ok := objs[1].(*StringInst)
+        if !ok {
+            return Py_NotImplemented
         }
 
+        if objs[0].(*StringInst).Value == s2.Value {
+            return Py_True
+        }
         return Py_False
     },
 )
 
+// Pystr__mul__ repeats a str n times, which is both
+// __mul__ and __rmul__, as `n * s` is the same as `s * n`
+var Pystr__mul__ = newBuiltinFunc(__mul__,
+    func(objs ...Object) Object {
+        n, ok := objs[1].(*IntegerInst)
+        if !ok {
+            return Py_NotImplemented
+        }
+        if n.big != nil {
+            panic(newError(Py_OverflowError, "cannot fit 'int' into an index-sized integer"))
+        }
+
+        self := objs[0].(*StringInst)
+        if n.Value <= 0 || self.Value == "" {
+            return newStringInst("")
+        }
+        if int64(len(self.Value)) > math.MaxInt32/n.Value {
+            panic(newError(Py_OverflowError, "repeated string is too long"))
+        }
+        return newStringInst(strings.Repeat(self.Value, int(n.Value)))
+    },
+)
+
 type Pystr struct {
     *objectData
 }
@@ -950,10 +2140,26 @@ func init() {
             func(objs ...Object) Object {
                 var inst *StringInst
 
-                if len(objs[1:]) == 0 {
+                switch {
+                case len(objs[1:]) > 3:
+                    panic(newError(Py_TypeError, "str() takes at most 3 arguments (%v given)", len(objs[1:])))
+                case len(objs[1:]) > 1:
+                    // str(b, encoding, errors) decodes bytes
+                    for i, param := range []string{"encoding", "errors"}[:len(objs[2:])] {
+                        strArg(objs[2+i], "str() argument '" + param + "' must be str, not %v")
+                    }
+                    b, ok := objs[1].(*BytesInst)
+                    if !ok {
+                        if _, ok := objs[1].(*StringInst); ok {
+                            panic(newError(Py_TypeError, "decoding str is not supported"))
+                        }
+                        panic(newError(Py_TypeError, "decoding to str: need a bytes-like object, %v found", typeName(objs[1])))
+                    }
+                    inst = op_CALL(op_GETATTR(b, newStringInst("decode")), objs[2:]...).(*StringInst)
+                case len(objs[1:]) == 1:
+                    inst = newStringInst(StringOf(objs[1]).(*StringInst).Value)
+                default:
                     inst = newStringInst("")
-                } else {
-                    inst = typeCall(__str__, objs[1]).(*StringInst)
                 }
 
                 inst.class = objs[0].(Class)
@@ -967,49 +2173,30 @@ func init() {
 
     Py_str.attrs().set(__repr__, newBuiltinFunc(__repr__,
             func(objs ...Object) Object {
-                return newStringInst("'" + objs[0].(*StringInst).Value + "'")
+                return newStringInst(strRepr(objs[0].(*StringInst).Value))
             },
         ),
     )
 
     Py_str.attrs().set(__str__, newBuiltinFunc(__str__,
             func(objs ...Object) Object {
-                return objs[0]
-            },
-        ),
-    )
-
-    Py_str.attrs().set(__gt__, newBuiltinFunc(__gt__,
-            func(objs ...Object) Object {
-                s1 := objs[0].(*StringInst)
-                s2 := objs[1].(*StringInst)
-
-                if s1.Value > s2.Value {
-                    return Py_True
-                } else {
-                    return Py_False
+                self := objs[0].(*StringInst)
+                if self.class != Py_str {
+                    return newStringInst(self.Value)
                 }
+                return self
             },
         ),
     )
 
-    Py_str.attrs().set(__lt__, newBuiltinFunc(__lt__,
-            func(objs ...Object) Object {
-                s1 := objs[0].(*StringInst)
-                s2 := objs[1].(*StringInst)
-
-                if s1.Value < s2.Value {
-                    return Py_True
-                } else {
-                    return Py_False
-                }
-            },
-        ),
-    )
+    Py_str.attrs().set(__gt__, newStrCompare(__gt__, func(c int) bool { return c > 0 }))
+    Py_str.attrs().set(__lt__, newStrCompare(__lt__, func(c int) bool { return c < 0 }))
+    Py_str.attrs().set(__ge__, newStrCompare(__ge__, func(c int) bool { return c >= 0 }))
+    Py_str.attrs().set(__le__, newStrCompare(__le__, func(c int) bool { return c <= 0 }))
 
     Py_str.attrs().set(__len__, newBuiltinFunc(__len__,
             func(objs ...Object) Object {
-                return newIntegerInst(int64(len(objs[0].(*StringInst).Value)))
+                return newIntegerInst(int64(objs[0].(*StringInst).length()))
             },
         ),
     )
@@ -1017,16 +2204,11 @@ func init() {
     Py_str.attrs().set(__contains__, newBuiltinFunc(__contains__,
             func(objs ...Object) Object {
                 self := objs[0].(*StringInst)
-                item := objs[1]
-
-                for _, ch := range self.Value {
-                    chStr := newStringInst(string(ch))
-                    if op_EQ(chStr, item) == Py_True {
-                        return Py_True
-                    }
+                item, ok := objs[1].(*StringInst)
+                if !ok {
+                    panic(newError(Py_TypeError, "'in <string>' requires string as left operand, not %v", typeName(objs[1])))
                 }
-
-                return Py_False
+                return newBoolInst(strings.Contains(self.Value, item.Value))
             },
         ),
     )
@@ -1042,30 +2224,76 @@ func init() {
     Py_str.attrs().set(__getitem__, newBuiltinFunc(__getitem__,
             func(objs ...Object) Object {
                 self := objs[0].(*StringInst)
-                idx := objs[1].(*IntegerInst)
-                return newStringInst(string(self.Value[idx.Value]))
+                switch key := objs[1].(type) {
+                case *IntegerInst:
+                    return newStringInst(self.at(seqIndex(key, self.length(), "string index out of range")))
+                case *SliceInst:
+                    start, _, step, n := key.indices(self.length())
+                    runes := self.codePoints()
+                    if runes == nil {
+                        b := make([]byte, n)
+                        for i := range b {
+                            b[i] = self.Value[start+i*step]
+                        }
+                        return newStringInst(string(b))
+                    }
+                    rs := make([]rune, n)
+                    for i := range rs {
+                        rs[i] = runes[start+i*step]
+                    }
+                    return newStringInst(string(rs))
+                }
+                panic(newError(Py_TypeError, "string indices must be integers, not '%v'", typeName(objs[1])))
             },
         ),
     )
 
     Py_str.attrs().set(__add__, newBuiltinFunc(__add__,
             func(objs ...Object) Object {
-                if objs[0].otype() != objs[1].otype() {
-                    panic(Error("otypeError: two different types"))
+                other, ok := objs[1].(*StringInst)
+                if !ok {
+                    panic(newError(Py_TypeError, "can only concatenate str (not \"%v\") to str", typeName(objs[1])))
                 }
 
-                self, other := objs[0].(*StringInst), objs[1].(*StringInst)
-                return newStringInst(self.Value + other.Value)
-            },
-        ),
+                self := objs[0].(*StringInst)
+                return newStringInst(self.Value + other.Value)
+            },
+        ),
+    )
+
+    Py_str.attrs().set(__mul__, Pystr__mul__)
+    Py_str.attrs().set(__rmul__, Pystr__mul__)
+
+}
+
+// newStrCompare makes a comparison method of str, which tells by
+// the sign of strings.Compare
+func newStrCompare(name *StringInst, cmp func(int) bool) *BuiltinFunctionInst {
+    return newBuiltinFunc(name,
+        func(objs ...Object) Object {
+            s1 := objs[0].(*StringInst)
+            s2, ok := objs[1].(*StringInst)
+            if !ok {
+                return Py_NotImplemented
+            }
+
+            if cmp(strings.Compare(s1.Value, s2.Value)) {
+                return Py_True
+            }
+            return Py_False
+        },
     )
-
 }
 
+// StringInst is a sequence of code points, kept as UTF-8 in Value.
+// Those of a str not all ASCII are decoded into runes the first time
+// they're needed, so that indexing doesn't have to decode it again
 type StringInst struct {
     *objectData
     Value   string
     class   Class
+    decoded bool
+    runes   []rune  // nil if Value is all ASCII
 }
 
 func newStringInst(s string) *StringInst {
@@ -1082,6 +2310,60 @@ func (s *StringInst) otype() Class { return s.class }
 func (s *StringInst) id() int64 { return int64(uintptr(unsafe.Pointer(s))) }
 func (s *StringInst) String() string { return s.Value }
 
+// codePoints gives the runes of s, or nil if it's all ASCII,
+// when the bytes of Value are its code points already
+func (s *StringInst) codePoints() []rune {
+    if !s.decoded {
+        for i := 0; i < len(s.Value); i++ {
+            if s.Value[i] >= utf8.RuneSelf {
+                s.runes = []rune(s.Value)
+                break
+            }
+        }
+        s.decoded = true
+    }
+    return s.runes
+}
+
+// length is the number of code points of s
+func (s *StringInst) length() int {
+    if runes := s.codePoints(); runes != nil {
+        return len(runes)
+    }
+    return len(s.Value)
+}
+
+// at gives the code point i of s as a str
+func (s *StringInst) at(i int) string {
+    if runes := s.codePoints(); runes != nil {
+        return string(runes[i])
+    }
+    return s.Value[i:i+1]
+}
+
+// byteOffset gives where in Value the code point i of s starts,
+// which is len(Value) for i at the end
+func (s *StringInst) byteOffset(i int) int {
+    runes := s.codePoints()
+    if runes == nil {
+        return i
+    }
+    offset := 0
+    for _, r := range runes[:i] {
+        offset += utf8.RuneLen(r)
+    }
+    return offset
+}
+
+// charIndex is the reverse of byteOffset, the index of
+// the code point starting at offset in Value
+func (s *StringInst) charIndex(offset int) int {
+    if s.codePoints() == nil {
+        return offset
+    }
+    return utf8.RuneCountInString(s.Value[:offset])
+}
+
 type Pybool struct {
     *objectData
 }
@@ -1104,11 +2386,21 @@ func init() {
     Py_bool.attrs().set(__name__, newStringInst("bool"))
     Py_bool.attrs().set(__new__, newBuiltinFunc(__new__,
             func(objs ...Object) Object {
+                if len(objs) > 2 {
+                    panic(newError(Py_TypeError, "bool expected at most 1 argument, got %v", len(objs)-1))
+                }
+                if len(objs) == 1 {
+                    return Py_False
+                }
+
                 if boolFn := attrItself(objs[1].otype(), __bool__); boolFn != nil {
-                    return op_CALL(boolFn, objs[1])
-                } else if lenFn := attrItself(objs[1].otype(), __len__); lenFn != nil {
-                    l := op_CALL(lenFn, objs[1]).(*IntegerInst)
-                    if l.Value != 0 {
+                    rv := op_CALL(boolFn, objs[1])
+                    if rv != Py_True && rv != Py_False {
+                        panic(newError(Py_TypeError, "__bool__ should return bool, returned %v", typeName(rv)))
+                    }
+                    return rv
+                } else if attrItself(objs[1].otype(), __len__) != nil {
+                    if op_CALL(Py_len, objs[1]).(*IntegerInst).Value != 0 {
                         return Py_True
                     } else {
                         return Py_False
@@ -1119,7 +2411,14 @@ func init() {
         ),
     )
 
-    Py_bool.attrs().set(__str__, newBuiltinFunc(__str__,
+    Py_bool.attrs().set(__and__, newBoolBitwise(__and__, __and__, func(a, b bool) bool { return a && b }))
+    Py_bool.attrs().set(__rand__, newBoolBitwise(__rand__, __and__, func(a, b bool) bool { return a && b }))
+    Py_bool.attrs().set(__or__, newBoolBitwise(__or__, __or__, func(a, b bool) bool { return a || b }))
+    Py_bool.attrs().set(__ror__, newBoolBitwise(__ror__, __or__, func(a, b bool) bool { return a || b }))
+    Py_bool.attrs().set(__xor__, newBoolBitwise(__xor__, __xor__, func(a, b bool) bool { return a != b }))
+    Py_bool.attrs().set(__rxor__, newBoolBitwise(__rxor__, __xor__, func(a, b bool) bool { return a != b }))
+
+    Py_bool.attrs().set(__repr__, newBuiltinFunc(__repr__,
             func(objs ...Object) Object {
                 o := objs[0].(*IntegerInst)
                 if o.Value == 1 {
@@ -1132,6 +2431,23 @@ func init() {
     )
 }
 
+// newBoolBitwise makes a bitwise method of bool, which gives a bool
+// when both are bools, and otherwise does as int's method intName
+func newBoolBitwise(name, intName *StringInst, fn func(bool, bool) bool) *BuiltinFunctionInst {
+    return newBuiltinFunc(name,
+        func(objs ...Object) Object {
+            self, other := objs[0].(*IntegerInst), objs[1]
+            if other.otype() != Py_bool {
+                return op_CALL(attrItself(Py_int, intName), self, other)
+            }
+            if fn(self == Py_True, other == Py_True) {
+                return Py_True
+            }
+            return Py_False
+        },
+    )
+}
+
 var Py_True = &IntegerInst{
     objectData: &objectData{
         d: newDictInst(),
@@ -1148,6 +2464,14 @@ var Py_False = &IntegerInst{
     Value: 0,
 }
 
+// newBoolInst gives True or False, which are the only bools there are
+func newBoolInst(b bool) *IntegerInst {
+    if b {
+        return Py_True
+    }
+    return Py_False
+}
+
 
 type Pylist_iterator struct {
     *objectData
@@ -1175,12 +2499,14 @@ func (pli *Pylist_iterator) init() {
 
     pli.attrs().set(__next__, newBuiltinFunc(__next__,
             func(objs ...Object) Object {
+                // the items themselves, whatever a subclass makes
+                // of __len__ and __getitem__
                 self := objs[0].(*ListIteratorInst)
-                if self.idx >= op_CALL(Py_len, self.listInst).(*IntegerInst).Value {
+                if self.idx >= int64(len(self.listInst.items)) {
                     panic(op_CALL(Py_StopIteration))
                 }
                 self.idx += 1
-                return op_SUBSCR_GET(self.listInst, newIntegerInst(self.idx-1))
+                return self.listInst.items[self.idx-1]
             },
         ),
     )
@@ -1230,6 +2556,38 @@ var Py_list = newPylist()
 func init() {
     Py_list.attrs().set(__name__, newStringInst("list"))
 
+    // the items are filled in by __init__, so that a subclass
+    // may take other arguments
+    Py_list.attrs().set(__new__, newBuiltinKwFunc(__new__,
+            func(objs []Object, kwargs *DictInst) Object {
+                li := newListInst()
+                li.class = objs[0].(Class)
+                return li
+            },
+        ),
+    )
+
+    Py_list.attrs().set(__init__, newBuiltinKwFunc(__init__,
+            func(objs []Object, kwargs *DictInst) Object {
+                if len(objs) > 2 {
+                    panic(newError(Py_TypeError, "list expected at most 1 argument, got %v", len(objs)-1))
+                }
+                if kwargs != nil && kwargs.len() > 0 {
+                    panic(newError(Py_TypeError, "list() takes no keyword arguments"))
+                }
+
+                self := objs[0].(*ListInst)
+                self.items = []Object{}
+                if len(objs) == 2 {
+                    self.items = append(self.items, iterItems(objs[1])...)
+                }
+                return Py_None
+            },
+        ),
+    )
+
+    Py_list.attrs().set(__hash__, Py_None)
+
     Py_list.attrs().set(__len__, newBuiltinFunc(__len__,
             func(objs ...Object) Object {
                 return newIntegerInst(int64(len(objs[0].(*ListInst).items)))
@@ -1239,13 +2597,17 @@ func init() {
 
     Py_list.attrs().set(__eq__, newBuiltinFunc(__eq__,
             func(objs ...Object) Object {
-                self, oli := objs[0].(*ListInst), objs[1].(*ListInst)
+                self := objs[0].(*ListInst)
+                oli, ok := objs[1].(*ListInst)
+                if !ok {
+                    return Py_NotImplemented
+                }
                 if len(self.items) != len(oli.items) {
                     return Py_False
                 }
 
                 for i := range self.items {
-                    if op_EQ(self.items[i], self.items[i]) == Py_False {
+                    if op_EQ(self.items[i], oli.items[i]) == Py_False {
                         return Py_False
                     }
                 }
@@ -1255,17 +2617,22 @@ func init() {
         ),
     )
 
-    Py_list.attrs().set(__str__, newBuiltinFunc(__str__,
+    Py_list.attrs().set(__lt__, newItemsCompare(__lt__, op_LT))
+    Py_list.attrs().set(__gt__, newItemsCompare(__gt__, op_GT))
+    Py_list.attrs().set(__le__, newItemsCompare(__le__, op_LE))
+    Py_list.attrs().set(__ge__, newItemsCompare(__ge__, op_GE))
+
+    Py_list.attrs().set(__repr__, newBuiltinFunc(__repr__,
             func(objs ...Object) Object {
                 li := objs[0].(*ListInst)
 
                 var s string
                 s += "["
-                if len(li.items) > 0 {
-                    s += fmt.Sprintf("%v", op_CALL(Py_str, li.items[0]))
-                }
-                for _, item := range li.items[1:] {
-                    s += fmt.Sprintf(", %v", op_CALL(Py_str, item))
+                for i, item := range li.items {
+                    if i > 0 {
+                        s += ", "
+                    }
+                    s += ReprOf(item).(*StringInst).Value
                 }
                 s += "]"
 
@@ -1277,30 +2644,90 @@ func init() {
     Py_list.attrs().set(__getitem__, newBuiltinFunc(__getitem__,
             func(objs ...Object) Object {
                 self := objs[0].(*ListInst)
-                idx := objs[1].(*IntegerInst)
-                return self.items[idx.Value]
+                switch key := objs[1].(type) {
+                case *IntegerInst:
+                    return self.items[seqIndex(key, len(self.items), "list index out of range")]
+                case *SliceInst:
+                    li := newListInst()
+                    li.items = sliceItems(self.items, key)
+                    return li
+                }
+                panic(listIndexError(objs[1]))
             },
         ),
     )
 
     Py_list.attrs().set(__setitem__, newBuiltinFunc(__setitem__,
             func(objs ...Object) Object {
-                self, key, value := objs[0].(*ListInst), objs[1].(*IntegerInst), objs[2]
-                self.items[key.Value] = value
+                self, value := objs[0].(*ListInst), objs[2]
+                switch key := objs[1].(type) {
+                case *IntegerInst:
+                    self.items[seqIndex(key, len(self.items), "list assignment index out of range")] = value
+                case *SliceInst:
+                    // take the values before changing anything,
+                    // for they may come from the list itself
+                    values := iterItems(value)
+                    start, stop, step, n := key.indices(len(self.items))
+                    if step == 1 {
+                        if stop < start {
+                            stop = start
+                        }
+                        items := make([]Object, 0, len(self.items)-(stop-start)+len(values))
+                        items = append(items, self.items[:start]...)
+                        items = append(items, values...)
+                        self.items = append(items, self.items[stop:]...)
+                    } else {
+                        if len(values) != n {
+                            panic(newError(Py_ValueError, "attempt to assign sequence of size %v to extended slice of size %v", len(values), n))
+                        }
+                        for i, v := range values {
+                            self.items[start+i*step] = v
+                        }
+                    }
+                default:
+                    panic(listIndexError(objs[1]))
+                }
                 return Py_None
             },
         ),
     )
 
-    Py_list.attrs().set(__contains__, newBuiltinFunc(__contains__,
+    Py_list.attrs().set(__delitem__, newBuiltinFunc(__delitem__,
             func(objs ...Object) Object {
                 self := objs[0].(*ListInst)
-                item := objs[1]
-                for val := op_CALL(Py_next, self); val != nil; {
-                    if op_EQ(val, item) == Py_True {
+                switch key := objs[1].(type) {
+                case *IntegerInst:
+                    idx := seqIndex(key, len(self.items), "list assignment index out of range")
+                    self.items = append(self.items[:idx:idx], self.items[idx+1:]...)
+                case *SliceInst:
+                    start, _, step, n := key.indices(len(self.items))
+                    deleted := make(map[int]bool, n)
+                    for i := 0; i < n; i++ {
+                        deleted[start+i*step] = true
+                    }
+
+                    items := make([]Object, 0, len(self.items)-n)
+                    for i, item := range self.items {
+                        if !deleted[i] {
+                            items = append(items, item)
+                        }
+                    }
+                    self.items = items
+                default:
+                    panic(listIndexError(objs[1]))
+                }
+                return Py_None
+            },
+        ),
+    )
+
+    Py_list.attrs().set(__contains__, newBuiltinFunc(__contains__,
+            func(objs ...Object) Object {
+                self, item := objs[0].(*ListInst), objs[1]
+                for _, val := range self.items {
+                    if op_IS(val, item) == Py_True || op_EQ(val, item) == Py_True {
                         return Py_True
                     }
-                    val = op_CALL(Py_next, self)
                 }
                 return Py_False
             },
@@ -1317,16 +2744,35 @@ func init() {
 
     Py_list.attrs().set(__add__, newBuiltinFunc(__add__,
             func(objs ...Object) Object {
-                self, other := objs[0].(*ListInst), objs[1].(*ListInst)
+                self := objs[0].(*ListInst)
+                other, ok := objs[1].(*ListInst)
+                if !ok {
+                    panic(newError(Py_TypeError, "can only concatenate list (not \"%v\") to list", typeName(objs[1])))
+                }
                 li := newListInst()
-                li.items = append(self.items, other.items...)
+                li.items = append(li.items, self.items...)
+                li.items = append(li.items, other.items...)
                 return li
             },
         ),
     )
 
+    // unlike +, += extends the list itself, and by any iterable
+    Py_list.attrs().set(__iadd__, newBuiltinFunc(__iadd__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 1)
+                self := objs[0].(*ListInst)
+                self.items = append(self.items, iterItems(objs[1])...)
+                return self
+            },
+        ),
+    )
+
     Py_list.attrs().set(newStringInst("append"), newBuiltinFunc(newStringInst("append"),
             func(objs ...Object) Object {
+                if len(objs) != 2 {
+                    panic(newError(Py_TypeError, "list.append() takes exactly one argument (%v given)", len(objs)-1))
+                }
                 self, item := objs[0].(*ListInst), objs[1]
                 self.items = append(self.items, item)
                 return Py_None
@@ -1334,11 +2780,37 @@ func init() {
         ),
     )
 
+    Py_list.attrs().set(newStringInst("sort"), newBuiltinKwFunc(newStringInst("sort"),
+            func(objs []Object, kwargs *DictInst) Object {
+                if len(objs) > 1 {
+                    panic(newError(Py_TypeError, "sort() takes no positional arguments"))
+                }
+                opts := builtinKwargs("sort", kwargs, "key", "reverse")
+                sortItems(objs[0].(*ListInst).items, opts[0], opts[1])
+                return Py_None
+            },
+        ),
+    )
+
     Py_list.attrs().set(newStringInst("pop"), newBuiltinFunc(newStringInst("pop"),
             func(objs ...Object) Object {
+                if len(objs) > 2 {
+                    panic(newError(Py_TypeError, "pop expected at most 1 argument, got %v", len(objs)-1))
+                }
                 self := objs[0].(*ListInst)
-                res := self.items[len(self.items)-1]
-                self.items = self.items[:len(self.items)-1]
+                if len(self.items) == 0 {
+                    panic(newError(Py_IndexError, "pop from empty list"))
+                }
+                i := len(self.items)-1
+                if len(objs) == 2 {
+                    idx, ok := objs[1].(*IntegerInst)
+                    if !ok {
+                        panic(newError(Py_TypeError, "'%v' object cannot be interpreted as an integer", typeName(objs[1])))
+                    }
+                    i = seqIndex(idx, len(self.items), "pop index out of range")
+                }
+                res := self.items[i]
+                self.items = append(self.items[:i], self.items[i+1:]...)
                 return res
             },
         ),
@@ -1346,9 +2818,14 @@ func init() {
 
 }
 
+func listIndexError(key Object) *ExceptionInst {
+    return newError(Py_TypeError, "list indices must be integers or slices, not %v", typeName(key))
+}
+
 type ListInst struct {
     *objectData
     items []Object
+    class   Class
 }
 
 func newListInst() *ListInst {
@@ -1357,10 +2834,11 @@ func newListInst() *ListInst {
             d: newDictInst(),
         },
         items: []Object{},
+        class: Py_list,
     }
 }
 
-func (l *ListInst) otype() Class { return Py_list }
+func (l *ListInst) otype() Class { return l.class }
 func (l *ListInst) id() int64 { return int64(uintptr(unsafe.Pointer(l))) }
 
 type Pydict_keyiterator struct {
@@ -1453,7 +2931,7 @@ var Py_dict = newPydict()
 func init() {
     Py_dict.attrs().set(__name__, newStringInst("dict"))
 
-    Py_dict.attrs().set(__str__, newBuiltinFunc(__str__,
+    Py_dict.attrs().set(__repr__, newBuiltinFunc(__repr__,
             func (objs ...Object) Object {
                 d := objs[0].(*DictInst)
 
@@ -1461,10 +2939,10 @@ func init() {
                 s += "{"
                 for _, pairs := range d.store {
                     for _, pair := range pairs {
-                        s += fmt.Sprintf("%v: %v, ",
-                            op_CALL(Py_str, pair.Key),
-                            op_CALL(Py_str, pair.Value),
-                            )
+                        if len(s) > 1 {
+                            s += ", "
+                        }
+                        s += ReprOf(pair.Key).(*StringInst).Value + ": " + ReprOf(pair.Value).(*StringInst).Value
                     }
                 }
                 s += "}"
@@ -1474,48 +2952,124 @@ func init() {
         ),
     )
 
-    Py_dict.attrs().set(__iter__, newBuiltinFunc(__iter__,
-            func (objs ...Object) Object {
+    Py_dict.attrs().set(__new__, newBuiltinKwFunc(__new__,
+            func (objs []Object, kwargs *DictInst) Object {
+                d := newDictInst()
+                d.class = objs[0].(Class)
+                return d
+            },
+        ),
+    )
+
+    // dict(mapping or iterable of pairs, **kwargs)
+    Py_dict.attrs().set(__init__, newBuiltinKwFunc(__init__,
+            func (objs []Object, kwargs *DictInst) Object {
+                if len(objs) > 2 {
+                    panic(newError(Py_TypeError, "dict expected at most 1 argument, got %v", len(objs)-1))
+                }
+
                 self := objs[0].(*DictInst)
-                return newDictKeyiteratorInst(self)
+                if len(objs) == 2 {
+                    if other, ok := objs[1].(*DictInst); ok {
+                        for _, pairs := range other.store {
+                            for _, pair := range pairs {
+                                self.insert(pair.Key, pair.Value)
+                            }
+                        }
+                    } else {
+                        for i, item := range iterItems(objs[1]) {
+                            kv := iterItems(item)
+                            if len(kv) != 2 {
+                                panic(newError(Py_ValueError, "dictionary update sequence element #%v has length %v; 2 is required", i, len(kv)))
+                            }
+                            self.insert(kv[0], kv[1])
+                        }
+                    }
+                }
+
+                if kwargs != nil {
+                    for _, pairs := range kwargs.store {
+                        for _, pair := range pairs {
+                            self.insert(pair.Key, pair.Value)
+                        }
+                    }
+                }
+                return Py_None
             },
         ),
     )
 
-    Py_dict.attrs().set(__getitem__, newBuiltinFunc(__getitem__,
+    Py_dict.attrs().set(__hash__, Py_None)
+
+    Py_dict.attrs().set(__eq__, newBuiltinFunc(__eq__,
             func (objs ...Object) Object {
-                self, key := objs[0].(*DictInst), objs[1]
-                hashVal := op_CALL(Py_hash, key)
-                if pairs, ok := self.store[hashVal.(*IntegerInst).Value]; ok {
+                self := objs[0].(*DictInst)
+                other, ok := objs[1].(*DictInst)
+                if !ok {
+                    return Py_NotImplemented
+                }
+                if self.len() != other.len() {
+                    return Py_False
+                }
+                for _, pairs := range self.store {
                     for _, pair := range pairs {
-                        if op_EQ(pair.Key, key) == Py_True {
-                            return pair.Value
+                        p := other.lookup(pair.Key)
+                        if p == nil || op_CALL(Py_bool, op_EQ(pair.Value, p.Value)) != Py_True {
+                            return Py_False
                         }
                     }
                 }
+                return Py_True
+            },
+        ),
+    )
 
-                return nil
+    Py_dict.attrs().set(__delitem__, newBuiltinFunc(__delitem__,
+            func (objs ...Object) Object {
+                self, key := objs[0].(*DictInst), objs[1]
+                if !self.remove(key) {
+                    panic(op_CALL(Py_KeyError, key))
+                }
+                return Py_None
             },
         ),
     )
 
-    Py_dict.attrs().set(__setitem__, newBuiltinFunc(__getitem__,
+    Py_dict.attrs().set(__iter__, newBuiltinFunc(__iter__,
             func (objs ...Object) Object {
-                self, key, val := objs[0].(*DictInst), objs[1], objs[2]
-                hashVal := op_CALL(Py_hash, key)
+                self := objs[0].(*DictInst)
+                return newDictKeyiteratorInst(self)
+            },
+        ),
+    )
 
-                var flag bool = false
-                for _, pair := range self.store[hashVal.(*IntegerInst).Value] {
-                    if op_EQ(pair.Key, key) == Py_True {
-                        pair.Value = val
-                        flag = true
-                    }
+    Py_dict.attrs().set(__len__, newBuiltinFunc(__len__,
+            func (objs ...Object) Object {
+                return newIntegerInst(int64(objs[0].(*DictInst).len()))
+            },
+        ),
+    )
+
+    Py_dict.attrs().set(__getitem__, newBuiltinFunc(__getitem__,
+            func (objs ...Object) Object {
+                self, key := objs[0].(*DictInst), objs[1]
+                if pair := self.lookup(key); pair != nil {
+                    return pair.Value
                 }
 
-                if !flag {
-                    self.store[hashVal.(*IntegerInst).Value] = append(self.store[hashVal.(*IntegerInst).Value], &pair{Key: key, Value: val})
+                // a subclass may give the value of a missing key
+                if missing := attrItself(self.otype(), __missing__); missing != nil {
+                    return op_CALL(missing, self, key)
                 }
+                panic(op_CALL(Py_KeyError, key))
+            },
+        ),
+    )
 
+    Py_dict.attrs().set(__setitem__, newBuiltinFunc(__setitem__,
+            func (objs ...Object) Object {
+                self, key, val := objs[0].(*DictInst), objs[1], objs[2]
+                self.insert(key, val)
                 return Py_None
             },
         ),
@@ -1524,11 +3078,8 @@ func init() {
     Py_dict.attrs().set(__contains__, newBuiltinFunc(__contains__,
             func (objs ...Object) Object {
                 self, item := objs[0].(*DictInst), objs[1]
-                for val := op_CALL(Py_next, self); val != nil; {
-                    if op_EQ(val, item) == Py_True {
-                        return Py_True
-                    }
-                    val = op_CALL(Py_next, self)
+                if self.lookup(item) != nil {
+                    return Py_True
                 }
                 return Py_False
             },
@@ -1545,6 +3096,7 @@ type pair struct {
 type DictInst struct {
     d *DictInst
     store map[int64][]*pair
+    class   Class   // nil for dict, which every class is built upon
 }
 
 func newDictInst() *DictInst {
@@ -1558,14 +3110,22 @@ func newDictInst() *DictInst {
 }
 
 func (d *DictInst) attrs() *DictInst { return d.d }
-func (d *DictInst) otype() Class { return Py_dict }
+func (d *DictInst) otype() Class {
+    if d.class == nil {
+        return Py_dict
+    }
+    return d.class
+}
 func (d *DictInst) id() int64 { return int64(uintptr(unsafe.Pointer(d))) }
 
+// get and set serve the attribute dicts that builtin classes are
+// built with at package initialization, so they go to the go functions
+// of str's __hash__ and __eq__ straight instead of a python call
 func (d *DictInst) get(key *StringInst) Object {
-    hashVal := Pystr__hash__.call(key)
+    hashVal := Pystr__hash__.gfunc(key)
     if pairs, ok := d.store[hashVal.(*IntegerInst).Value]; ok {
         for _, pair := range pairs {
-            if Pystr__eq__.call(key, pair.Key) == Py_True {
+            if Pystr__eq__.gfunc(key, pair.Key) == Py_True {
                 return pair.Value
             }
         }
@@ -1574,12 +3134,45 @@ func (d *DictInst) get(key *StringInst) Object {
     return nil
 }
 
+// delete removes key, telling if it was there
+func (d *DictInst) delete(key *StringInst) bool {
+    hashVal := Pystr__hash__.gfunc(key).(*IntegerInst).Value
+    for i, pair := range d.store[hashVal] {
+        if Pystr__eq__.gfunc(key, pair.Key) == Py_True {
+            d.dropPair(hashVal, i)
+            return true
+        }
+    }
+    return false
+}
+
+// dropPair takes the i-th pair out of the bucket of hashVal, not
+// leaving it behind in the array for the collector to keep
+func (d *DictInst) dropPair(hashVal int64, i int) {
+    pairs := d.store[hashVal]
+    copy(pairs[i:], pairs[i+1:])
+    pairs[len(pairs)-1] = nil
+    if len(pairs) == 1 {
+        delete(d.store, hashVal)
+    } else {
+        d.store[hashVal] = pairs[:len(pairs)-1]
+    }
+}
+
+func (d *DictInst) len() int {
+    n := 0
+    for _, pairs := range d.store {
+        n += len(pairs)
+    }
+    return n
+}
+
 func (d *DictInst) set(key *StringInst, val Object) {
-    hashVal := Pystr__hash__.call(key)
+    hashVal := Pystr__hash__.gfunc(key)
 
     var flag bool = false
     for _, pair := range d.store[hashVal.(*IntegerInst).Value] {
-        if Pystr__eq__.call(key, pair.Key) == Py_True {
+        if Pystr__eq__.gfunc(key, pair.Key) == Py_True {
             pair.Value = val
             flag = true
         }
@@ -1590,6 +3183,41 @@ func (d *DictInst) set(key *StringInst, val Object) {
     }
 }
 
+// lookup finds the pair of key, which can be any hashable object,
+// it's nil if there is none
+func (d *DictInst) lookup(key Object) *pair {
+    hashVal := op_CALL(Py_hash, key).(*IntegerInst).Value
+    for _, pair := range d.store[hashVal] {
+        if op_IS(pair.Key, key) == Py_True || op_EQ(pair.Key, key) == Py_True {
+            return pair
+        }
+    }
+    return nil
+}
+
+// remove removes key, which can be any hashable object,
+// telling if it was there
+func (d *DictInst) remove(key Object) bool {
+    hashVal := op_CALL(Py_hash, key).(*IntegerInst).Value
+    for i, pair := range d.store[hashVal] {
+        if op_IS(pair.Key, key) == Py_True || op_EQ(pair.Key, key) == Py_True {
+            d.dropPair(hashVal, i)
+            return true
+        }
+    }
+    return false
+}
+
+func (d *DictInst) insert(key Object, val Object) {
+    if pair := d.lookup(key); pair != nil {
+        pair.Value = val
+        return
+    }
+
+    hashVal := op_CALL(Py_hash, key).(*IntegerInst).Value
+    d.store[hashVal] = append(d.store[hashVal], &pair{Key: key, Value: val})
+}
+
 type Pyrange_iterator struct {
     *objectData
 }
@@ -1672,27 +3300,25 @@ func (pr *Pyrange) init() {
 
     pr.attrs().set(__new__, newBuiltinFunc(__new__,
             func (objs ...Object) Object {
-                if len(objs) == 2 {
-                    return newRangeInst(
-                        0,
-                        objs[1].(*IntegerInst).Value,
-                        1,
-                        )
-                } else if len(objs) == 3 {
-                    return newRangeInst(
-                        objs[1].(*IntegerInst).Value,
-                        objs[2].(*IntegerInst).Value,
-                        1,
-                        )
-                } else if len(objs) == 4 {
-                    return newRangeInst(
-                        objs[1].(*IntegerInst).Value,
-                        objs[2].(*IntegerInst).Value,
-                        objs[3].(*IntegerInst).Value,
-                        )
+                args := objs[1:]
+                if len(args) == 0 {
+                    panic(newError(Py_TypeError, "range expected at least 1 argument, got 0"))
+                }
+                if len(args) > 3 {
+                    panic(newError(Py_TypeError, "range expected at most 3 arguments, got %v", len(args)))
                 }
 
-                return nil
+                bounds := []int64{0, 0, 1}
+                for i, arg := range args {
+                    bounds[i] = int64(asInt(arg))
+                }
+                if len(args) == 1 {
+                    bounds[0], bounds[1] = 0, bounds[0]
+                }
+                if bounds[2] == 0 {
+                    panic(newError(Py_ValueError, "range() arg 3 must not be zero"))
+                }
+                return newRangeInst(bounds[0], bounds[1], bounds[2])
             },
         ),
     )
@@ -1732,76 +3358,6 @@ func (ri *RangeInst) otype() Class { return Py_range }
 func (ri *RangeInst) id() int64 { return int64(uintptr(unsafe.Pointer(ri))) }
 
 
-type PyException struct {
-    *objectData
-}
-
-func (pe *PyException) otype() Class { return Py_type }
-func (pe *PyException) id() int64 { return int64(uintptr(unsafe.Pointer(pe))) }
-func (pe *PyException) cbase() Class { return Py_object }
-
-var Py_Exception = &PyException{
-    &objectData{
-        d: newDictInst(),
-    },
-}
-func init() {
-    Py_Exception.attrs().set(__name__, newStringInst("Exception"))
-    Py_Exception.attrs().set(__new__, newBuiltinFunc(__new__,
-            func (objs ...Object) Object {
-                var inst *ExceptionInst
-                if len(objs) == 1 {
-                    inst = newExceptionInst(newStringInst(""))
-                } else {
-                    inst = newExceptionInst(objs[1])
-                }
-                inst.class = objs[0].(Class)
-                return inst
-            },
-        ),
-    )
-}
-
-type PyStopIteration struct {
-    *objectData
-}
-
-func (psi *PyStopIteration) otype() Class { return Py_type }
-func (psi *PyStopIteration) cbase() Class { return Py_Exception }
-func (psi *PyStopIteration) id() int64 { return int64(uintptr(unsafe.Pointer(psi))) }
-
-var Py_StopIteration = &PyStopIteration{
-    &objectData{
-        d: newDictInst(),
-    },
-}
-func init() {
-    Py_StopIteration.attrs().set(__name__, newStringInst("StopIteration"))
-}
-
-type ExceptionInst struct {
-    *objectData
-    class        Class
-    Payload     Object
-}
-
-func newExceptionInst(obj Object) *ExceptionInst {
-    return &ExceptionInst{
-        objectData: &objectData{
-            d: newDictInst(),
-        },
-        class: Py_Exception,
-        Payload: obj,
-    }
-}
-
-func Error(s string) *ExceptionInst {
-    return newExceptionInst(newStringInst(s))
-}
-
-func (e *ExceptionInst) otype() Class { return e.class }
-func (e *ExceptionInst) id() int64 { return int64(uintptr(unsafe.Pointer(e))) }
-
 func hash(bv []byte) int64 {
     // sha1 just fine
     bs := sha1.Sum(bv)
@@ -1813,15 +3369,14 @@ func hash(bv []byte) int64 {
 }
 
 func Getattr(obj Object, name *StringInst) Object {
-    __getattribute__ := attrFromAll(obj.otype(), __getattribute__).(Function)
-    return op_CALL(__getattribute__, obj, name)
+    return op_GETATTR(obj, name)
 }
 
 func attrItself(obj Object, name *StringInst) Object {
     switch obj.(type) {
     case Class:
         cls := obj.(Class)
-        for c := cls; c != nil; c = c.cbase() {
+        for _, c := range mroOf(cls) {
             if rv := c.attrs().get(name); rv != nil {
                 return rv
             }
@@ -1835,19 +3390,42 @@ func attrItself(obj Object, name *StringInst) Object {
     return nil
 }
 
+// attrFromAll looks name up on obj the way object.__getattribute__ does.
+// A data descriptor of the type comes first, then the attribute of obj
+// itself, then that of the type, where a function is bound to obj as a
+// method and a descriptor gives what its __get__ does
 func attrFromAll(obj Object, name *StringInst) Object {
+    typ := obj.otype()
+    typeAttr := attrItself(typ, name)
+    if typeAttr != nil && isDataDescriptor(typeAttr) {
+        return op_CALL(attrItself(typeAttr.otype(), __get__), typeAttr, obj, typ)
+    }
+
     if rv := attrItself(obj, name); rv != nil {
+        // what's found on a class is got with no instance
+        if _, ok := obj.(Class); ok {
+            if get := attrItself(rv.otype(), __get__); get != nil {
+                return op_CALL(get, rv, Py_None, obj)
+            }
+        }
         return rv
     }
 
-    if rv := attrItself(obj.otype(), name); rv != nil {
-        switch v := rv.(type) {
-        case Function:
-            return newMethod(obj, v)
-        default:
-            return rv
+    if typeAttr != nil {
+        if f, ok := typeAttr.(Function); ok {
+            return newMethod(obj, f)
+        }
+        if get := attrItself(typeAttr.otype(), __get__); get != nil {
+            return op_CALL(get, typeAttr, obj, typ)
         }
+        return typeAttr
     }
 
     return nil
 }
+
+// isDataDescriptor tells if attr defines how it's assigned or deleted,
+// which makes it take precedence over the attributes of instances
+func isDataDescriptor(attr Object) bool {
+    return attrItself(attr.otype(), __set__) != nil || attrItself(attr.otype(), __delete__) != nil
+}
@@ -0,0 +1,271 @@
+package evaluator
+
+import (
+    "math"
+    "math/big"
+    "strings"
+    "unicode/utf8"
+)
+
+/*
+    The printf-style formatting of str, like '%-5s|%03d' % ('a', 7).
+    The values are a tuple, one value on its own, or a mapping whose
+    items are asked for by key, like '%(name)s' % {'name': 'x'}.
+*/
+
+func init() {
+    Py_str.attrs().set(__mod__, newBuiltinFunc(__mod__,
+            func(objs ...Object) Object {
+                return newStringInst(percentFormat(objs[0].(*StringInst).Value, objs[1]))
+            },
+        ),
+    )
+}
+
+// percentFormat gives format % values
+func percentFormat(format string, values Object) string {
+    args := []Object{values}
+    var mapping Object
+    switch v := values.(type) {
+    case *TupleInst:
+        args = v.items
+    case *StringInst:
+    default:
+        if attrItself(values.otype(), __getitem__) != nil {
+            mapping = values
+        }
+    }
+
+    argIdx := 0
+    nextArg := func() Object {
+        if argIdx >= len(args) {
+            panic(newError(Py_TypeError, "not enough arguments for format string"))
+        }
+        argIdx++
+        return args[argIdx-1]
+    }
+
+    var res strings.Builder
+    for i := 0; i < len(format); i++ {
+        if format[i] != '%' {
+            res.WriteByte(format[i])
+            continue
+        }
+        i++
+
+        var arg Object
+        if i < len(format) && format[i] == '(' {
+            if mapping == nil {
+                panic(newError(Py_TypeError, "format requires a mapping"))
+            }
+            end, depth := i+1, 1
+            for ; end < len(format) && depth > 0; end++ {
+                if format[end] == '(' {
+                    depth++
+                } else if format[end] == ')' {
+                    depth--
+                }
+            }
+            if depth > 0 {
+                panic(newError(Py_ValueError, "incomplete format key"))
+            }
+            arg = op_SUBSCR_GET(mapping, newStringInst(format[i+1:end-1]))
+            // what's asked for by key leaves nothing to take in order
+            args, argIdx = nil, 0
+            i = end
+        }
+
+        var left, zero, alternate bool
+        var sign byte
+        for ; i < len(format) && strings.IndexByte("-+ #0", format[i]) >= 0; i++ {
+            switch format[i] {
+            case '-':
+                left = true
+            case '0':
+                zero = true
+            case '#':
+                alternate = true
+            case '+':
+                sign = '+'
+            case ' ':
+                if sign == 0 {
+                    sign = ' '
+                }
+            }
+        }
+
+        readNumber := func() int {
+            if i < len(format) && format[i] == '*' {
+                i++
+                n, ok := nextArg().(*IntegerInst)
+                if !ok {
+                    panic(newError(Py_TypeError, "* wants int"))
+                }
+                return asInt(n)
+            }
+            n := 0
+            for ; i < len(format) && '0' <= format[i] && format[i] <= '9'; i++ {
+                n = n*10 + int(format[i]-'0')
+                if n > math.MaxInt32 {
+                    panic(newError(Py_ValueError, "width too big"))
+                }
+            }
+            return n
+        }
+        width := readNumber()
+        if width < 0 {
+            left, width = true, -width
+        }
+        precision := -1
+        if i < len(format) && format[i] == '.' {
+            i++
+            if precision = readNumber(); precision < 0 {
+                precision = 0
+            }
+        }
+        for i < len(format) && (format[i] == 'h' || format[i] == 'l' || format[i] == 'L') {
+            i++
+        }
+        if i >= len(format) {
+            panic(newError(Py_ValueError, "incomplete format"))
+        }
+
+        conv := format[i]
+        if conv == '%' {
+            res.WriteByte('%')
+            continue
+        }
+        if arg == nil {
+            arg = nextArg()
+        }
+
+        prefix, body := "", ""
+        numeric := true
+        switch conv {
+        case 's', 'r', 'a':
+            numeric = false
+            switch conv {
+            case 's':
+                body = StringOf(arg).(*StringInst).Value
+            case 'r':
+                body = ReprOf(arg).(*StringInst).Value
+            default:
+                body = op_CALL(Py_ascii, arg).(*StringInst).Value
+            }
+            if precision >= 0 && utf8.RuneCountInString(body) > precision {
+                body = string([]rune(body)[:precision])
+            }
+        case 'c':
+            numeric = false
+            switch arg := arg.(type) {
+            case *IntegerInst:
+                if arg.big != nil || arg.Value < 0 || arg.Value > utf8.MaxRune {
+                    panic(newError(Py_OverflowError, "%%c arg not in range(0x110000)"))
+                }
+                body = string(rune(arg.Value))
+            case *StringInst:
+                if arg.length() != 1 {
+                    panic(newError(Py_TypeError, "%%c requires int or char"))
+                }
+                body = arg.Value
+            default:
+                panic(newError(Py_TypeError, "%%c requires int or char"))
+            }
+        case 'd', 'i', 'u', 'o', 'x', 'X':
+            var n *big.Int
+            switch arg := arg.(type) {
+            case *IntegerInst:
+                n = arg.bigValue()
+            case *FloatInst:
+                if conv == 'd' || conv == 'i' || conv == 'u' {
+                    if math.IsInf(arg.Value, 0) || math.IsNaN(arg.Value) {
+                        panic(newError(Py_ValueError, "cannot convert float %v to integer", formatFloat(arg.Value)))
+                    }
+                    n, _ = big.NewFloat(math.Trunc(arg.Value)).Int(nil)
+                    break
+                }
+                panic(newError(Py_TypeError, "%%%c format: an integer is required, not %v", conv, typeName(arg)))
+            default:
+                if conv == 'd' || conv == 'i' || conv == 'u' {
+                    panic(newError(Py_TypeError, "%%%c format: a real number is required, not %v", conv, typeName(arg)))
+                }
+                panic(newError(Py_TypeError, "%%%c format: an integer is required, not %v", conv, typeName(arg)))
+            }
+
+            base := map[byte]int{'o': 8, 'x': 16, 'X': 16}[conv]
+            if base == 0 {
+                base = 10
+            }
+            body = new(big.Int).Abs(n).Text(base)
+            if conv == 'X' {
+                body = strings.ToUpper(body)
+            }
+            if len(body) < precision {
+                body = strings.Repeat("0", precision-len(body)) + body
+            }
+            if alternate && base != 10 {
+                prefix = "0" + string(conv)
+            }
+            prefix = signPrefix(n.Sign() < 0, sign) + prefix
+        case 'e', 'E', 'f', 'F', 'g', 'G':
+            var v float64
+            switch arg := arg.(type) {
+            case *FloatInst:
+                v = arg.Value
+            case *IntegerInst:
+                v, _ = new(big.Float).SetInt(arg.bigValue()).Float64()
+            default:
+                panic(newError(Py_TypeError, "must be real number, not %v", typeName(arg)))
+            }
+
+            abs := math.Abs(v)
+            switch {
+            case math.IsInf(abs, 0):
+                body = "inf"
+            case math.IsNaN(abs):
+                body = "nan"
+            default:
+                if precision < 0 {
+                    precision = 6
+                }
+                body = floatDigits(abs, conv, precision, alternate)
+            }
+            if conv == 'E' || conv == 'F' || conv == 'G' {
+                body = strings.ToUpper(body)
+            }
+            prefix = signPrefix(math.Signbit(v) && !math.IsNaN(v), sign)
+        default:
+            r, _ := utf8.DecodeRuneInString(format[i:])
+            panic(newError(Py_ValueError, "unsupported format character '%c' (0x%x) at index %v", r, r, i))
+        }
+
+        n := width - utf8.RuneCountInString(prefix) - utf8.RuneCountInString(body)
+        switch {
+        case n <= 0:
+            res.WriteString(prefix + body)
+        case left:
+            res.WriteString(prefix + body + strings.Repeat(" ", n))
+        case zero && numeric:
+            res.WriteString(prefix + strings.Repeat("0", n) + body)
+        default:
+            res.WriteString(strings.Repeat(" ", n) + prefix + body)
+        }
+    }
+
+    if mapping == nil && argIdx < len(args) {
+        panic(newError(Py_TypeError, "not all arguments converted during string formatting"))
+    }
+    return res.String()
+}
+
+// signPrefix gives the sign of a number, as the flag asks for it
+// if it's not negative
+func signPrefix(negative bool, flag byte) string {
+    if negative {
+        return "-"
+    }
+    if flag != 0 {
+        return string(flag)
+    }
+    return ""
+}
@@ -0,0 +1,173 @@
+package evaluator
+
+import (
+    "unsafe"
+)
+
+/*
+    A property is a data descriptor calling its fget, fset and fdel
+    functions to get, set and delete the attribute of an instance.
+    Its getter, setter and deleter make a copy of it with one of them
+    replaced, which is what `@x.setter` on a method of the same name
+    is for.
+*/
+
+type Pyproperty struct {
+    *objectData
+}
+
+func newPyproperty() *Pyproperty {
+    return &Pyproperty{
+        objectData: &objectData{
+            d: newDictInst(),
+        },
+    }
+}
+
+func (pp *Pyproperty) otype() Class { return Py_type }
+func (pp *Pyproperty) cbase() Class { return Py_object }
+func (pp *Pyproperty) id() int64 { return int64(uintptr(unsafe.Pointer(pp))) }
+
+var Py_property = newPyproperty()
+func init() {
+    Py_property.attrs().set(__name__, newStringInst("property"))
+
+    Py_property.attrs().set(__new__, newBuiltinKwFunc(__new__,
+            func(objs []Object, kwargs *DictInst) Object {
+                if len(objs) > 5 {
+                    panic(newError(Py_TypeError, "property() takes at most 4 arguments (%v given)", len(objs)-1))
+                }
+
+                funcs := builtinKwargs("property", kwargs, "fget", "fset", "fdel", "doc")
+                for i, obj := range objs[1:] {
+                    if funcs[i] != nil {
+                        panic(newError(Py_TypeError, "argument for property() given by name ('%v') and position (%v)",
+                            []string{"fget", "fset", "fdel", "doc"}[i], i+1))
+                    }
+                    funcs[i] = obj
+                }
+                return newPropertyInst(funcs[0], funcs[1], funcs[2], funcs[3])
+            },
+        ),
+    )
+
+    Py_property.attrs().set(__get__, newBuiltinFunc(__get__,
+            func(objs ...Object) Object {
+                self, obj := objs[0].(*PropertyInst), objs[1]
+                if obj == Py_None {
+                    return self
+                }
+                if self.fget == Py_None {
+                    panic(self.missing(obj, "getter"))
+                }
+                return op_CALL(self.fget, obj)
+            },
+        ),
+    )
+
+    Py_property.attrs().set(__set__, newBuiltinFunc(__set__,
+            func(objs ...Object) Object {
+                self, obj := objs[0].(*PropertyInst), objs[1]
+                if self.fset == Py_None {
+                    panic(self.missing(obj, "setter"))
+                }
+                op_CALL(self.fset, obj, objs[2])
+                return Py_None
+            },
+        ),
+    )
+
+    Py_property.attrs().set(__delete__, newBuiltinFunc(__delete__,
+            func(objs ...Object) Object {
+                self, obj := objs[0].(*PropertyInst), objs[1]
+                if self.fdel == Py_None {
+                    panic(self.missing(obj, "deleter"))
+                }
+                op_CALL(self.fdel, obj)
+                return Py_None
+            },
+        ),
+    )
+
+    Py_property.attrs().set(__set_name__, newBuiltinFunc(__set_name__,
+            func(objs ...Object) Object {
+                objs[0].(*PropertyInst).name = objs[2].(*StringInst)
+                return Py_None
+            },
+        ),
+    )
+
+    Py_property.attrs().set(newStringInst("getter"), newBuiltinFunc(newStringInst("getter"),
+            func(objs ...Object) Object {
+                self := objs[0].(*PropertyInst)
+                return self.copy(objs[1], self.fset, self.fdel)
+            },
+        ),
+    )
+
+    Py_property.attrs().set(newStringInst("setter"), newBuiltinFunc(newStringInst("setter"),
+            func(objs ...Object) Object {
+                self := objs[0].(*PropertyInst)
+                return self.copy(self.fget, objs[1], self.fdel)
+            },
+        ),
+    )
+
+    Py_property.attrs().set(newStringInst("deleter"), newBuiltinFunc(newStringInst("deleter"),
+            func(objs ...Object) Object {
+                self := objs[0].(*PropertyInst)
+                return self.copy(self.fget, self.fset, objs[1])
+            },
+        ),
+    )
+}
+
+type PropertyInst struct {
+    *objectData
+    fget    Object
+    fset    Object
+    fdel    Object
+    name    *StringInst     // the attribute it's assigned to in a class body
+}
+
+func newPropertyInst(fget, fset, fdel, doc Object) *PropertyInst {
+    p := &PropertyInst{
+        objectData: &objectData{
+            d: newDictInst(),
+        },
+        fget: orNone(fget),
+        fset: orNone(fset),
+        fdel: orNone(fdel),
+    }
+
+    p.attrs().set(newStringInst("fget"), p.fget)
+    p.attrs().set(newStringInst("fset"), p.fset)
+    p.attrs().set(newStringInst("fdel"), p.fdel)
+    p.attrs().set(newStringInst("__doc__"), orNone(doc))
+
+    return p
+}
+
+func (p *PropertyInst) otype() Class { return Py_property }
+func (p *PropertyInst) id() int64 { return int64(uintptr(unsafe.Pointer(p))) }
+
+func (p *PropertyInst) copy(fget, fset, fdel Object) *PropertyInst {
+    prop := newPropertyInst(fget, fset, fdel, p.attrs().get(newStringInst("__doc__")))
+    prop.name = p.name
+    return prop
+}
+
+// missing is the error of using a function the property hasn't got
+func (p *PropertyInst) missing(obj Object, function string) *ExceptionInst {
+    if p.name == nil {
+        return newError(Py_AttributeError, "property of '%v' object has no %v", typeName(obj), function)
+    }
+    return newError(Py_AttributeError, "property '%v' of '%v' object has no %v", p.name, typeName(obj), function)
+}
+
+func orNone(obj Object) Object {
+    if obj == nil {
+        return Py_None
+    }
+    return obj
+}
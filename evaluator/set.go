@@ -0,0 +1,513 @@
+package evaluator
+
+import (
+    "unsafe"
+)
+
+/*
+    A set keeps its elements as the keys of a dict, so that they are
+    hashed and compared the same way. Its methods that don't change it
+    are shared with frozenset, and the result of an operation between
+    the two is of the kind of the left one.
+*/
+
+type Pyset struct {
+    *objectData
+}
+
+func newPyset() *Pyset {
+    return &Pyset{
+        objectData: &objectData{
+            d: newDictInst(),
+        },
+    }
+}
+
+func (ps *Pyset) otype() Class { return Py_type }
+func (ps *Pyset) cbase() Class { return Py_object }
+func (ps *Pyset) id() int64 { return int64(uintptr(unsafe.Pointer(ps))) }
+
+var Py_set = newPyset()
+func init() {
+    Py_set.attrs().set(__name__, newStringInst("set"))
+
+    Py_set.attrs().set(__new__, newBuiltinKwFunc(__new__,
+            func(objs []Object, kwargs *DictInst) Object {
+                set := newSetInst()
+                set.class = objs[0].(Class)
+                return set
+            },
+        ),
+    )
+
+    Py_set.attrs().set(__init__, newBuiltinKwFunc(__init__,
+            func(objs []Object, kwargs *DictInst) Object {
+                if len(objs) > 2 {
+                    panic(newError(Py_TypeError, "set expected at most 1 argument, got %v", len(objs)-1))
+                }
+                if kwargs != nil && kwargs.len() > 0 {
+                    panic(newError(Py_TypeError, "set() takes no keyword arguments"))
+                }
+
+                self := objs[0].(*SetInst)
+                self.items = newDictInst()
+                if len(objs) == 2 {
+                    self.update(objs[1])
+                }
+                return Py_None
+            },
+        ),
+    )
+
+    Py_set.attrs().set(__hash__, Py_None)
+
+    initSetMethods(Py_set)
+
+    Py_set.attrs().set(newStringInst("add"), newBuiltinFunc(newStringInst("add"),
+            func(objs ...Object) Object {
+                checkSetArgs("set.add", objs, 1)
+                objs[0].(*SetInst).add(objs[1])
+                return Py_None
+            },
+        ),
+    )
+
+    Py_set.attrs().set(newStringInst("discard"), newBuiltinFunc(newStringInst("discard"),
+            func(objs ...Object) Object {
+                checkSetArgs("set.discard", objs, 1)
+                objs[0].(*SetInst).items.remove(objs[1])
+                return Py_None
+            },
+        ),
+    )
+
+    Py_set.attrs().set(newStringInst("remove"), newBuiltinFunc(newStringInst("remove"),
+            func(objs ...Object) Object {
+                checkSetArgs("set.remove", objs, 1)
+                if !objs[0].(*SetInst).items.remove(objs[1]) {
+                    panic(op_CALL(Py_KeyError, objs[1]))
+                }
+                return Py_None
+            },
+        ),
+    )
+
+    Py_set.attrs().set(newStringInst("pop"), newBuiltinFunc(newStringInst("pop"),
+            func(objs ...Object) Object {
+                checkSetArgs("set.pop", objs, 0)
+                self := objs[0].(*SetInst)
+                items := self.elements()
+                if len(items) == 0 {
+                    panic(op_CALL(Py_KeyError, newStringInst("pop from an empty set")))
+                }
+                self.items.remove(items[0])
+                return items[0]
+            },
+        ),
+    )
+
+    Py_set.attrs().set(newStringInst("clear"), newBuiltinFunc(newStringInst("clear"),
+            func(objs ...Object) Object {
+                checkSetArgs("set.clear", objs, 0)
+                objs[0].(*SetInst).items = newDictInst()
+                return Py_None
+            },
+        ),
+    )
+
+    Py_set.attrs().set(newStringInst("update"), newBuiltinFunc(newStringInst("update"),
+            func(objs ...Object) Object {
+                self := objs[0].(*SetInst)
+                for _, other := range objs[1:] {
+                    self.update(other)
+                }
+                return Py_None
+            },
+        ),
+    )
+
+    // the *_update methods make the set what the operation
+    // without the suffix gives, without making a new one
+    for _, name := range []string{"intersection", "difference", "symmetric_difference"} {
+        op := attrItself(Py_set, newStringInst(name))
+        updateName := newStringInst(name + "_update")
+        exactlyOne := name == "symmetric_difference"
+        Py_set.attrs().set(updateName, newBuiltinFunc(updateName,
+                func(objs ...Object) Object {
+                    if exactlyOne {
+                        checkSetArgs("set." + updateName.Value, objs, 1)
+                    }
+                    self := objs[0].(*SetInst)
+                    self.items = op_CALL(op, objs...).(*SetInst).items
+                    return Py_None
+                },
+            ),
+        )
+    }
+
+    // the in-place operators make the set what the operator gives,
+    // and likewise take sets only
+    for _, names := range [][2]*StringInst{{__ior__, __or__}, {__iand__, __and__}, {__isub__, __sub__}, {__ixor__, __xor__}} {
+        op := attrItself(Py_set, names[1])
+        Py_set.attrs().set(names[0], newBuiltinFunc(names[0],
+                func(objs ...Object) Object {
+                    checkSlotArgs(objs, 1)
+                    rv := op_CALL(op, objs...)
+                    if rv == Py_NotImplemented {
+                        return rv
+                    }
+                    self := objs[0].(*SetInst)
+                    self.items = rv.(*SetInst).items
+                    return self
+                },
+            ),
+        )
+    }
+}
+
+// initSetMethods sets the methods that set and frozenset have in common on cls
+func initSetMethods(cls Class) {
+    clsName := cls.attrs().get(__name__).(*StringInst).Value
+
+    cls.attrs().set(__len__, newBuiltinFunc(__len__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 0)
+                return newIntegerInst(int64(objs[0].(*SetInst).items.len()))
+            },
+        ),
+    )
+
+    cls.attrs().set(__contains__, newBuiltinFunc(__contains__,
+            func(objs ...Object) Object {
+                checkSetArgs(clsName + ".__contains__", objs, 1)
+                if objs[0].(*SetInst).contains(objs[1]) {
+                    return Py_True
+                }
+                return Py_False
+            },
+        ),
+    )
+
+    cls.attrs().set(__iter__, newBuiltinFunc(__iter__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 0)
+                li := newListInst()
+                li.items = objs[0].(*SetInst).elements()
+                return newListIteratorInst(li)
+            },
+        ),
+    )
+
+    cls.attrs().set(__repr__, newBuiltinFunc(__repr__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 0)
+                self := objs[0].(*SetInst)
+                name := typeName(self)
+                if self.items.len() == 0 {
+                    return newStringInst(name + "()")
+                }
+
+                s := "{"
+                for i, item := range self.elements() {
+                    if i > 0 {
+                        s += ", "
+                    }
+                    s += ReprOf(item).(*StringInst).Value
+                }
+                s += "}"
+
+                if self.class == Py_set {
+                    return newStringInst(s)
+                }
+                return newStringInst(name + "(" + s + ")")
+            },
+        ),
+    )
+
+    cls.attrs().set(__eq__, newBuiltinFunc(__eq__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 1)
+                self := objs[0].(*SetInst)
+                other, ok := objs[1].(*SetInst)
+                if !ok {
+                    return Py_NotImplemented
+                }
+                return newBoolInst(self.items.len() == other.items.len() && self.isSubset(other))
+            },
+        ),
+    )
+
+    cls.attrs().set(__le__, newBuiltinFunc(__le__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 1)
+                self := objs[0].(*SetInst)
+                other, ok := objs[1].(*SetInst)
+                if !ok {
+                    return Py_NotImplemented
+                }
+                return newBoolInst(self.isSubset(other))
+            },
+        ),
+    )
+
+    cls.attrs().set(__lt__, newBuiltinFunc(__lt__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 1)
+                self := objs[0].(*SetInst)
+                other, ok := objs[1].(*SetInst)
+                if !ok {
+                    return Py_NotImplemented
+                }
+                return newBoolInst(self.items.len() < other.items.len() && self.isSubset(other))
+            },
+        ),
+    )
+
+    cls.attrs().set(__ge__, newBuiltinFunc(__ge__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 1)
+                self := objs[0].(*SetInst)
+                other, ok := objs[1].(*SetInst)
+                if !ok {
+                    return Py_NotImplemented
+                }
+                return newBoolInst(other.isSubset(self))
+            },
+        ),
+    )
+
+    cls.attrs().set(__gt__, newBuiltinFunc(__gt__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 1)
+                self := objs[0].(*SetInst)
+                other, ok := objs[1].(*SetInst)
+                if !ok {
+                    return Py_NotImplemented
+                }
+                return newBoolInst(other.items.len() < self.items.len() && other.isSubset(self))
+            },
+        ),
+    )
+
+    cls.attrs().set(newStringInst("copy"), newBuiltinFunc(newStringInst("copy"),
+            func(objs ...Object) Object {
+                checkSetArgs(clsName + ".copy", objs, 0)
+                self := objs[0].(*SetInst)
+                if self.class == Py_frozenset {
+                    return self
+                }
+                return self.union()
+            },
+        ),
+    )
+
+    cls.attrs().set(newStringInst("union"), newBuiltinFunc(newStringInst("union"),
+            func(objs ...Object) Object {
+                return objs[0].(*SetInst).union(objs[1:]...)
+            },
+        ),
+    )
+
+    cls.attrs().set(newStringInst("intersection"), newBuiltinFunc(newStringInst("intersection"),
+            func(objs ...Object) Object {
+                return objs[0].(*SetInst).intersection(objs[1:]...)
+            },
+        ),
+    )
+
+    cls.attrs().set(newStringInst("difference"), newBuiltinFunc(newStringInst("difference"),
+            func(objs ...Object) Object {
+                return objs[0].(*SetInst).difference(objs[1:]...)
+            },
+        ),
+    )
+
+    cls.attrs().set(newStringInst("symmetric_difference"), newBuiltinFunc(newStringInst("symmetric_difference"),
+            func(objs ...Object) Object {
+                checkSetArgs(clsName + ".symmetric_difference", objs, 1)
+                return objs[0].(*SetInst).symmetricDifference(objs[1])
+            },
+        ),
+    )
+
+    cls.attrs().set(newStringInst("issubset"), newBuiltinFunc(newStringInst("issubset"),
+            func(objs ...Object) Object {
+                checkSetArgs(clsName + ".issubset", objs, 1)
+                return newBoolInst(objs[0].(*SetInst).isSubset(toSet(objs[1])))
+            },
+        ),
+    )
+
+    cls.attrs().set(newStringInst("issuperset"), newBuiltinFunc(newStringInst("issuperset"),
+            func(objs ...Object) Object {
+                checkSetArgs(clsName + ".issuperset", objs, 1)
+                return newBoolInst(toSet(objs[1]).isSubset(objs[0].(*SetInst)))
+            },
+        ),
+    )
+
+    cls.attrs().set(newStringInst("isdisjoint"), newBuiltinFunc(newStringInst("isdisjoint"),
+            func(objs ...Object) Object {
+                checkSetArgs(clsName + ".isdisjoint", objs, 1)
+                self := objs[0].(*SetInst)
+                for _, item := range iterItems(objs[1]) {
+                    if self.contains(item) {
+                        return Py_False
+                    }
+                }
+                return Py_True
+            },
+        ),
+    )
+
+    // the operators take sets only, unlike the methods taking any iterable
+    setOperator := func(name *StringInst, fn func(self *SetInst, other *SetInst) Object) {
+        cls.attrs().set(name, newBuiltinFunc(name,
+                func(objs ...Object) Object {
+                    checkSlotArgs(objs, 1)
+                    other, ok := objs[1].(*SetInst)
+                    if !ok {
+                        return Py_NotImplemented
+                    }
+                    return fn(objs[0].(*SetInst), other)
+                },
+            ),
+        )
+    }
+    setOperator(__or__, func(self *SetInst, other *SetInst) Object { return self.union(other) })
+    setOperator(__and__, func(self *SetInst, other *SetInst) Object { return self.intersection(other) })
+    setOperator(__sub__, func(self *SetInst, other *SetInst) Object { return self.difference(other) })
+    setOperator(__xor__, func(self *SetInst, other *SetInst) Object { return self.symmetricDifference(other) })
+}
+
+// checkSetArgs checks that method got n arguments besides self,
+// n being 0 or 1
+func checkSetArgs(method string, objs []Object, n int) {
+    if len(objs)-1 == n {
+        return
+    }
+    if n == 0 {
+        panic(newError(Py_TypeError, "%v() takes no arguments (%v given)", method, len(objs)-1))
+    }
+    panic(newError(Py_TypeError, "%v() takes exactly one argument (%v given)", method, len(objs)-1))
+}
+
+type SetInst struct {
+    *objectData
+    items   *DictInst
+    class   Class
+}
+
+func newSetInst() *SetInst {
+    return &SetInst{
+        objectData: &objectData{
+            d: newDictInst(),
+        },
+        items: newDictInst(),
+        class: Py_set,
+    }
+}
+
+func (s *SetInst) otype() Class { return s.class }
+func (s *SetInst) id() int64 { return int64(uintptr(unsafe.Pointer(s))) }
+
+func (s *SetInst) add(item Object) {
+    if s.items.lookup(item) == nil {
+        s.items.insert(item, Py_None)
+    }
+}
+
+func (s *SetInst) contains(item Object) bool {
+    return s.items.lookup(item) != nil
+}
+
+// update adds the items of the iterable obj
+func (s *SetInst) update(obj Object) {
+    for _, item := range iterItems(obj) {
+        s.add(item)
+    }
+}
+
+func (s *SetInst) elements() []Object {
+    var items []Object
+    for _, pairs := range s.items.store {
+        for _, pair := range pairs {
+            items = append(items, pair.Key)
+        }
+    }
+    return items
+}
+
+func (s *SetInst) isSubset(other *SetInst) bool {
+    for _, item := range s.elements() {
+        if !other.contains(item) {
+            return false
+        }
+    }
+    return true
+}
+
+// empty makes an empty set of the kind of s, that is a frozenset
+// if s is one, or else a set, whichever subclass s is of
+func (s *SetInst) empty() *SetInst {
+    set := newSetInst()
+    if isSubclass(s.class, Py_frozenset) {
+        set.class = Py_frozenset
+    }
+    return set
+}
+
+func (s *SetInst) union(others ...Object) *SetInst {
+    set := s.empty()
+    set.update(s)
+    for _, other := range others {
+        set.update(other)
+    }
+    return set
+}
+
+func (s *SetInst) intersection(others ...Object) *SetInst {
+    set := s.empty()
+    set.update(s)
+    for _, other := range others {
+        o := toSet(other)
+        for _, item := range set.elements() {
+            if !o.contains(item) {
+                set.items.remove(item)
+            }
+        }
+    }
+    return set
+}
+
+func (s *SetInst) difference(others ...Object) *SetInst {
+    set := s.empty()
+    set.update(s)
+    for _, other := range others {
+        for _, item := range iterItems(other) {
+            set.items.remove(item)
+        }
+    }
+    return set
+}
+
+func (s *SetInst) symmetricDifference(other Object) *SetInst {
+    set := s.empty()
+    set.update(s)
+    for _, item := range toSet(other).elements() {
+        if !set.items.remove(item) {
+            set.add(item)
+        }
+    }
+    return set
+}
+
+// toSet gives obj if it's a set or frozenset, or a set of its items
+func toSet(obj Object) *SetInst {
+    if set, ok := obj.(*SetInst); ok {
+        return set
+    }
+    set := newSetInst()
+    set.update(obj)
+    return set
+}
@@ -0,0 +1,206 @@
+package evaluator
+
+import (
+    "math"
+    "unsafe"
+)
+
+type Pyslice struct {
+    *objectData
+}
+
+func newPyslice() *Pyslice {
+    return &Pyslice{
+        objectData: &objectData{
+            d: newDictInst(),
+        },
+    }
+}
+
+func (ps *Pyslice) otype() Class { return Py_type }
+func (ps *Pyslice) cbase() Class { return Py_object }
+func (ps *Pyslice) id() int64 { return int64(uintptr(unsafe.Pointer(ps))) }
+
+var Py_slice = newPyslice()
+func init() {
+    Py_slice.attrs().set(__name__, newStringInst("slice"))
+
+    Py_slice.attrs().set(__new__, newBuiltinFunc(__new__,
+            func(objs ...Object) Object {
+                args := objs[1:]
+                switch len(args) {
+                case 1:
+                    return newSliceInst(Py_None, args[0], Py_None)
+                case 2:
+                    return newSliceInst(args[0], args[1], Py_None)
+                case 3:
+                    return newSliceInst(args[0], args[1], args[2])
+                }
+                panic(newError(Py_TypeError, "slice expected 1 to 3 arguments, got %v", len(args)))
+            },
+        ),
+    )
+
+    sliceRepr := newBuiltinFunc(__repr__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 0)
+                self := objs[0].(*SliceInst)
+                return newStringInst("slice" + ReprOf(self.tuple()).(*StringInst).Value)
+            },
+        )
+    Py_slice.attrs().set(__repr__, sliceRepr)
+
+    Py_slice.attrs().set(__eq__, newBuiltinFunc(__eq__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 1)
+                self := objs[0].(*SliceInst)
+                other, ok := objs[1].(*SliceInst)
+                if !ok {
+                    return Py_NotImplemented
+                }
+                return op_EQ(self.tuple(), other.tuple())
+            },
+        ),
+    )
+
+    Py_slice.attrs().set(newStringInst("indices"), newBuiltinFunc(newStringInst("indices"),
+            func(objs ...Object) Object {
+                if len(objs) != 2 {
+                    panic(newError(Py_TypeError, "slice.indices() takes exactly one argument (%v given)", len(objs)-1))
+                }
+                self := objs[0].(*SliceInst)
+                length, ok := objs[1].(*IntegerInst)
+                if !ok {
+                    panic(newError(Py_TypeError, "'%v' object cannot be interpreted as an integer", typeName(objs[1])))
+                }
+                if length.Value < 0 {
+                    panic(newError(Py_ValueError, "length should not be negative"))
+                }
+
+                start, stop, step, _ := self.indices(int(length.Value))
+                return newTupleInst([]Object{
+                    newIntegerInst(int64(start)),
+                    newIntegerInst(int64(stop)),
+                    newIntegerInst(int64(step)),
+                })
+            },
+        ),
+    )
+
+}
+
+// seqIndex turns idx into an index of a sequence of length, counting
+// from the end if negative, and raises IndexError with msg if it's
+// out of range
+func seqIndex(idx *IntegerInst, length int, msg string) int {
+    i := idx.Value
+    if i < 0 {
+        i += int64(length)
+    }
+    if i < 0 || i >= int64(length) {
+        panic(newError(Py_IndexError, msg))
+    }
+    return int(i)
+}
+
+// sliceItems gives a copy of what s selects of items
+func sliceItems(items []Object, s *SliceInst) []Object {
+    start, _, step, n := s.indices(len(items))
+    result := make([]Object, 0, n)
+    for i := 0; i < n; i++ {
+        result = append(result, items[start+i*step])
+    }
+    return result
+}
+
+type SliceInst struct {
+    *objectData
+    start   Object
+    stop    Object
+    step    Object
+}
+
+func newSliceInst(start, stop, step Object) *SliceInst {
+    s := &SliceInst{
+        objectData: &objectData{
+            d: newDictInst(),
+        },
+        start: start,
+        stop: stop,
+        step: step,
+    }
+    s.attrs().set(newStringInst("start"), start)
+    s.attrs().set(newStringInst("stop"), stop)
+    s.attrs().set(newStringInst("step"), step)
+    return s
+}
+
+func (s *SliceInst) otype() Class { return Py_slice }
+func (s *SliceInst) id() int64 { return int64(uintptr(unsafe.Pointer(s))) }
+
+func (s *SliceInst) tuple() *TupleInst {
+    return newTupleInst([]Object{s.start, s.stop, s.step})
+}
+
+// indices resolves the slice against a sequence of length the way
+// CPython does: missing bounds default to the ends in the direction of
+// step, negative ones count from the end, and all are clipped to the
+// sequence. n is the number of items selected
+func (s *SliceInst) indices(length int) (start, stop, step, n int) {
+    step = 1
+    if s.step != Py_None {
+        v := sliceBound(s.step)
+        if v == 0 {
+            panic(newError(Py_ValueError, "slice step cannot be zero"))
+        }
+        // keep -step from overflowing
+        if v < -math.MaxInt64 {
+            v = -math.MaxInt64
+        }
+        step = int(v)
+    }
+
+    lower, upper := 0, length
+    if step < 0 {
+        lower, upper = -1, length-1
+    }
+
+    clip := func(obj Object, dflt int) int {
+        if obj == Py_None {
+            return dflt
+        }
+        v := sliceBound(obj)
+        if v < 0 {
+            v += int64(length)
+            if v < int64(lower) {
+                return lower
+            }
+            return int(v)
+        }
+        if v > int64(upper) {
+            return upper
+        }
+        return int(v)
+    }
+
+    if step < 0 {
+        start, stop = clip(s.start, upper), clip(s.stop, lower)
+        if start > stop {
+            n = (start-stop-1)/(-step) + 1
+        }
+    } else {
+        start, stop = clip(s.start, lower), clip(s.stop, upper)
+        if stop > start {
+            n = (stop-start-1)/step + 1
+        }
+    }
+    return start, stop, step, n
+}
+
+func sliceBound(obj Object) int64 {
+    v, ok := obj.(*IntegerInst)
+    if !ok {
+        panic(newError(Py_TypeError, "slice indices must be integers or None or have an __index__ method"))
+    }
+    return v.Value
+}
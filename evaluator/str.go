@@ -0,0 +1,626 @@
+package evaluator
+
+import (
+    "strings"
+    "unicode"
+    "unicode/utf8"
+)
+
+/*
+    The methods of str other than the special ones. They complain about
+    wrong arguments in the words of CPython, which differ from method to
+    method as they come from different ways of parsing the arguments.
+*/
+
+func init() {
+    setStrMethod("split", func(objs []Object, kwargs *DictInst) Object {
+        if len(objs) > 3 {
+            panic(newError(Py_TypeError, "split() takes at most 2 arguments (%v given)", len(objs)-1))
+        }
+        args := builtinKwargs("split", kwargs, "sep", "maxsplit")
+        for i, obj := range objs[1:] {
+            args[i] = obj
+        }
+
+        self := objs[0].(*StringInst).Value
+        maxsplit := -1
+        if args[1] != nil {
+            maxsplit = asInt(args[1])
+        }
+        if args[0] == nil || args[0] == Py_None {
+            return newStrList(splitSpace(self, maxsplit))
+        }
+
+        sep := strArg(args[0], "must be str or None, not %v")
+        if sep == "" {
+            panic(newError(Py_ValueError, "empty separator"))
+        }
+        if maxsplit < 0 {
+            return newStrList(strings.Split(self, sep))
+        }
+        return newStrList(strings.SplitN(self, sep, maxsplit+1))
+    })
+
+    setStrMethod("splitlines", func(objs []Object, kwargs *DictInst) Object {
+        if len(objs) > 2 {
+            panic(newError(Py_TypeError, "splitlines() takes at most 1 argument (%v given)", len(objs)-1))
+        }
+        args := builtinKwargs("splitlines", kwargs, "keepends")
+        if len(objs) == 2 {
+            args[0] = objs[1]
+        }
+        keepends := args[0] != nil && op_CALL(Py_bool, args[0]) == Py_True
+
+        self := objs[0].(*StringInst).Value
+        var lines []string
+        start := 0
+        for i, r := range self {
+            if !isLineBreak(r) {
+                continue
+            }
+            end := i + utf8.RuneLen(r)
+            // \r\n is a single line break
+            if r == '\r' && end < len(self) && self[end] == '\n' {
+                continue
+            }
+            if keepends {
+                lines = append(lines, self[start:end])
+            } else if r == '\n' && i > start && self[i-1] == '\r' {
+                lines = append(lines, self[start:i-1])
+            } else {
+                lines = append(lines, self[start:i])
+            }
+            start = end
+        }
+        if start < len(self) {
+            lines = append(lines, self[start:])
+        }
+        return newStrList(lines)
+    })
+
+    setStrMethod("join", func(objs []Object, kwargs *DictInst) Object {
+        if len(objs) != 2 {
+            panic(newError(Py_TypeError, "str.join() takes exactly one argument (%v given)", len(objs)-1))
+        }
+        if attrItself(objs[1].otype(), __iter__) == nil && attrItself(objs[1].otype(), __getitem__) == nil {
+            panic(newError(Py_TypeError, "can only join an iterable"))
+        }
+
+        var items []string
+        for i, item := range iterItems(objs[1]) {
+            s, ok := item.(*StringInst)
+            if !ok {
+                panic(newError(Py_TypeError, "sequence item %v: expected str instance, %v found", i, typeName(item)))
+            }
+            items = append(items, s.Value)
+        }
+        return newStringInst(strings.Join(items, objs[0].(*StringInst).Value))
+    })
+
+    setStrMethod("strip", newStrStrip("strip", strings.TrimFunc, strings.Trim))
+    setStrMethod("lstrip", newStrStrip("lstrip", strings.TrimLeftFunc, strings.TrimLeft))
+    setStrMethod("rstrip", newStrStrip("rstrip", strings.TrimRightFunc, strings.TrimRight))
+
+    setStrMethod("replace", func(objs []Object, kwargs *DictInst) Object {
+        noKwargs("replace", kwargs)
+        if len(objs) < 3 {
+            panic(newError(Py_TypeError, "replace expected at least 2 arguments, got %v", len(objs)-1))
+        }
+        if len(objs) > 4 {
+            panic(newError(Py_TypeError, "replace expected at most 3 arguments, got %v", len(objs)-1))
+        }
+
+        old := strArg(objs[1], "replace() argument 1 must be str, not %v")
+        new := strArg(objs[2], "replace() argument 2 must be str, not %v")
+        count := -1
+        if len(objs) == 4 {
+            count = asInt(objs[3])
+        }
+        if count < 0 {
+            count = -1
+        }
+        return newStringInst(strings.Replace(objs[0].(*StringInst).Value, old, new, count))
+    })
+
+    setStrMethod("find", func(objs []Object, kwargs *DictInst) Object {
+        return newIntegerInst(int64(strFind("find", objs, kwargs)))
+    })
+
+    setStrMethod("index", func(objs []Object, kwargs *DictInst) Object {
+        i := strFind("index", objs, kwargs)
+        if i < 0 {
+            panic(newError(Py_ValueError, "substring not found"))
+        }
+        return newIntegerInst(int64(i))
+    })
+
+    setStrMethod("count", func(objs []Object, kwargs *DictInst) Object {
+        s, sub, _, ok := strSubArgs("count", objs, kwargs)
+        if !ok {
+            return newIntegerInst(0)
+        }
+        return newIntegerInst(int64(strings.Count(s, sub)))
+    })
+
+    setStrMethod("startswith", newStrAffixCheck("startswith", strings.HasPrefix))
+    setStrMethod("endswith", newStrAffixCheck("endswith", strings.HasSuffix))
+
+    setStrMethod("upper", newStrConvert("upper", func(s string) string {
+        var b strings.Builder
+        for _, r := range s {
+            writeCase(&b, r, specialUpper, unicode.ToUpper)
+        }
+        return b.String()
+    }))
+
+    setStrMethod("lower", newStrConvert("lower", func(s string) string {
+        var b strings.Builder
+        rs := []rune(s)
+        for i := range rs {
+            writeLower(&b, rs, i)
+        }
+        return b.String()
+    }))
+
+    setStrMethod("title", newStrConvert("title", func(s string) string {
+        // a letter is title-cased after an uncased char, and lower-cased after a cased one
+        var b strings.Builder
+        rs := []rune(s)
+        prevCased := false
+        for i, r := range rs {
+            if prevCased {
+                writeLower(&b, rs, i)
+            } else {
+                writeCase(&b, r, specialTitle, unicode.ToTitle)
+            }
+            prevCased = isCased(r)
+        }
+        return b.String()
+    }))
+
+    setStrMethod("capitalize", newStrConvert("capitalize", func(s string) string {
+        var b strings.Builder
+        rs := []rune(s)
+        for i, r := range rs {
+            if i == 0 {
+                writeCase(&b, r, specialTitle, unicode.ToTitle)
+            } else {
+                writeLower(&b, rs, i)
+            }
+        }
+        return b.String()
+    }))
+
+    setStrMethod("isdigit", newStrClassify("isdigit", isDigit))
+    setStrMethod("isalpha", newStrClassify("isalpha", unicode.IsLetter))
+    setStrMethod("isspace", newStrClassify("isspace", isSpace))
+
+    setStrMethod("partition", func(objs []Object, kwargs *DictInst) Object {
+        noKwargs("partition", kwargs)
+        if len(objs) != 2 {
+            panic(newError(Py_TypeError, "str.partition() takes exactly one argument (%v given)", len(objs)-1))
+        }
+
+        self := objs[0].(*StringInst).Value
+        sep := strArg(objs[1], "must be str, not %v")
+        if sep == "" {
+            panic(newError(Py_ValueError, "empty separator"))
+        }
+
+        i := strings.Index(self, sep)
+        if i < 0 {
+            return newTupleInst([]Object{newStringInst(self), newStringInst(""), newStringInst("")})
+        }
+        return newTupleInst([]Object{
+            newStringInst(self[:i]),
+            newStringInst(sep),
+            newStringInst(self[i+len(sep):]),
+        })
+    })
+
+    setStrMethod("zfill", func(objs []Object, kwargs *DictInst) Object {
+        noKwargs("zfill", kwargs)
+        if len(objs) != 2 {
+            panic(newError(Py_TypeError, "str.zfill() takes exactly one argument (%v given)", len(objs)-1))
+        }
+
+        self := objs[0].(*StringInst).Value
+        fill := asInt(objs[1]) - utf8.RuneCountInString(self)
+        if fill <= 0 {
+            return newStringInst(self)
+        }
+
+        // the zeros go after the sign
+        sign := ""
+        if strings.HasPrefix(self, "+") || strings.HasPrefix(self, "-") {
+            sign, self = self[:1], self[1:]
+        }
+        return newStringInst(sign + strings.Repeat("0", fill) + self)
+    })
+
+    setStrMethod("center", newStrPad("center", func(fill, width int) (int, int) {
+        // an odd fill char goes to the left when width is odd, as in CPython
+        left := fill/2 + (fill & width & 1)
+        return left, fill - left
+    }))
+    setStrMethod("ljust", newStrPad("ljust", func(fill, width int) (int, int) { return 0, fill }))
+    setStrMethod("rjust", newStrPad("rjust", func(fill, width int) (int, int) { return fill, 0 }))
+}
+
+func setStrMethod(name string, f builtinKwFn) {
+    Py_str.attrs().set(newStringInst(name), newBuiltinKwFunc(newStringInst(name), f))
+}
+
+func noKwargs(fname string, kwargs *DictInst) {
+    if kwargs != nil && kwargs.len() > 0 {
+        panic(newError(Py_TypeError, "str.%v() takes no keyword arguments", fname))
+    }
+}
+
+// strArg gives the value of obj, which must be a str, or else
+// raises TypeError with msg formatted with the type of obj
+func strArg(obj Object, msg string) string {
+    s, ok := obj.(*StringInst)
+    if !ok {
+        panic(newError(Py_TypeError, msg, typeName(obj)))
+    }
+    return s.Value
+}
+
+// decimalValue gives the value of a decimal digit of any script, like
+// '٣', or -1 if r isn't one. Unicode keeps those digits in runs of
+// ten, from 0 up to 9, so it's how far r is into its run
+func decimalValue(r rune) int {
+    if !unicode.IsDigit(r) {
+        return -1
+    }
+    start := r
+    for unicode.IsDigit(start-1) {
+        start--
+    }
+    return int(r-start) % 10
+}
+
+// asciiDecimals gives s with its decimal digits of other scripts
+// turned into ASCII ones, as int() and float() read them
+func asciiDecimals(s string) string {
+    return strings.Map(func(r rune) rune {
+        if v := decimalValue(r); v >= 0 {
+            return '0' + rune(v)
+        }
+        return r
+    }, s)
+}
+
+// asInt gives the value of obj, which must be an int
+func asInt(obj Object) int {
+    v, ok := obj.(*IntegerInst)
+    if !ok {
+        panic(newError(Py_TypeError, "'%v' object cannot be interpreted as an integer", typeName(obj)))
+    }
+    if v.big != nil {
+        panic(newError(Py_OverflowError, "Python int too large to convert to C ssize_t"))
+    }
+    return int(v.Value)
+}
+
+// strRepr gives s in quotes, with backslashes, the quote, and the
+// chars that aren't printable escaped, as they'd be in a literal
+func strRepr(s string) string {
+    quote := reprQuote(s)
+    var res strings.Builder
+    res.WriteByte(quote)
+    for _, r := range s {
+        switch {
+        case r == rune(quote) || r == '\\':
+            res.WriteByte('\\')
+            res.WriteRune(r)
+        case r == '\t':
+            res.WriteString("\\t")
+        case r == '\n':
+            res.WriteString("\\n")
+        case r == '\r':
+            res.WriteString("\\r")
+        case r == ' ' || unicode.IsPrint(r):
+            res.WriteRune(r)
+        default:
+            res.WriteString(escapeRune(r))
+        }
+    }
+    res.WriteByte(quote)
+    return res.String()
+}
+
+func newStrList(items []string) *ListInst {
+    li := newListInst()
+    for _, item := range items {
+        li.items = append(li.items, newStringInst(item))
+    }
+    return li
+}
+
+// isSpace tells the whitespace of str.isspace(), which has the
+// separators \x1c to \x1f besides what unicode.IsSpace tells
+func isSpace(r rune) bool {
+    return unicode.IsSpace(r) || (r >= 0x1c && r <= 0x1f)
+}
+
+func isLineBreak(r rune) bool {
+    switch r {
+    case '\n', '\r', '\v', '\f', 0x1c, 0x1d, 0x1e, 0x85, 0x2028, 0x2029:
+        return true
+    }
+    return false
+}
+
+func isCased(r rune) bool {
+    return unicode.IsUpper(r) || unicode.IsLower(r) || unicode.IsTitle(r) ||
+        unicode.In(r, unicode.Other_Uppercase, unicode.Other_Lowercase)
+}
+
+// isCaseIgnorable tells the chars that the casing of a word looks
+// past, the marks and modifiers and the few punctuation within words
+func isCaseIgnorable(r rune) bool {
+    switch r {
+    case '\'', '.', ':', 0xB7, 0x387, 0x55F, 0x5F4, 0x2018, 0x2019, 0x2024, 0x2027, 0xFE13, 0xFE52, 0xFE55, 0xFF07, 0xFF0E, 0xFF1A:
+        return true
+    }
+    return unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf, unicode.Lm, unicode.Sk)
+}
+
+// isDigit tells the digits of str.isdigit(), the decimal ones
+// and the others like '²'
+func isDigit(r rune) bool {
+    return unicode.IsDigit(r) || unicode.Is(otherDigits, r)
+}
+
+//go:generate go run gen_unicodetables.go
+
+// writeCase writes the case mapping of r, which is special where it
+// gives more than one char, like 'ß' upper-cased to "SS"
+func writeCase(b *strings.Builder, r rune, special map[rune]string, simple func(rune) rune) {
+    if s, ok := special[r]; ok {
+        b.WriteString(s)
+    } else {
+        b.WriteRune(simple(r))
+    }
+}
+
+// writeLower writes the lower case of rs[i], where a capital sigma
+// ending a word becomes the final form 'ς'
+func writeLower(b *strings.Builder, rs []rune, i int) {
+    if rs[i] != 'Σ' {
+        writeCase(b, rs[i], specialLower, unicode.ToLower)
+        return
+    }
+
+    cased := func(j, step int) bool {
+        for ; j >= 0 && j < len(rs); j += step {
+            if !isCaseIgnorable(rs[j]) {
+                return isCased(rs[j])
+            }
+        }
+        return false
+    }
+    if cased(i-1, -1) && !cased(i+1, 1) {
+        b.WriteRune('ς')
+    } else {
+        b.WriteRune('σ')
+    }
+}
+
+// splitSpace splits s by runs of whitespace, ignoring those at the
+// ends, at most maxsplit times unless it's negative
+func splitSpace(s string, maxsplit int) []string {
+    items := []string{}
+    for {
+        s = strings.TrimLeftFunc(s, isSpace)
+        if s == "" {
+            return items
+        }
+        if maxsplit == len(items) {
+            return append(items, s)
+        }
+
+        i := strings.IndexFunc(s, isSpace)
+        if i < 0 {
+            return append(items, s)
+        }
+        items = append(items, s[:i])
+        s = s[i:]
+    }
+}
+
+func newStrStrip(name string, trimSpace func(string, func(rune) bool) string,
+    trim func(string, string) string) builtinKwFn {
+    return func(objs []Object, kwargs *DictInst) Object {
+        noKwargs(name, kwargs)
+        if len(objs) > 2 {
+            panic(newError(Py_TypeError, "%v expected at most 1 argument, got %v", name, len(objs)-1))
+        }
+
+        self := objs[0].(*StringInst).Value
+        if len(objs) == 1 || objs[1] == Py_None {
+            return newStringInst(trimSpace(self, isSpace))
+        }
+        chars, ok := objs[1].(*StringInst)
+        if !ok {
+            panic(newError(Py_TypeError, "%v arg must be None or str", name))
+        }
+        return newStringInst(trim(self, chars.Value))
+    }
+}
+
+// strSubArgs checks the arguments `sub[, start[, end]]` of the method
+// name, giving sub and the part s of self between start and end, which
+// work like slice indices, with the index where s starts. It's not ok
+// when start is beyond the end
+func strSubArgs(name string, objs []Object, kwargs *DictInst) (s, sub string, start int, ok bool) {
+    noKwargs(name, kwargs)
+    if len(objs) < 2 {
+        panic(newError(Py_TypeError, "%v() takes at least 1 argument (0 given)", name))
+    }
+    if len(objs) > 4 {
+        panic(newError(Py_TypeError, "%v() takes at most 3 arguments (%v given)", name, len(objs)-1))
+    }
+
+    self := objs[0].(*StringInst)
+    sub = strArg(objs[1], "must be str, not %v")
+    s, start, ok = strBetween(self, objs[2:])
+    return s, sub, start, ok
+}
+
+// strBetween gives the part of s between the optional start and end
+// in bounds, and start resolved against the length of s
+func strBetween(s *StringInst, bounds []Object) (string, int, bool) {
+    length := s.length()
+    start, end := 0, length
+    if len(bounds) > 0 && bounds[0] != Py_None {
+        start = clipIndex(sliceBound(bounds[0]), length)
+    }
+    if len(bounds) > 1 && bounds[1] != Py_None {
+        end = clipIndex(sliceBound(bounds[1]), length)
+    }
+    if start > length {
+        return "", 0, false
+    }
+    if end > length {
+        end = length
+    }
+    if end < start {
+        end = start
+    }
+    return s.Value[s.byteOffset(start):s.byteOffset(end)], start, true
+}
+
+// clipIndex counts i from the end of a sequence of length if it's
+// negative, not going below 0, but it may go beyond the end
+func clipIndex(i int64, length int) int {
+    if i < 0 {
+        i += int64(length)
+        if i < 0 {
+            return 0
+        }
+    }
+    if i > int64(length) {
+        return length + 1
+    }
+    return int(i)
+}
+
+// strFind gives the index of the first sub in self[start:end], or -1
+func strFind(name string, objs []Object, kwargs *DictInst) int {
+    s, sub, start, ok := strSubArgs(name, objs, kwargs)
+    if !ok {
+        return -1
+    }
+    i := strings.Index(s, sub)
+    if i < 0 {
+        return -1
+    }
+    return start + utf8.RuneCountInString(s[:i])
+}
+
+func newStrAffixCheck(name string, check func(string, string) bool) builtinKwFn {
+    return func(objs []Object, kwargs *DictInst) Object {
+        noKwargs(name, kwargs)
+        if len(objs) < 2 {
+            panic(newError(Py_TypeError, "%v() takes at least 1 argument (0 given)", name))
+        }
+        if len(objs) > 4 {
+            panic(newError(Py_TypeError, "%v() takes at most 3 arguments (%v given)", name, len(objs)-1))
+        }
+
+        var affixes []Object
+        switch affix := objs[1].(type) {
+        case *StringInst:
+            affixes = []Object{affix}
+        case *TupleInst:
+            affixes = affix.items
+        default:
+            panic(newError(Py_TypeError, "%v first arg must be str or a tuple of str, not %v", name, typeName(objs[1])))
+        }
+
+        s, _, ok := strBetween(objs[0].(*StringInst), objs[2:])
+        if !ok {
+            return Py_False
+        }
+        for _, affix := range affixes {
+            a, ok := affix.(*StringInst)
+            if !ok {
+                panic(newError(Py_TypeError, "tuple for %v must only contain str, not %v", name, typeName(affix)))
+            }
+            if check(s, a.Value) {
+                return Py_True
+            }
+        }
+        return Py_False
+    }
+}
+
+func newStrConvert(name string, convert func(string) string) builtinKwFn {
+    return func(objs []Object, kwargs *DictInst) Object {
+        noKwargs(name, kwargs)
+        if len(objs) != 1 {
+            panic(newError(Py_TypeError, "str.%v() takes no arguments (%v given)", name, len(objs)-1))
+        }
+        return newStringInst(convert(objs[0].(*StringInst).Value))
+    }
+}
+
+// newStrClassify makes a method telling if all the chars of a
+// non-empty str are of a kind
+func newStrClassify(name string, is func(rune) bool) builtinKwFn {
+    return func(objs []Object, kwargs *DictInst) Object {
+        noKwargs(name, kwargs)
+        if len(objs) != 1 {
+            panic(newError(Py_TypeError, "str.%v() takes no arguments (%v given)", name, len(objs)-1))
+        }
+
+        self := objs[0].(*StringInst).Value
+        if self == "" {
+            return Py_False
+        }
+        for _, r := range self {
+            if !is(r) {
+                return Py_False
+            }
+        }
+        return Py_True
+    }
+}
+
+// newStrPad makes a method padding a str to a width with a fill char,
+// split tells how many of the fill chars go to the left and the right
+func newStrPad(name string, split func(fill, width int) (int, int)) builtinKwFn {
+    return func(objs []Object, kwargs *DictInst) Object {
+        noKwargs(name, kwargs)
+        if len(objs) < 2 {
+            panic(newError(Py_TypeError, "%v expected at least 1 argument, got 0", name))
+        }
+        if len(objs) > 3 {
+            panic(newError(Py_TypeError, "%v expected at most 2 arguments, got %v", name, len(objs)-1))
+        }
+
+        self := objs[0].(*StringInst).Value
+        width := asInt(objs[1])
+        fillchar := " "
+        if len(objs) == 3 {
+            fill, ok := objs[2].(*StringInst)
+            if !ok {
+                panic(newError(Py_TypeError, "The fill character must be a unicode character, not %v", typeName(objs[2])))
+            }
+            if utf8.RuneCountInString(fill.Value) != 1 {
+                panic(newError(Py_TypeError, "The fill character must be exactly one character long"))
+            }
+            fillchar = fill.Value
+        }
+
+        fill := width - utf8.RuneCountInString(self)
+        if fill <= 0 {
+            return newStringInst(self)
+        }
+        left, right := split(fill, width)
+        return newStringInst(strings.Repeat(fillchar, left) + self + strings.Repeat(fillchar, right))
+    }
+}
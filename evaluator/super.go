@@ -0,0 +1,163 @@
+package evaluator
+
+import (
+    "fmt"
+    "unsafe"
+)
+
+/*
+    super(cls, obj) looks attributes up in the method resolution order
+    of obj's type, starting from the class next to cls, so that a
+    method can reach the one it overrides without naming where it is.
+
+    The zero-argument super() takes cls from the `__class__` cell, which
+    encloses the functions defined in a class body and is filled with the
+    class once it's made, and obj from the first argument of the method.
+*/
+
+type Pysuper struct {
+    *objectData
+}
+
+func newPysuper() *Pysuper {
+    return &Pysuper{
+        objectData: &objectData{
+            d: newDictInst(),
+        },
+    }
+}
+
+func (ps *Pysuper) otype() Class { return Py_type }
+func (ps *Pysuper) cbase() Class { return Py_object }
+func (ps *Pysuper) id() int64 { return int64(uintptr(unsafe.Pointer(ps))) }
+
+var Py_super = newPysuper()
+func init() {
+    Py_super.attrs().set(__name__, newStringInst("super"))
+
+    Py_super.attrs().set(__new__, newBuiltinFunc(__new__,
+            func(objs ...Object) Object {
+                switch len(objs[1:]) {
+                case 0:
+                    panic(newError(Py_RuntimeError, "super(): no arguments"))
+                case 1:
+                    panic(newError(Py_TypeError, "super() without a second argument is not supported"))
+                case 2:
+                default:
+                    panic(newError(Py_TypeError, "super() takes at most 2 arguments (%v given)", len(objs[1:])))
+                }
+
+                cls, ok := objs[1].(Class)
+                if !ok {
+                    panic(newError(Py_TypeError, "super() argument 1 must be a type, not %v", typeName(objs[1])))
+                }
+                return newSuperInst(cls, objs[2])
+            },
+        ),
+    )
+
+    Py_super.attrs().set(__getattribute__, newBuiltinFunc(__getattribute__,
+            func(objs ...Object) Object {
+                self := objs[0].(*SuperInst)
+                name := objs[1].(*StringInst)
+                if attr := self.lookup(name); attr != nil {
+                    return attr
+                }
+                if attr := attrFromAll(self, name); attr != nil {
+                    return attr
+                }
+                panic(newError(Py_AttributeError, "'super' object has no attribute '%v'", name))
+            },
+        ),
+    )
+
+    Py_super.attrs().set(__repr__, newBuiltinFunc(__repr__,
+            func(objs ...Object) Object {
+                self := objs[0].(*SuperInst)
+                return newStringInst(fmt.Sprintf("<super: %v, <%v object>>",
+                    StringOf(self.cls), attrItself(self.objType, __name__)))
+            },
+        ),
+    )
+}
+
+type SuperInst struct {
+    *objectData
+    cls         Class
+    obj         Object
+    objType     Class   // whose mro is searched, obj itself if it's a class
+}
+
+func newSuperInst(cls Class, obj Object) *SuperInst {
+    var objType Class
+    if c, ok := obj.(Class); ok && isSubclass(c, cls) {
+        objType = c
+    } else if isSubclass(obj.otype(), cls) {
+        objType = obj.otype()
+    } else {
+        panic(newError(Py_TypeError, "super(type, obj): obj must be an instance or subtype of type"))
+    }
+
+    return &SuperInst{
+        objectData: &objectData{
+            d: newDictInst(),
+        },
+        cls: cls,
+        obj: obj,
+        objType: objType,
+    }
+}
+
+func (s *SuperInst) otype() Class { return Py_super }
+func (s *SuperInst) id() int64 { return int64(uintptr(unsafe.Pointer(s))) }
+
+// lookup finds name in the classes after cls in the mro, a function
+// is bound to obj unless super was given a class instead of an instance,
+// and a descriptor gives what its __get__ does
+func (s *SuperInst) lookup(name *StringInst) Object {
+    mro := mroOf(s.objType)
+    i := 0
+    for i < len(mro) && mro[i] != s.cls {
+        i++
+    }
+
+    for _, c := range mro[i+1:] {
+        attr := c.attrs().get(name)
+        if attr == nil {
+            continue
+        }
+        if s.obj == s.objType {
+            if get := attrItself(attr.otype(), __get__); get != nil {
+                return op_CALL(get, attr, Py_None, s.objType)
+            }
+            return attr
+        }
+        if f, ok := attr.(Function); ok {
+            return newMethod(s.obj, f)
+        }
+        if get := attrItself(attr.otype(), __get__); get != nil {
+            return op_CALL(get, attr, s.obj, s.objType)
+        }
+        return attr
+    }
+    return nil
+}
+
+// superArgs gives the arguments of a zero-argument super() called in env
+func superArgs(env *Environment) []Object {
+    f := env.function
+    if f == nil || len(f.Params.params) == 0 {
+        panic(newError(Py_RuntimeError, "super(): no arguments"))
+    }
+
+    obj := env.store.get(f.Params.params[0])
+    if obj == nil {
+        panic(newError(Py_RuntimeError, "super(): arg[0] deleted"))
+    }
+
+    cls := env.Get(__class__)
+    if cls == nil {
+        panic(newError(Py_RuntimeError, "super(): __class__ cell not found"))
+    }
+    return []Object{cls, obj}
+}
@@ -0,0 +1,376 @@
+package evaluator
+
+import (
+    "encoding/binary"
+    "math"
+    "unsafe"
+)
+
+type Pytuple_iterator struct {
+    *objectData
+}
+
+func newPytuple_iterator() *Pytuple_iterator {
+    o := &Pytuple_iterator{
+        objectData: &objectData{
+            d: newDictInst(),
+        },
+    }
+    o.init()
+    return o
+}
+
+func (pti *Pytuple_iterator) init() {
+    pti.attrs().set(__name__, newStringInst("tuple_iterator"))
+
+    pti.attrs().set(__iter__, newBuiltinFunc(__iter__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 0)
+                return objs[0]
+            },
+        ),
+    )
+
+    pti.attrs().set(__next__, newBuiltinFunc(__next__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 0)
+                self := objs[0].(*TupleIteratorInst)
+                if self.idx >= int64(len(self.tupleInst.items)) {
+                    panic(op_CALL(Py_StopIteration))
+                }
+                self.idx += 1
+                return self.tupleInst.items[self.idx-1]
+            },
+        ),
+    )
+
+}
+
+func (pti *Pytuple_iterator) otype() Class { return Py_type }
+func (pti *Pytuple_iterator) cbase() Class { return Py_object }
+func (pti *Pytuple_iterator) id() int64 { return int64(uintptr(unsafe.Pointer(pti))) }
+
+var Py_tuple_iterator = newPytuple_iterator()
+
+type TupleIteratorInst struct {
+    *objectData
+    idx         int64
+    tupleInst   *TupleInst
+}
+
+func newTupleIteratorInst(t *TupleInst) *TupleIteratorInst {
+    return &TupleIteratorInst{
+        objectData: &objectData{d: newDictInst()},
+        idx: 0,
+        tupleInst: t,
+    }
+}
+
+func (tii *TupleIteratorInst) otype() Class { return Py_tuple_iterator }
+func (tii *TupleIteratorInst) id() int64 { return int64(uintptr(unsafe.Pointer(tii))) }
+
+type Pytuple struct {
+    *objectData
+}
+
+func newPytuple() *Pytuple {
+    return &Pytuple{
+        objectData: &objectData{
+            d: newDictInst(),
+        },
+    }
+}
+
+func (pt *Pytuple) otype() Class { return Py_type }
+func (pt *Pytuple) cbase() Class { return Py_object }
+func (pt *Pytuple) id() int64 { return int64(uintptr(unsafe.Pointer(pt))) }
+
+var Py_tuple = newPytuple()
+func init() {
+    Py_tuple.attrs().set(__name__, newStringInst("tuple"))
+
+    Py_tuple.attrs().set(__new__, newBuiltinFunc(__new__,
+            func(objs ...Object) Object {
+                if len(objs) > 2 {
+                    panic(newError(Py_TypeError, "tuple expected at most 1 argument, got %v", len(objs)-1))
+                }
+
+                tuple := newTupleInst(nil)
+                if len(objs) == 2 {
+                    tuple.items = iterItems(objs[1])
+                }
+                tuple.class = objs[0].(Class)
+                return tuple
+            },
+        ),
+    )
+
+    Py_tuple.attrs().set(__hash__, newBuiltinFunc(__hash__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 0)
+                self := objs[0].(*TupleInst)
+
+                // hash the hashes of the items, so that equal
+                // tuples have equal hashes
+                buf := make([]byte, 8*len(self.items))
+                for i, item := range self.items {
+                    h := op_CALL(Py_hash, item).(*IntegerInst).Value
+                    binary.LittleEndian.PutUint64(buf[8*i:], uint64(h))
+                }
+
+                return newIntegerInst(hash(buf))
+            },
+        ),
+    )
+
+    Py_tuple.attrs().set(__len__, newBuiltinFunc(__len__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 0)
+                return newIntegerInst(int64(len(objs[0].(*TupleInst).items)))
+            },
+        ),
+    )
+
+    Py_tuple.attrs().set(__eq__, newBuiltinFunc(__eq__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 1)
+                self := objs[0].(*TupleInst)
+                other, ok := objs[1].(*TupleInst)
+                if !ok {
+                    return Py_NotImplemented
+                }
+                if len(self.items) != len(other.items) {
+                    return Py_False
+                }
+
+                for i := range self.items {
+                    if op_EQ(self.items[i], other.items[i]) == Py_False {
+                        return Py_False
+                    }
+                }
+
+                return Py_True
+            },
+        ),
+    )
+
+    Py_tuple.attrs().set(__lt__, newItemsCompare(__lt__, op_LT))
+    Py_tuple.attrs().set(__gt__, newItemsCompare(__gt__, op_GT))
+    Py_tuple.attrs().set(__le__, newItemsCompare(__le__, op_LE))
+    Py_tuple.attrs().set(__ge__, newItemsCompare(__ge__, op_GE))
+
+    tupleRepr := newBuiltinFunc(__repr__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 0)
+                self := objs[0].(*TupleInst)
+
+                s := "("
+                for i, item := range self.items {
+                    if i > 0 {
+                        s += ", "
+                    }
+                    s += ReprOf(item).(*StringInst).Value
+                }
+                if len(self.items) == 1 {
+                    s += ","
+                }
+                s += ")"
+
+                return newStringInst(s)
+            },
+        )
+    Py_tuple.attrs().set(__repr__, tupleRepr)
+
+    Py_tuple.attrs().set(__getitem__, newBuiltinFunc(__getitem__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 1)
+                self := objs[0].(*TupleInst)
+                switch key := objs[1].(type) {
+                case *IntegerInst:
+                    return self.items[seqIndex(key, len(self.items), "tuple index out of range")]
+                case *SliceInst:
+                    return newTupleInst(sliceItems(self.items, key))
+                }
+                panic(newError(Py_TypeError, "tuple indices must be integers or slices, not %v", typeName(objs[1])))
+            },
+        ),
+    )
+
+    Py_tuple.attrs().set(__contains__, newBuiltinFunc(__contains__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 1)
+                self, item := objs[0].(*TupleInst), objs[1]
+                for _, val := range self.items {
+                    if op_IS(val, item) == Py_True || op_EQ(val, item) == Py_True {
+                        return Py_True
+                    }
+                }
+                return Py_False
+            },
+        ),
+    )
+
+    Py_tuple.attrs().set(__iter__, newBuiltinFunc(__iter__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 0)
+                return newTupleIteratorInst(objs[0].(*TupleInst))
+            },
+        ),
+    )
+
+    Py_tuple.attrs().set(__add__, newBuiltinFunc(__add__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 1)
+                self := objs[0].(*TupleInst)
+                other, ok := objs[1].(*TupleInst)
+                if !ok {
+                    panic(newError(Py_TypeError, "can only concatenate tuple (not \"%v\") to tuple", typeName(objs[1])))
+                }
+
+                items := make([]Object, 0, len(self.items)+len(other.items))
+                items = append(items, self.items...)
+                items = append(items, other.items...)
+                return newTupleInst(items)
+            },
+        ),
+    )
+
+    tupleMul := newBuiltinFunc(__mul__,
+            func(objs ...Object) Object {
+                checkSlotArgs(objs, 1)
+                n, ok := objs[1].(*IntegerInst)
+                if !ok {
+                    return Py_NotImplemented
+                }
+                if n.big != nil {
+                    panic(newError(Py_OverflowError, "cannot fit 'int' into an index-sized integer"))
+                }
+
+                self := objs[0].(*TupleInst)
+                if n.Value <= 0 || len(self.items) == 0 {
+                    return newTupleInst(nil)
+                }
+                if int64(len(self.items)) > math.MaxInt32/n.Value {
+                    panic(newError(Py_OverflowError, "repeated tuple is too long"))
+                }
+                items := make([]Object, 0, len(self.items)*int(n.Value))
+                for i := int64(0); i < n.Value; i++ {
+                    items = append(items, self.items...)
+                }
+                return newTupleInst(items)
+            },
+        )
+    Py_tuple.attrs().set(__mul__, tupleMul)
+    Py_tuple.attrs().set(__rmul__, tupleMul)
+
+    Py_tuple.attrs().set(newStringInst("count"), newBuiltinFunc(newStringInst("count"),
+            func(objs ...Object) Object {
+                if len(objs) != 2 {
+                    panic(newError(Py_TypeError, "tuple.count() takes exactly one argument (%v given)", len(objs)-1))
+                }
+                self, item := objs[0].(*TupleInst), objs[1]
+                var n int64
+                for _, val := range self.items {
+                    if op_EQ(val, item) == Py_True {
+                        n++
+                    }
+                }
+                return newIntegerInst(n)
+            },
+        ),
+    )
+
+    Py_tuple.attrs().set(newStringInst("index"), newBuiltinFunc(newStringInst("index"),
+            func(objs ...Object) Object {
+                if len(objs) < 2 {
+                    panic(newError(Py_TypeError, "index expected at least 1 argument, got 0"))
+                }
+                if len(objs) > 4 {
+                    panic(newError(Py_TypeError, "index expected at most 3 arguments, got %v", len(objs)-1))
+                }
+
+                self, item := objs[0].(*TupleInst), objs[1]
+                // the search may be limited to items[start:stop]
+                bounds := newSliceInst(Py_None, Py_None, Py_None)
+                if len(objs) > 2 {
+                    bounds.start = objs[2]
+                }
+                if len(objs) > 3 {
+                    bounds.stop = objs[3]
+                }
+                start, stop, _, _ := bounds.indices(len(self.items))
+                for i := start; i < stop; i++ {
+                    if op_EQ(self.items[i], item) == Py_True {
+                        return newIntegerInst(int64(i))
+                    }
+                }
+                panic(newError(Py_ValueError, "tuple.index(x): x not in tuple"))
+            },
+        ),
+    )
+
+}
+
+// newItemsCompare makes an ordering method of list or tuple, which
+// compares with the same type only, by compareItems
+func newItemsCompare(name *StringInst, cmp func(Object, Object) Object) *BuiltinFunctionInst {
+    return newBuiltinFunc(name,
+        func(objs ...Object) Object {
+            checkSlotArgs(objs, 1)
+            switch self := objs[0].(type) {
+            case *TupleInst:
+                if other, ok := objs[1].(*TupleInst); ok {
+                    return compareItems(self.items, other.items, cmp)
+                }
+            case *ListInst:
+                if other, ok := objs[1].(*ListInst); ok {
+                    return compareItems(self.items, other.items, cmp)
+                }
+            }
+            return Py_NotImplemented
+        },
+    )
+}
+
+// compareItems compares two sequences lexicographically: the first
+// pair of items that differ decides, otherwise the shorter is less
+func compareItems(items1, items2 []Object, cmp func(Object, Object) Object) Object {
+    for i := 0; i < len(items1) && i < len(items2); i++ {
+        if op_EQ(items1[i], items2[i]) != Py_True {
+            return cmp(items1[i], items2[i])
+        }
+    }
+    return cmp(newIntegerInst(int64(len(items1))), newIntegerInst(int64(len(items2))))
+}
+
+// iterItems exhausts the iterable obj, collecting what it yields
+func iterItems(obj Object) []Object {
+    var items []Object
+    iterator := op_CALL(Py_iter, obj)
+    for val := iterationNext(iterator); val != nil; val = iterationNext(iterator) {
+        items = append(items, val)
+    }
+    return items
+}
+
+type TupleInst struct {
+    *objectData
+    items   []Object
+    class   Class
+}
+
+func newTupleInst(items []Object) *TupleInst {
+    if items == nil {
+        items = []Object{}
+    }
+    return &TupleInst{
+        objectData: &objectData{
+            d: newDictInst(),
+        },
+        items: items,
+        class: Py_tuple,
+    }
+}
+
+func (t *TupleInst) otype() Class { return t.class }
+func (t *TupleInst) id() int64 { return int64(uintptr(unsafe.Pointer(t))) }
@@ -0,0 +1,198 @@
+// Code generated by gen_unicodetables.go from https://www.unicode.org/Public/14.0.0/ucd/; DO NOT EDIT.
+
+package evaluator
+
+import "unicode"
+
+// specialLower is the full lower case mapping where it isn't one to one
+var specialLower = map[rune]string{
+    0x0130: "i\u0307",
+}
+
+// specialTitle is the full title case mapping where it isn't one to one
+var specialTitle = map[rune]string{
+    0x00DF: "Ss",
+    0x0149: "\u02bcN",
+    0x01F0: "J\u030c",
+    0x0390: "\u0399\u0308\u0301",
+    0x03B0: "\u03a5\u0308\u0301",
+    0x0587: "\u0535\u0582",
+    0x1E96: "H\u0331",
+    0x1E97: "T\u0308",
+    0x1E98: "W\u030a",
+    0x1E99: "Y\u030a",
+    0x1E9A: "A\u02be",
+    0x1F50: "\u03a5\u0313",
+    0x1F52: "\u03a5\u0313\u0300",
+    0x1F54: "\u03a5\u0313\u0301",
+    0x1F56: "\u03a5\u0313\u0342",
+    0x1FB2: "\u1fba\u0345",
+    0x1FB4: "\u0386\u0345",
+    0x1FB6: "\u0391\u0342",
+    0x1FB7: "\u0391\u0342\u0345",
+    0x1FC2: "\u1fca\u0345",
+    0x1FC4: "\u0389\u0345",
+    0x1FC6: "\u0397\u0342",
+    0x1FC7: "\u0397\u0342\u0345",
+    0x1FD2: "\u0399\u0308\u0300",
+    0x1FD3: "\u0399\u0308\u0301",
+    0x1FD6: "\u0399\u0342",
+    0x1FD7: "\u0399\u0308\u0342",
+    0x1FE2: "\u03a5\u0308\u0300",
+    0x1FE3: "\u03a5\u0308\u0301",
+    0x1FE4: "\u03a1\u0313",
+    0x1FE6: "\u03a5\u0342",
+    0x1FE7: "\u03a5\u0308\u0342",
+    0x1FF2: "\u1ffa\u0345",
+    0x1FF4: "\u038f\u0345",
+    0x1FF6: "\u03a9\u0342",
+    0x1FF7: "\u03a9\u0342\u0345",
+    0xFB00: "Ff",
+    0xFB01: "Fi",
+    0xFB02: "Fl",
+    0xFB03: "Ffi",
+    0xFB04: "Ffl",
+    0xFB05: "St",
+    0xFB06: "St",
+    0xFB13: "\u0544\u0576",
+    0xFB14: "\u0544\u0565",
+    0xFB15: "\u0544\u056b",
+    0xFB16: "\u054e\u0576",
+    0xFB17: "\u0544\u056d",
+}
+
+// specialUpper is the full upper case mapping where it isn't one to one
+var specialUpper = map[rune]string{
+    0x00DF: "SS",
+    0x0149: "\u02bcN",
+    0x01F0: "J\u030c",
+    0x0390: "\u0399\u0308\u0301",
+    0x03B0: "\u03a5\u0308\u0301",
+    0x0587: "\u0535\u0552",
+    0x1E96: "H\u0331",
+    0x1E97: "T\u0308",
+    0x1E98: "W\u030a",
+    0x1E99: "Y\u030a",
+    0x1E9A: "A\u02be",
+    0x1F50: "\u03a5\u0313",
+    0x1F52: "\u03a5\u0313\u0300",
+    0x1F54: "\u03a5\u0313\u0301",
+    0x1F56: "\u03a5\u0313\u0342",
+    0x1F80: "\u1f08\u0399",
+    0x1F81: "\u1f09\u0399",
+    0x1F82: "\u1f0a\u0399",
+    0x1F83: "\u1f0b\u0399",
+    0x1F84: "\u1f0c\u0399",
+    0x1F85: "\u1f0d\u0399",
+    0x1F86: "\u1f0e\u0399",
+    0x1F87: "\u1f0f\u0399",
+    0x1F88: "\u1f08\u0399",
+    0x1F89: "\u1f09\u0399",
+    0x1F8A: "\u1f0a\u0399",
+    0x1F8B: "\u1f0b\u0399",
+    0x1F8C: "\u1f0c\u0399",
+    0x1F8D: "\u1f0d\u0399",
+    0x1F8E: "\u1f0e\u0399",
+    0x1F8F: "\u1f0f\u0399",
+    0x1F90: "\u1f28\u0399",
+    0x1F91: "\u1f29\u0399",
+    0x1F92: "\u1f2a\u0399",
+    0x1F93: "\u1f2b\u0399",
+    0x1F94: "\u1f2c\u0399",
+    0x1F95: "\u1f2d\u0399",
+    0x1F96: "\u1f2e\u0399",
+    0x1F97: "\u1f2f\u0399",
+    0x1F98: "\u1f28\u0399",
+    0x1F99: "\u1f29\u0399",
+    0x1F9A: "\u1f2a\u0399",
+    0x1F9B: "\u1f2b\u0399",
+    0x1F9C: "\u1f2c\u0399",
+    0x1F9D: "\u1f2d\u0399",
+    0x1F9E: "\u1f2e\u0399",
+    0x1F9F: "\u1f2f\u0399",
+    0x1FA0: "\u1f68\u0399",
+    0x1FA1: "\u1f69\u0399",
+    0x1FA2: "\u1f6a\u0399",
+    0x1FA3: "\u1f6b\u0399",
+    0x1FA4: "\u1f6c\u0399",
+    0x1FA5: "\u1f6d\u0399",
+    0x1FA6: "\u1f6e\u0399",
+    0x1FA7: "\u1f6f\u0399",
+    0x1FA8: "\u1f68\u0399",
+    0x1FA9: "\u1f69\u0399",
+    0x1FAA: "\u1f6a\u0399",
+    0x1FAB: "\u1f6b\u0399",
+    0x1FAC: "\u1f6c\u0399",
+    0x1FAD: "\u1f6d\u0399",
+    0x1FAE: "\u1f6e\u0399",
+    0x1FAF: "\u1f6f\u0399",
+    0x1FB2: "\u1fba\u0399",
+    0x1FB3: "\u0391\u0399",
+    0x1FB4: "\u0386\u0399",
+    0x1FB6: "\u0391\u0342",
+    0x1FB7: "\u0391\u0342\u0399",
+    0x1FBC: "\u0391\u0399",
+    0x1FC2: "\u1fca\u0399",
+    0x1FC3: "\u0397\u0399",
+    0x1FC4: "\u0389\u0399",
+    0x1FC6: "\u0397\u0342",
+    0x1FC7: "\u0397\u0342\u0399",
+    0x1FCC: "\u0397\u0399",
+    0x1FD2: "\u0399\u0308\u0300",
+    0x1FD3: "\u0399\u0308\u0301",
+    0x1FD6: "\u0399\u0342",
+    0x1FD7: "\u0399\u0308\u0342",
+    0x1FE2: "\u03a5\u0308\u0300",
+    0x1FE3: "\u03a5\u0308\u0301",
+    0x1FE4: "\u03a1\u0313",
+    0x1FE6: "\u03a5\u0342",
+    0x1FE7: "\u03a5\u0308\u0342",
+    0x1FF2: "\u1ffa\u0399",
+    0x1FF3: "\u03a9\u0399",
+    0x1FF4: "\u038f\u0399",
+    0x1FF6: "\u03a9\u0342",
+    0x1FF7: "\u03a9\u0342\u0399",
+    0x1FFC: "\u03a9\u0399",
+    0xFB00: "FF",
+    0xFB01: "FI",
+    0xFB02: "FL",
+    0xFB03: "FFI",
+    0xFB04: "FFL",
+    0xFB05: "ST",
+    0xFB06: "ST",
+    0xFB13: "\u0544\u0546",
+    0xFB14: "\u0544\u0535",
+    0xFB15: "\u0544\u053b",
+    0xFB16: "\u054e\u0546",
+    0xFB17: "\u0544\u053d",
+}
+
+// otherDigits are the digits that aren't decimal ones, like '²',
+// those of Numeric_Type=Digit
+var otherDigits = &unicode.RangeTable{
+    R16: []unicode.Range16{
+        {0x00B2, 0x00B3, 1},
+        {0x00B9, 0x00B9, 1},
+        {0x1369, 0x1371, 1},
+        {0x19DA, 0x19DA, 1},
+        {0x2070, 0x2070, 1},
+        {0x2074, 0x2079, 1},
+        {0x2080, 0x2089, 1},
+        {0x2460, 0x2468, 1},
+        {0x2474, 0x247C, 1},
+        {0x2488, 0x2490, 1},
+        {0x24EA, 0x24EA, 1},
+        {0x24F5, 0x24FD, 1},
+        {0x24FF, 0x24FF, 1},
+        {0x2776, 0x277E, 1},
+        {0x2780, 0x2788, 1},
+        {0x278A, 0x2792, 1},
+    },
+    R32: []unicode.Range32{
+        {0x10A40, 0x10A43, 1},
+        {0x10E60, 0x10E68, 1},
+        {0x11052, 0x1105A, 1},
+        {0x1F100, 0x1F10A, 1},
+    },
+    LatinOffset: 2,
+}
@@ -0,0 +1,85 @@
+package lexer
+
+import (
+    "strconv"
+    "strings"
+    "sync"
+)
+
+/*
+    The names of characters for \N{name} escapes, those of the Unicode
+    Character Database and their aliases. They are in unicodenames.go,
+    generated from the database, but for the CJK unified ideographs and
+    the Hangul syllables, whose names are made up of their code points
+    and of their parts, and so are worked out here.
+*/
+
+//go:generate go run gen_unicodenames.go
+
+var (
+    charNamesOnce   sync.Once
+    charNames       map[string]rune
+)
+
+// lookupName gives the character of name, which is case-insensitive
+func lookupName(name string) (rune, bool) {
+    name = strings.ToUpper(name)
+
+    charNamesOnce.Do(func() {
+        charNames = map[string]rune{}
+        for _, line := range strings.Split(strings.Trim(charNameData, "\n"), "\n") {
+            i := strings.IndexByte(line, ';')
+            v, _ := strconv.ParseUint(line[i+1:], 16, 32)
+            charNames[line[:i]] = rune(v)
+        }
+    })
+    if r, ok := charNames[name]; ok {
+        return r, true
+    }
+
+    if code, ok := strings.CutPrefix(name, "CJK UNIFIED IDEOGRAPH-"); ok && (len(code) == 4 || len(code) == 5) {
+        if v, err := strconv.ParseUint(code, 16, 32); err == nil {
+            for _, ideographs := range cjkIdeographs {
+                if ideographs[0] <= rune(v) && rune(v) <= ideographs[1] {
+                    return rune(v), true
+                }
+            }
+        }
+    }
+
+    if syllable, ok := strings.CutPrefix(name, "HANGUL SYLLABLE "); ok {
+        return lookupHangulSyllable(syllable)
+    }
+    return 0, false
+}
+
+// the short names of the jamo that make up a Hangul syllable,
+// its leading consonant, vowel and trailing consonant
+var (
+    hangulLeads     = []string{"G", "GG", "N", "D", "DD", "R", "M", "B", "BB", "S", "SS", "", "J", "JJ", "C", "K", "T", "P", "H"}
+    hangulVowels    = []string{"A", "AE", "YA", "YAE", "EO", "E", "YEO", "YE", "O", "WA", "WAE", "OE", "YO", "U", "WEO", "WE", "WI", "YU", "EU", "YI", "I"}
+    hangulTrails    = []string{"", "G", "GG", "GS", "N", "NJ", "NH", "D", "L", "LG", "LM", "LB", "LS", "LT", "LP", "LH", "M", "B", "BS", "S", "SS", "NG", "J", "C", "K", "T", "P", "H"}
+)
+
+// lookupHangulSyllable gives the syllable spelled by the short names
+// of its jamo, like GAG for U+AC01, taking the longest of each
+func lookupHangulSyllable(s string) (rune, bool) {
+    longest := func(jamo []string) int {
+        index := -1
+        for i, short := range jamo {
+            if strings.HasPrefix(s, short) && (index < 0 || len(short) > len(jamo[index])) {
+                index = i
+            }
+        }
+        if index >= 0 {
+            s = s[len(jamo[index]):]
+        }
+        return index
+    }
+
+    lead, vowel, trail := longest(hangulLeads), longest(hangulVowels), longest(hangulTrails)
+    if vowel < 0 || s != "" {
+        return 0, false
+    }
+    return rune(0xAC00 + (lead*len(hangulVowels) + vowel)*len(hangulTrails) + trail), true
+}
@@ -0,0 +1,149 @@
+package lexer
+
+import (
+    "fmt"
+    "strings"
+    "unicode/utf8"
+)
+
+/*
+    The escape sequences of string literals, decoded the way Python
+    does, from the source text between the quotes. Bytes have the
+    simple ones and \xhh only, of which each makes one byte, while
+    str has \uXXXX, \UXXXXXXXX and \N{name} of code points as well.
+    An unknown escape is kept as it is, backslash and all.
+*/
+
+var simpleEscapes = map[byte]byte{
+    '\\': '\\',
+    '\'': '\'',
+    '"': '"',
+    'a': '\a',
+    'b': '\b',
+    'f': '\f',
+    'n': '\n',
+    'r': '\r',
+    't': '\t',
+    'v': '\v',
+}
+
+// decodeEscapes gives the value of a literal of body, or the message
+// of the SyntaxError if an escape in it is malformed
+func decodeEscapes(body string, isBytes bool) (string, string) {
+    if !strings.Contains(body, "\\") {
+        return body, ""
+    }
+
+    var res strings.Builder
+    for i := 0; i < len(body); i++ {
+        if body[i] != '\\' || i+1 == len(body) {
+            res.WriteByte(body[i])
+            continue
+        }
+
+        start := i
+        i++
+        c := body[i]
+        if c == '\n' {
+            // a backslash at the end of a line joins it with the next
+            continue
+        }
+        if ch, ok := simpleEscapes[c]; ok {
+            res.WriteByte(ch)
+            continue
+        }
+
+        switch {
+        case '0' <= c && c <= '7':
+            v := rune(c - '0')
+            for n := 1; n < 3 && i+1 < len(body) && '0' <= body[i+1] && body[i+1] <= '7'; n++ {
+                i++
+                v = v*8 + rune(body[i]-'0')
+            }
+            if isBytes {
+                res.WriteByte(byte(v))
+            } else {
+                res.WriteRune(v)
+            }
+        case c == 'x':
+            v, n := readHex(body[i+1:], 2)
+            i += n
+            if n < 2 {
+                if isBytes {
+                    return "", fmt.Sprintf("(value error) invalid \\x escape at position %d", start)
+                }
+                return "", unicodeError(start, i, "truncated \\xXX escape")
+            }
+            if isBytes {
+                res.WriteByte(byte(v))
+            } else {
+                res.WriteRune(v)
+            }
+        case (c == 'u' || c == 'U') && !isBytes:
+            size, form := 4, "\\uXXXX"
+            if c == 'U' {
+                size, form = 8, "\\UXXXXXXXX"
+            }
+            v, n := readHex(body[i+1:], size)
+            i += n
+            if n < size {
+                return "", unicodeError(start, i, "truncated " + form + " escape")
+            }
+            if v > utf8.MaxRune {
+                return "", unicodeError(start, i, "illegal Unicode character")
+            }
+            res.WriteRune(v)
+        case c == 'N' && !isBytes:
+            if i+1 == len(body) || body[i+1] != '{' {
+                return "", unicodeError(start, i, "malformed \\N character escape")
+            }
+            end := strings.IndexByte(body[i:], '}')
+            if end < 0 {
+                return "", unicodeError(start, len(body)-1, "malformed \\N character escape")
+            }
+            name := body[i+2:i+end]
+            i += end
+            v, ok := lookupName(name)
+            if !ok {
+                return "", unicodeError(start, i, "unknown Unicode character name")
+            }
+            res.WriteRune(v)
+        default:
+            res.WriteByte('\\')
+            res.WriteByte(c)
+        }
+    }
+
+    return res.String(), ""
+}
+
+// readHex reads up to size hex digits from the start of s, giving
+// their value and how many there are
+func readHex(s string, size int) (rune, int) {
+    var v rune
+    n := 0
+    for ; n < size && n < len(s); n++ {
+        d := hexDigit(s[n])
+        if d < 0 {
+            break
+        }
+        v = v*16 + d
+    }
+    return v, n
+}
+
+func hexDigit(c byte) rune {
+    switch {
+    case '0' <= c && c <= '9':
+        return rune(c - '0')
+    case 'a' <= c && c <= 'f':
+        return rune(c - 'a' + 10)
+    case 'A' <= c && c <= 'F':
+        return rune(c - 'A' + 10)
+    }
+    return -1
+}
+
+func unicodeError(start, end int, reason string) string {
+    return fmt.Sprintf("(unicode error) 'unicodeescape' codec can't decode bytes in position %d-%d: %s", start, end, reason)
+}
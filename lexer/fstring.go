@@ -0,0 +1,127 @@
+package lexer
+
+import (
+    "strings"
+)
+
+/*
+    The body of an f-string, like f'{name!r:>10} is {age}', is given
+    to the parser nearly as it is. Only its literal text, and that of
+    the specs of its fields, is decoded of escapes, with each brace
+    that comes out of it doubled, so that the parser finds a field at
+    every single brace and nowhere else. The expressions of the fields
+    are copied untouched.
+*/
+
+// normalizeFString gives the body of an f-string as the parser
+// wants it, or the message of the SyntaxError if it's malformed
+func normalizeFString(body string, raw bool) (string, string) {
+    res, _, err := normalizeText(body, 0, raw, false)
+    return res, err
+}
+
+// normalizeText normalizes the literal text from body[start] on,
+// which is either the rest of the f-string, or the spec of a field,
+// ending at its closing brace. It gives where that text ends
+func normalizeText(body string, start int, raw, spec bool) (string, int, string) {
+    var res, chunk strings.Builder
+    flush := func() string {
+        text := chunk.String()
+        chunk.Reset()
+        if !raw {
+            var err string
+            if text, err = decodeEscapes(text, false); err != "" {
+                return err
+            }
+        }
+        text = strings.ReplaceAll(text, "{", "{{")
+        res.WriteString(strings.ReplaceAll(text, "}", "}}"))
+        return ""
+    }
+
+    for i := start; i < len(body); i++ {
+        switch c := body[i]; {
+        case c == '\\' && !raw && i+1 < len(body):
+            end := i+2
+            if body[i+1] == 'N' && end < len(body) && body[end] == '{' {
+                // the braces of \N{name} are no field
+                if n := strings.IndexByte(body[end:], '}'); n >= 0 {
+                    end += n+1
+                }
+            }
+            chunk.WriteString(body[i:end])
+            i = end-1
+        case !spec && (c == '{' || c == '}') && i+1 < len(body) && body[i+1] == c:
+            chunk.WriteByte(c)
+            i++
+        case c == '}':
+            if !spec {
+                return "", 0, "f-string: single '}' is not allowed"
+            }
+            if err := flush(); err != "" {
+                return "", 0, err
+            }
+            return res.String(), i, ""
+        case c == '{':
+            if err := flush(); err != "" {
+                return "", 0, err
+            }
+            field, end, err := normalizeField(body, i, raw)
+            if err != "" {
+                return "", 0, err
+            }
+            res.WriteString(field)
+            i = end-1
+        default:
+            chunk.WriteByte(c)
+        }
+    }
+
+    if spec {
+        return "", 0, "f-string: expecting '}'"
+    }
+    if err := flush(); err != "" {
+        return "", 0, err
+    }
+    return res.String(), len(body), ""
+}
+
+// normalizeField gives the field whose opening brace is at
+// body[start], with its spec normalized, and the index just past
+// its closing brace
+func normalizeField(body string, start int, raw bool) (string, int, string) {
+    depth := 0
+    var quote byte
+    for i := start+1; i < len(body); i++ {
+        c := body[i]
+        switch {
+        case c == '\\':
+            return "", 0, "f-string expression part cannot include a backslash"
+        case quote != 0:
+            if c == quote {
+                quote = 0
+            }
+        case c == '\'' || c == '"':
+            quote = c
+        case c == '#':
+            return "", 0, "f-string expression part cannot include '#'"
+        case c == '(' || c == '[' || c == '{':
+            depth++
+        case depth == 0 && c == ':':
+            spec, end, err := normalizeText(body, i+1, raw, true)
+            if err != "" {
+                return "", 0, err
+            }
+            return body[start:i+1] + spec + "}", end+1, ""
+        case c == ')' || c == ']' || c == '}':
+            if depth > 0 {
+                depth--
+            } else if c == '}' {
+                return body[start:i+1], i+1, ""
+            } else {
+                return "", 0, "f-string: unmatched '" + string(c) + "'"
+            }
+        }
+    }
+    return "", 0, "f-string: expecting '}'"
+}
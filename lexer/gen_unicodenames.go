@@ -0,0 +1,122 @@
+//go:build ignore
+
+// gen_unicodenames writes unicodenames.go, the names of characters
+// for \N{name} escapes, from UnicodeData.txt and NameAliases.txt of
+// the Unicode Character Database. It reads them from the directory
+// given, or else downloads them from unicode.org:
+//
+//     go run gen_unicodenames.go [ucd-dir]
+package main
+
+import (
+    "bufio"
+    "bytes"
+    "fmt"
+    "io"
+    "log"
+    "net/http"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+)
+
+const ucdURL = "https://www.unicode.org/Public/14.0.0/ucd/"
+
+func main() {
+    dir := ""
+    if len(os.Args) > 1 {
+        dir = os.Args[1]
+    }
+
+    var names []string
+    var cjk [][2]rune
+    var first rune
+    eachLine(open(dir, "UnicodeData.txt"), func(fields []string) {
+        code, name := parseCode(fields[0]), fields[1]
+        switch {
+        case !strings.HasPrefix(name, "<"):
+            names = append(names, fmt.Sprintf("%v;%04X", name, code))
+        // the ideographs come as a range, and are named after their code points
+        case strings.HasPrefix(name, "<CJK Ideograph") && strings.HasSuffix(name, ", First>"):
+            first = code
+        case strings.HasPrefix(name, "<CJK Ideograph") && strings.HasSuffix(name, ", Last>"):
+            cjk = append(cjk, [2]rune{first, code})
+        }
+    })
+
+    // the aliases are names as well, like LINE FEED of the
+    // control chars that have none of their own
+    eachLine(open(dir, "NameAliases.txt"), func(fields []string) {
+        names = append(names, fmt.Sprintf("%v;%04X", fields[1], parseCode(fields[0])))
+    })
+
+    var b bytes.Buffer
+    fmt.Fprintf(&b, "// Code generated by gen_unicodenames.go from %v; DO NOT EDIT.\n\n", ucdURL)
+    fmt.Fprintf(&b, "package lexer\n\n")
+    fmt.Fprintf(&b, "// charNameData has a line NAME;XXXX of each name and alias of a char\n")
+    fmt.Fprintf(&b, "const charNameData = `\n%v\n`\n\n", strings.Join(names, "\n"))
+    fmt.Fprintf(&b, "// cjkIdeographs are the ranges of the CJK unified ideographs\n")
+    fmt.Fprintf(&b, "var cjkIdeographs = [][2]rune{\n")
+    for _, r := range cjk {
+        fmt.Fprintf(&b, "    {0x%04X, 0x%04X},\n", r[0], r[1])
+    }
+    fmt.Fprintf(&b, "}\n")
+
+    if err := os.WriteFile("unicodenames.go", b.Bytes(), 0644); err != nil {
+        log.Fatal(err)
+    }
+}
+
+func open(dir, name string) io.ReadCloser {
+    if dir != "" {
+        f, err := os.Open(filepath.Join(dir, name))
+        if err != nil {
+            log.Fatal(err)
+        }
+        return f
+    }
+
+    resp, err := http.Get(ucdURL + name)
+    if err != nil {
+        log.Fatal(err)
+    }
+    if resp.StatusCode != http.StatusOK {
+        log.Fatalf("%v: %v", ucdURL + name, resp.Status)
+    }
+    return resp.Body
+}
+
+// eachLine calls fn with the ;-separated fields of each line of a file
+// of the database, leaving out comments and blank lines
+func eachLine(r io.ReadCloser, fn func(fields []string)) {
+    defer r.Close()
+
+    sc := bufio.NewScanner(r)
+    for sc.Scan() {
+        line := sc.Text()
+        if i := strings.IndexByte(line, '#'); i >= 0 {
+            line = line[:i]
+        }
+        if strings.TrimSpace(line) == "" {
+            continue
+        }
+
+        fields := strings.Split(line, ";")
+        for i := range fields {
+            fields[i] = strings.TrimSpace(fields[i])
+        }
+        fn(fields)
+    }
+    if err := sc.Err(); err != nil {
+        log.Fatal(err)
+    }
+}
+
+func parseCode(s string) rune {
+    v, err := strconv.ParseUint(s, 16, 32)
+    if err != nil {
+        log.Fatal(err)
+    }
+    return rune(v)
+}
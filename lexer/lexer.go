@@ -2,6 +2,10 @@ package lexer
 
 import (
     "fmt"
+    "math/big"
+    "strings"
+    "unicode"
+    "unicode/utf8"
 
     "github.com/realyixuan/gsubpy/token"
     "github.com/realyixuan/gsubpy/evaluator"
@@ -12,7 +16,8 @@ type Lexer struct {
     idx         int
     LineNum     int
     Line        string
-    ch          byte
+    ch          rune
+    depth       int     // of the brackets the current token is in
     Indents     string
     indentReady bool
     lineReady   bool
@@ -20,6 +25,8 @@ type Lexer struct {
 }
 
 func New(input string) *Lexer {
+    // a byte order mark may start a UTF-8 source
+    input = strings.TrimPrefix(input, "\ufeff")
     l := &Lexer{input: input, indentReady: true}
     l.readLine()
     l.readChar()
@@ -64,7 +71,7 @@ func (l *Lexer) readNextToken() {
             l.CurToken = token.Token{Type: token.NEQ, Literals: token.NEQ}
             l.readChar()
         } else {
-            panic(evaluator.Error(fmt.Sprintf("line %v\n\t%s\nSyntaxError: invalid syntax", l.LineNum, l.Line)))
+            panic(evaluator.SyntaxError("invalid syntax", l.LineNum, l.Line))
         }
     case '+':
         l.readChar()
@@ -87,6 +94,14 @@ func (l *Lexer) readNextToken() {
         if l.ch == '=' {
             l.CurToken = token.Token{Type: token.MULASSIGN, Literals: token.MULASSIGN}
             l.readChar()
+        } else if l.ch == '*' {
+            l.readChar()
+            if l.ch == '=' {
+                l.CurToken = token.Token{Type: token.POWASSIGN, Literals: token.POWASSIGN}
+                l.readChar()
+            } else {
+                l.CurToken = token.Token{Type: token.POW, Literals: token.POW}
+            }
         } else {
             l.CurToken = token.Token{Type: token.MUL, Literals: "*"}
         }
@@ -95,57 +110,106 @@ func (l *Lexer) readNextToken() {
         if l.ch == '=' {
             l.CurToken = token.Token{Type: token.DIVIDEASSIGN, Literals: token.DIVIDEASSIGN}
             l.readChar()
+        } else if l.ch == '/' {
+            l.readChar()
+            if l.ch == '=' {
+                l.CurToken = token.Token{Type: token.FLOORDIVASSIGN, Literals: token.FLOORDIVASSIGN}
+                l.readChar()
+            } else {
+                l.CurToken = token.Token{Type: token.FLOORDIV, Literals: token.FLOORDIV}
+            }
         } else {
             l.CurToken = token.Token{Type: token.DIVIDE, Literals: token.DIVIDE}
         }
-    case '>':
-        l.CurToken = token.Token{Type: token.GT, Literals: string(l.ch)}
+    case '%':
+        l.CurToken = l.readOperator(token.MOD, token.MODASSIGN)
+    case '&':
+        l.CurToken = l.readOperator(token.BITAND, token.BITANDASSIGN)
+    case '|':
+        l.CurToken = l.readOperator(token.BITOR, token.BITORASSIGN)
+    case '^':
+        l.CurToken = l.readOperator(token.BITXOR, token.BITXORASSIGN)
+    case '~':
+        l.CurToken = token.Token{Type: token.INVERT, Literals: string(l.ch)}
         l.readChar()
+    case '>':
+        if l.peekChar() == '>' {
+            l.readChar()
+            l.CurToken = l.readOperator(token.RSHIFT, token.RSHIFTASSIGN)
+        } else {
+            l.CurToken = l.readOperator(token.GT, token.GE)
+        }
     case '<':
-        l.CurToken = token.Token{Type: token.LT, Literals: string(l.ch)}
-        l.readChar()
+        if l.peekChar() == '<' {
+            l.readChar()
+            l.CurToken = l.readOperator(token.LSHIFT, token.LSHIFTASSIGN)
+        } else {
+            l.CurToken = l.readOperator(token.LT, token.LE)
+        }
     case '(':
         l.CurToken = token.Token{Type: token.LPAREN, Literals: string(l.ch)}
         l.readChar()
+        l.depth++
     case ')':
         l.CurToken = token.Token{Type: token.RPAREN, Literals: string(l.ch)}
         l.readChar()
+        if l.depth > 0 {
+            l.depth--
+        }
     case '[':
         l.CurToken = token.Token{Type: token.LBRACKET, Literals: string(l.ch)}
         l.readChar()
+        l.depth++
     case ']':
         l.CurToken = token.Token{Type: token.RBRACKET, Literals: string(l.ch)}
         l.readChar()
+        if l.depth > 0 {
+            l.depth--
+        }
     case '{':
         l.CurToken = token.Token{Type: token.LBRACE, Literals: string(l.ch)}
         l.readChar()
+        l.depth++
     case '}':
         l.CurToken = token.Token{Type: token.RBRACE, Literals: string(l.ch)}
         l.readChar()
+        if l.depth > 0 {
+            l.depth--
+        }
     case ',':
         l.CurToken = token.Token{Type: token.COMMA, Literals: string(l.ch)}
         l.readChar()
     case ':':
         l.CurToken = token.Token{Type: token.COLON, Literals: string(l.ch)}
         l.readChar()
-    case '.':
-        l.CurToken = token.Token{Type: token.DOT, Literals: string(l.ch)}
+    case '@':
+        l.CurToken = token.Token{Type: token.AT, Literals: string(l.ch)}
         l.readChar()
+    case '.':
+        if isDigit(l.peekChar()) {
+            l.CurToken = l.readNumber()
+        } else {
+            l.CurToken = token.Token{Type: token.DOT, Literals: string(l.ch)}
+            l.readChar()
+        }
     case '"', '\'':
-        l.CurToken = token.Token{Type: token.STRING}
-        l.CurToken.Literals = l.readString()
-        l.readChar()
+        l.CurToken = l.readString("")
     case '\\':
         l.readChar()
         if l.ch != '\n' {
-            panic(evaluator.Error(fmt.Sprintf(
-                "line %v\n\t%s\nSyntaxError: unexpected character after line continuation character",
-                l.LineNum, l.Line)))
+            panic(evaluator.SyntaxError("unexpected character after line continuation character", l.LineNum, l.Line))
         }
         l.readChar()
         l.LineNum++
         l.readNextToken()
     case '\n':
+        if l.depth > 0 {
+            // lines are joined inside brackets
+            l.readChar()
+            l.readLine()
+            l.readNextToken()
+            return
+        }
         l.CurToken = token.Token{Type: token.LINEFEED, Literals: string(l.ch)}
         l.readChar()
         l.indentReady = true
@@ -154,24 +218,36 @@ func (l *Lexer) readNextToken() {
         l.CurToken = token.Token{Type: token.EOF}
     default:
         if isDigit(l.ch) {
-            num := l.readNumber()
-            l.CurToken = token.Token{Type: token.INTEGER, Literals: num}
-        } else if isLetter(l.ch) {
+            l.CurToken = l.readNumber()
+        } else if isIdentifierStart(l.ch) {
             identifier := l.readLetters()
-            if tokType, ok := token.Keywords[identifier]; ok {
+            if (l.ch == '"' || l.ch == '\'') && isStringPrefix(identifier) {
+                l.CurToken = l.readString(identifier)
+            } else if tokType, ok := token.Keywords[identifier]; ok {
                 l.CurToken = token.Token{Type: tokType, Literals: identifier}
             } else {
                 l.CurToken = token.Token{Type: token.IDENTIFIER, Literals: identifier}
             }
+        } else if l.ch >= utf8.RuneSelf {
+            panic(evaluator.SyntaxError(fmt.Sprintf("invalid character '%c' (U+%04X)", l.ch, l.ch), l.LineNum, l.Line))
         } else {
-            panic(evaluator.Error(fmt.Sprintf("line %v\n\t%s\nSyntaxError: invalid syntax", l.LineNum, l.Line)))
-
-            l.CurToken = token.Token{Type: token.ILLEGAL}
-            l.readChar()
+            panic(evaluator.SyntaxError("invalid syntax", l.LineNum, l.Line))
         }
     }
 }
 
+// readOperator reads an operator ending at l.ch, which is op
+// itself, or opAssign, like its augmented assignment, if
+// followed by `=`
+func (l *Lexer) readOperator(op, opAssign token.TokenType) token.Token {
+    l.readChar()
+    if l.ch == '=' {
+        l.readChar()
+        return token.Token{Type: opAssign, Literals: string(opAssign)}
+    }
+    return token.Token{Type: op, Literals: string(op)}
+}
+
 func (l *Lexer) PeekNextToken() token.Token {
     // A trick
     lc := *l
@@ -198,47 +274,245 @@ func (l *Lexer) skipWhitespace() {
 
 }
 
-func (l *Lexer) readString() string {
-    var result string
-    stringMark := l.ch
+// readString reads a string literal from its opening quote at
+// l.ch, which may be tripled to let it span lines. Its prefix, read
+// already, tells whether it's raw, bytes or an f-string. The token
+// holds the value of the literal, with the escape sequences decoded
+func (l *Lexer) readString(prefix string) token.Token {
+    prefix = strings.ToLower(prefix)
+    tokType := token.TokenType(token.STRING)
+    if strings.Contains(prefix, "b") {
+        tokType = token.BYTES
+    } else if strings.Contains(prefix, "f") {
+        tokType = token.FSTRING
+    }
+
+    lineNum, line := l.LineNum, l.Line
+    quote := l.ch
     l.readChar()
-    for l.ch != stringMark && l.ch != '\x03' {
-        result += string(l.ch)
+    triple := false
+    if l.ch == quote && l.peekChar() == quote {
+        triple = true
+        l.readChar()
+        l.readChar()
+    }
+
+    var body strings.Builder
+    for {
+        if l.ch == '\x03' || l.ch == '\n' && !triple {
+            if triple {
+                detected := l.LineNum
+                if strings.HasSuffix(l.input, "\n") {
+                    // the end of the last line isn't one more line
+                    detected--
+                }
+                panic(evaluator.SyntaxError(fmt.Sprintf("unterminated triple-quoted string literal (detected at line %d)", detected), lineNum, line))
+            }
+            panic(evaluator.SyntaxError(fmt.Sprintf("unterminated string literal (detected at line %d)", l.LineNum), lineNum, line))
+        }
+
+        if l.ch == quote {
+            if !triple {
+                l.readChar()
+                break
+            }
+            if l.peekChar() == quote && l.idx+1 < len(l.input) && rune(l.input[l.idx+1]) == quote {
+                l.readChar()
+                l.readChar()
+                l.readChar()
+                break
+            }
+        }
+
+        if l.ch == '\\' {
+            // the escaped char never ends the literal, even if raw
+            body.WriteRune(l.ch)
+            l.readChar()
+            if l.ch == '\x03' {
+                continue
+            }
+        }
+        body.WriteRune(l.ch)
+        if l.ch == '\n' {
+            l.readLine()
+        }
+        l.readChar()
+    }
+
+    value := body.String()
+    if tokType == token.BYTES {
+        for i := 0; i < len(value); i++ {
+            if value[i] >= utf8.RuneSelf {
+                panic(evaluator.SyntaxError("bytes can only contain ASCII literal characters", lineNum, line))
+            }
+        }
+    }
+    if tokType == token.FSTRING {
+        var err string
+        if value, err = normalizeFString(value, strings.Contains(prefix, "r")); err != "" {
+            panic(evaluator.SyntaxError(err, lineNum, line))
+        }
+    } else if !strings.Contains(prefix, "r") {
+        var err string
+        if value, err = decodeEscapes(value, tokType == token.BYTES); err != "" {
+            panic(evaluator.SyntaxError(err, lineNum, line))
+        }
+    }
+
+    return token.Token{Type: tokType, Literals: value}
+}
+
+// isStringPrefix tells the letters that can lead a string literal,
+// in either case: u, r for raw, b for bytes, f for f-strings, and
+// br, rb, fr or rf
+func isStringPrefix(s string) bool {
+    switch strings.ToLower(s) {
+    case "u", "r", "b", "br", "rb", "f", "fr", "rf":
+        return true
+    }
+    return false
+}
+
+// readNumber reads an integer like `10`, `1_000` or `0x1f`, or a
+// float like `1.5`, `.5`, `1.` and `1e-3`. The token holds its digits
+// without underscores, those of another base turned into decimal
+func (l *Lexer) readNumber() token.Token {
+    if l.ch == '0' && strings.ContainsRune("xXoObB", l.peekChar()) {
+        return l.readBasedInteger()
+    }
+
+    tokType := token.TokenType(token.INTEGER)
+    res := l.readDigits()
+
+    if l.ch == '.' {
+        tokType = token.FLOAT
+        res += string(l.ch)
         l.readChar()
+        res += l.readDigits()
     }
 
-    if l.ch != stringMark {
-        panic(evaluator.Error(fmt.Sprintf("line %v\n\t%s\nSyntaxError: invalid syntax, string have wrong format", l.LineNum, l.Line)))
+    if next := l.peekChar(); (l.ch == 'e' || l.ch == 'E') &&
+        (isDigit(next) || (next == '+' || next == '-') && l.idx+1 < len(l.input) && isDigit(rune(l.input[l.idx+1]))) {
+        tokType = token.FLOAT
+        res += string(l.ch)
+        l.readChar()
+        if l.ch == '+' || l.ch == '-' {
+            res += string(l.ch)
+            l.readChar()
+        }
+        res += l.readDigits()
     }
+    l.checkNumberEnd("decimal")
 
-    return result
+    if tokType == token.INTEGER && strings.HasPrefix(res, "0") && strings.Trim(res, "0") != "" {
+        panic(evaluator.SyntaxError("leading zeros in decimal integer literals are not permitted; use an 0o prefix for octal integers", l.LineNum, l.Line))
+    }
+    return token.Token{Type: tokType, Literals: res}
 }
 
-func (l *Lexer) readNumber() string {
+// readBasedInteger reads an integer like `0x1f`, `0o17` or `0b1_0`
+func (l *Lexer) readBasedInteger() token.Token {
+    base, kind := 16, "hexadecimal"
+    switch l.peekChar() {
+    case 'o', 'O':
+        base, kind = 8, "octal"
+    case 'b', 'B':
+        base, kind = 2, "binary"
+    }
+    l.readChar()
+    l.readChar()
+
+    digits := ""
+    for {
+        if l.ch == '_' {
+            // an underscore may come before any digit
+            l.readChar()
+            if hexDigit(byte(l.ch)) < 0 || l.ch >= utf8.RuneSelf {
+                panic(evaluator.SyntaxError("invalid " + kind + " literal", l.LineNum, l.Line))
+            }
+        }
+        if l.ch >= utf8.RuneSelf || hexDigit(byte(l.ch)) < 0 || base != 16 && !isDigit(l.ch) {
+            break
+        }
+        if int(hexDigit(byte(l.ch))) >= base {
+            panic(evaluator.SyntaxError(fmt.Sprintf("invalid digit '%c' in %v literal", l.ch, kind), l.LineNum, l.Line))
+        }
+        digits += string(l.ch)
+        l.readChar()
+    }
+    if digits == "" {
+        panic(evaluator.SyntaxError("invalid " + kind + " literal", l.LineNum, l.Line))
+    }
+    l.checkNumberEnd(kind)
+
+    n, _ := new(big.Int).SetString(digits, base)
+    return token.Token{Type: token.INTEGER, Literals: n.String()}
+}
+
+// readDigits reads decimal digits, which may be grouped by single
+// underscores between them
+func (l *Lexer) readDigits() string {
     res := ""
     for isDigit(l.ch) {
         res += string(l.ch)
         l.readChar()
+        if l.ch == '_' {
+            l.readChar()
+            if !isDigit(l.ch) {
+                panic(evaluator.SyntaxError("invalid decimal literal", l.LineNum, l.Line))
+            }
+        }
     }
     return res
 }
 
+// checkNumberEnd makes sure a number isn't run together with a name,
+// like `3e` or `1abc`, though a keyword may follow it, like `1or 2`
+func (l *Lexer) checkNumberEnd(kind string) {
+    if !isIdentifierChar(l.ch) {
+        return
+    }
+    rest := l.input[l.idx-utf8.RuneLen(l.ch):]
+    for _, keyword := range []string{"and", "else", "for", "if", "in", "is", "not", "or"} {
+        if strings.HasPrefix(rest, keyword) {
+            return
+        }
+    }
+    panic(evaluator.SyntaxError("invalid " + kind + " literal", l.LineNum, l.Line))
+}
+
 func (l *Lexer) readLetters() string {
     res := ""
-    for isLetter(l.ch) || isDigit(l.ch) {
+    for isIdentifierChar(l.ch) {
         res += string(l.ch)
         l.readChar()
     }
     return res
 }
 
-func (l *Lexer) readChar() {
+// peekChar gives the char after l.ch without consuming it
+func (l *Lexer) peekChar() rune {
     if l.idx < len(l.input) {
-        l.ch = l.input[l.idx]
-    } else {
+        r, _ := utf8.DecodeRuneInString(l.input[l.idx:])
+        return r
+    }
+    return '\x03'
+}
+
+// readChar decodes the next char of the source, which is UTF-8
+func (l *Lexer) readChar() {
+    if l.idx >= len(l.input) {
         l.ch = '\x03'   // end of text, special byte
+        l.idx += 1
+        return
+    }
+
+    r, size := utf8.DecodeRuneInString(l.input[l.idx:])
+    if r == utf8.RuneError && size == 1 {
+        panic(evaluator.SyntaxError(fmt.Sprintf("Non-UTF-8 code starting with '\\x%02x'", l.input[l.idx]), l.LineNum, l.Line))
     }
-    l.idx += 1
+    l.ch = r
+    l.idx += size
 }
 
 func (l *Lexer)readLine() {
@@ -246,13 +520,13 @@ func (l *Lexer)readLine() {
     for idx := l.idx; idx < len(l.input) && l.input[idx] != '\n'; idx++ {
         if l.input[idx] == '\\' {
             if idx+1 < len(l.input) && l.input[idx+1] == '\n' {
-                l.Line += string(l.input[idx:idx+2])
+                l.Line += l.input[idx:idx+2]
                 idx++
             } else {
-                l.Line += string(l.input[idx])
+                l.Line += l.input[idx:idx+1]
             }
         } else {
-            l.Line += string(l.input[idx])
+            l.Line += l.input[idx:idx+1]
         }
     }
     l.LineNum++
@@ -266,12 +540,30 @@ func (l *Lexer) skipoverComment() {
     }
 }
 
-func isDigit(ch byte) bool {
+func isDigit(ch rune) bool {
     return '0' <= ch && ch <= '9'
 }
 
-func isLetter(ch byte) bool {
+func isLetter(ch rune) bool {
     return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
 }
 
+// isIdentifierStart tells the chars an identifier can start with,
+// which are letters of any language and the underscore
+func isIdentifierStart(ch rune) bool {
+    if ch < utf8.RuneSelf {
+        return isLetter(ch)
+    }
+    return unicode.IsLetter(ch) || unicode.Is(unicode.Nl, ch)
+}
+
+// isIdentifierChar tells the chars that can follow the first one of an
+// identifier, including digits, combining marks and connectors
+func isIdentifierChar(ch rune) bool {
+    if ch < utf8.RuneSelf {
+        return isLetter(ch) || isDigit(ch)
+    }
+    return isIdentifierStart(ch) || unicode.In(ch, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc)
+}
+
 
@@ -2,6 +2,7 @@ package lexer
 
 import (
     "github.com/realyixuan/gsubpy/token"
+    "github.com/realyixuan/gsubpy/evaluator"
 )
 
 import (
@@ -19,6 +20,15 @@ func TestIdentifier(t *testing.T) {
                 token.Token{Type: token.IDENTIFIER, Literals: "_abc12"},
             },
         },
+        {
+            `größe = 'π'`,
+            []token.Token{
+                token.Token{Type: token.IDENTIFIER, Literals: "größe"},
+                token.Token{Type: token.ASSIGN, Literals: "="},
+                token.Token{Type: token.STRING, Literals: "π"},
+                token.Token{Type: token.EOF},
+            },
+        },
     }
 
     for _, testCase := range testCases {
@@ -312,6 +322,39 @@ func TestStringStatement(t *testing.T) {
     }
 }
 
+func TestStringLiteral(t *testing.T) {
+    testCases := []struct {
+        input   string
+        expectedToken   token.Token
+    }{
+        {`'a\tb\\c\'d'`, token.Token{Type: token.STRING, Literals: "a\tb\\c'd"}},
+        {`"\x41é\U0001F600\101"`, token.Token{Type: token.STRING, Literals: "Aé😀A"}},
+        {`'\N{GREEK SMALL LETTER PI}\q'`, token.Token{Type: token.STRING, Literals: "π\\q"}},
+        {`'\N{HIRAGANA LETTER A}\N{hangul syllable gag}\N{CJK UNIFIED IDEOGRAPH-4E00}\N{LF}'`, token.Token{Type: token.STRING, Literals: "あ각一\n"}},
+        {`r'\n\''`, token.Token{Type: token.STRING, Literals: `\n\'`}},
+        {"'''a\n'b'\n'''", token.Token{Type: token.STRING, Literals: "a\n'b'\n"}},
+        {`b'\xff\u0041'`, token.Token{Type: token.BYTES, Literals: "\xff\\u0041"}},
+        {`Rb'\x00'`, token.Token{Type: token.BYTES, Literals: `\x00`}},
+        {`f'\x7b{x!r:\x3e{w}}\n'`, token.Token{Type: token.FSTRING, Literals: "{{{x!r:>{w}}\n"}},
+        {`rF'\n{x}'`, token.Token{Type: token.FSTRING, Literals: `\n{x}`}},
+    }
+
+    for _, testCase := range testCases {
+        l := New(testCase.input)
+        if l.CurToken != testCase.expectedToken {
+            t.Errorf("expected token %q, got token %q", testCase.expectedToken, l.CurToken)
+        }
+    }
+
+    l := New("s = '''a\nb\nc'''\nx")
+    for l.CurToken.Type != token.IDENTIFIER || l.CurToken.Literals != "x" {
+        l.ReadNextToken()
+    }
+    if l.LineNum != 4 {
+        t.Errorf("expected x at line 4, got line %d", l.LineNum)
+    }
+}
+
 func TestList(t *testing.T) {
     testCases := []struct {
         input   string
@@ -464,12 +507,20 @@ func TestOperatorAssign(t *testing.T) {
         expectedTokens []token.Token
     }{
         {
-            "+= -= *= /=",
+            "+= -= *= /= //= %= **= &= |= ^= <<= >>=",
             []token.Token{
                 token.Token{Type: token.PLUSASSIGN, Literals: "+="},
                 token.Token{Type: token.MINUSASSIGN, Literals: "-="},
                 token.Token{Type: token.MULASSIGN, Literals: "*="},
                 token.Token{Type: token.DIVIDEASSIGN, Literals: "/="},
+                token.Token{Type: token.FLOORDIVASSIGN, Literals: "//="},
+                token.Token{Type: token.MODASSIGN, Literals: "%="},
+                token.Token{Type: token.POWASSIGN, Literals: "**="},
+                token.Token{Type: token.BITANDASSIGN, Literals: "&="},
+                token.Token{Type: token.BITORASSIGN, Literals: "|="},
+                token.Token{Type: token.BITXORASSIGN, Literals: "^="},
+                token.Token{Type: token.LSHIFTASSIGN, Literals: "<<="},
+                token.Token{Type: token.RSHIFTASSIGN, Literals: ">>="},
             },
         },
     }
@@ -485,6 +536,111 @@ func TestOperatorAssign(t *testing.T) {
     }
 }
 
+func TestOperator(t *testing.T) {
+    testCases := []struct {
+        input   string
+        expectedTokens []token.Token
+    }{
+        {
+            "% ** ~ & | ^ << >> < >",
+            []token.Token{
+                token.Token{Type: token.MOD, Literals: "%"},
+                token.Token{Type: token.POW, Literals: "**"},
+                token.Token{Type: token.INVERT, Literals: "~"},
+                token.Token{Type: token.BITAND, Literals: "&"},
+                token.Token{Type: token.BITOR, Literals: "|"},
+                token.Token{Type: token.BITXOR, Literals: "^"},
+                token.Token{Type: token.LSHIFT, Literals: "<<"},
+                token.Token{Type: token.RSHIFT, Literals: ">>"},
+                token.Token{Type: token.LT, Literals: "<"},
+                token.Token{Type: token.GT, Literals: ">"},
+            },
+        },
+    }
+
+    for _, testCase := range testCases {
+        l := New(testCase.input)
+        for _, tk := range testCase.expectedTokens {
+            if tk != l.CurToken {
+                t.Errorf("expected token %s, got token %s", tk, l.CurToken)
+            }
+            l.ReadNextToken()
+        }
+    }
+}
+
+func TestNumber(t *testing.T) {
+    testCases := []struct {
+        input   string
+        expectedTokens []token.Token
+    }{
+        {
+            "10 1.5 .5 1. 1e-3 2E10 1_000 0x1F 0o_17 0b101 00 7 // 2",
+            []token.Token{
+                token.Token{Type: token.INTEGER, Literals: "10"},
+                token.Token{Type: token.FLOAT, Literals: "1.5"},
+                token.Token{Type: token.FLOAT, Literals: ".5"},
+                token.Token{Type: token.FLOAT, Literals: "1."},
+                token.Token{Type: token.FLOAT, Literals: "1e-3"},
+                token.Token{Type: token.FLOAT, Literals: "2E10"},
+                token.Token{Type: token.INTEGER, Literals: "1000"},
+                token.Token{Type: token.INTEGER, Literals: "31"},
+                token.Token{Type: token.INTEGER, Literals: "15"},
+                token.Token{Type: token.INTEGER, Literals: "5"},
+                token.Token{Type: token.INTEGER, Literals: "00"},
+                token.Token{Type: token.INTEGER, Literals: "7"},
+                token.Token{Type: token.FLOORDIV, Literals: "//"},
+                token.Token{Type: token.INTEGER, Literals: "2"},
+            },
+        },
+    }
+
+    for _, testCase := range testCases {
+        l := New(testCase.input)
+        for _, tk := range testCase.expectedTokens {
+            if tk != l.CurToken {
+                t.Errorf("expected token %s, got token %s", tk, l.CurToken)
+            }
+            l.ReadNextToken()
+        }
+    }
+}
+
+func TestMalformedNumber(t *testing.T) {
+    testCases := []struct {
+        input   string
+        expected    string
+    }{
+        {"3e", "SyntaxError: invalid decimal literal"},
+        {"1abc", "SyntaxError: invalid decimal literal"},
+        {"1__0", "SyntaxError: invalid decimal literal"},
+        {"1_", "SyntaxError: invalid decimal literal"},
+        {"1.5_", "SyntaxError: invalid decimal literal"},
+        {"0xg", "SyntaxError: invalid hexadecimal literal"},
+        {"0b102", "SyntaxError: invalid digit '2' in binary literal"},
+        {"012", "SyntaxError: leading zeros in decimal integer literals are not permitted; use an 0o prefix for octal integers"},
+    }
+
+    for _, testCase := range testCases {
+        func() {
+            defer func() {
+                e, ok := recover().(*evaluator.ExceptionInst)
+                if !ok || e.String() != testCase.expected {
+                    t.Errorf("%v: expected %q, got %v", testCase.input, testCase.expected, e)
+                }
+            } ()
+            New(testCase.input)
+        }()
+    }
+
+    // a keyword may follow a number right away
+    l := New("1or 2")
+    l.ReadNextToken()
+    if l.CurToken.Type != token.OR {
+        t.Errorf("expected token or, got %v", l.CurToken)
+    }
+}
+
 func TestLogicOperator(t *testing.T) {
     testCases := []struct {
         input   string
@@ -519,11 +675,15 @@ func TestComparisonOperator(t *testing.T) {
         expectedTokens []token.Token
     }{
         {
-            "==, !=",
+            "==, !=, <=, >=",
             []token.Token{
                 token.Token{Type: token.EQ, Literals: "=="},
                 token.Token{Type: token.COMMA, Literals: ","},
                 token.Token{Type: token.NEQ, Literals: "!="},
+                token.Token{Type: token.COMMA, Literals: ","},
+                token.Token{Type: token.LE, Literals: "<="},
+                token.Token{Type: token.COMMA, Literals: ","},
+                token.Token{Type: token.GE, Literals: ">="},
             },
         },
     }
@@ -17,11 +17,11 @@ func main() {
         if r := recover(); r != nil {
             switch o := r.(type) {
             case *evaluator.ExceptionInst:
-                for _, f := range evaluator.Py_traceback.Frames {
+                for _, f := range o.Traceback.Frames {
                     fmt.Println("line", f.LineNum)
                     fmt.Println("\t", strings.TrimLeft(f.Line, " \t"))
                 }
-                fmt.Println(o.Payload)
+                fmt.Println(o)
             default:
                 panic(r)
             }
@@ -0,0 +1,160 @@
+package parser
+
+import (
+    "strings"
+
+    "github.com/realyixuan/gsubpy/ast"
+    "github.com/realyixuan/gsubpy/lexer"
+    "github.com/realyixuan/gsubpy/token"
+    "github.com/realyixuan/gsubpy/evaluator"
+)
+
+/*
+    An f-string comes from the lexer with its literal text decoded,
+    and every brace of that text doubled, so that each single brace
+    opens a field: an expression, then perhaps `=` to show its text
+    too, a conversion like !r, and a spec after `:`, which can have
+    fields of its own, though those can't nest any deeper.
+*/
+
+// parsingFString parses the body of an f-string, or the spec of one
+// of its fields, which is nested within that many fields
+func (p *Parser) parsingFString(s string, nested int) *ast.FStringExpression {
+    expr := &ast.FStringExpression{}
+    var literal strings.Builder
+    flush := func() {
+        if literal.Len() > 0 {
+            expr.Parts = append(expr.Parts, &ast.StringExpression{
+                Value: token.Token{Type: token.STRING, Literals: literal.String()},
+            })
+            literal.Reset()
+        }
+    }
+
+    for i := 0; i < len(s); i++ {
+        if (s[i] == '{' || s[i] == '}') && i+1 < len(s) && s[i+1] == s[i] {
+            literal.WriteByte(s[i])
+            i++
+        } else if s[i] == '{' {
+            if nested > 1 {
+                p.fstringError("expressions nested too deeply")
+            }
+            var debug string
+            var field *ast.FormattedExpression
+            debug, field, i = p.parsingFormattedField(s, i+1, nested)
+            literal.WriteString(debug)
+            flush()
+            expr.Parts = append(expr.Parts, field)
+        } else {
+            literal.WriteByte(s[i])
+        }
+    }
+    flush()
+    return expr
+}
+
+// parsingFormattedField parses the field starting at s[start], just
+// after its opening brace, giving the text it shows of itself if it
+// has `=`, and the index of its closing brace
+func (p *Parser) parsingFormattedField(s string, start int, nested int) (string, *ast.FormattedExpression, int) {
+    i := exprEnd(s, start)
+    text := s[start:i]
+    if strings.TrimSpace(text) == "" {
+        if i < len(s) && s[i] == '!' {
+            p.fstringError("expression required before '!'")
+        }
+        p.fstringError("empty expression not allowed")
+    }
+    field := &ast.FormattedExpression{Value: p.parsingFieldExpression(text)}
+
+    debug := ""
+    if i < len(s) && s[i] == '=' {
+        for i++; i < len(s) && s[i] == ' '; i++ {}
+        debug = s[start:i]
+    }
+
+    if i < len(s) && s[i] == '!' {
+        i++
+        if i == len(s) || strings.IndexByte("sra", s[i]) < 0 {
+            p.fstringError("invalid conversion character: expected 's', 'r', or 'a'")
+        }
+        field.Conversion = s[i]
+        i++
+    }
+
+    if i < len(s) && s[i] == ':' {
+        specStart, depth := i+1, 0
+        for i = specStart; i < len(s) && (s[i] != '}' || depth > 0); i++ {
+            if s[i] == '{' {
+                depth++
+            } else if s[i] == '}' {
+                depth--
+            }
+        }
+        field.Spec = p.parsingFString(s[specStart:i], nested+1)
+    }
+
+    if i == len(s) || s[i] != '}' {
+        p.fstringError("expecting '}'")
+    }
+    if debug != "" && field.Conversion == 0 && field.Spec == nil {
+        // what shows its text is shown by repr, unless formatted
+        field.Conversion = 'r'
+    }
+    return debug, field, i
+}
+
+// parsingFieldExpression parses the expression of a field, as if it
+// were in parentheses, so that it may span lines or be a bare tuple
+func (p *Parser) parsingFieldExpression(text string) ast.Expression {
+    defer func() {
+        if r := recover(); r != nil {
+            if _, ok := r.(*evaluator.ExceptionInst); ok {
+                p.fstringError("invalid syntax")
+            }
+            panic(r)
+        }
+    }()
+
+    sub := New(lexer.New("(" + text + ")"))
+    sub.scope = p.scope
+    expr := sub.getLPARENPrefix()
+    if sub.l.CurToken.Type != token.RPAREN || sub.l.PeekNextToken().Type != token.EOF {
+        p.fstringError("invalid syntax")
+    }
+    return expr
+}
+
+func (p *Parser) fstringError(msg string) {
+    panic(evaluator.SyntaxError("f-string: " + msg, p.l.LineNum, p.l.Line))
+}
+
+// exprEnd gives the index where the expression of a field starting
+// at s[start] ends, at the `=`, `!`, `:` or `}` that follows it
+func exprEnd(s string, start int) int {
+    depth := 0
+    var quote byte
+    for i := start; i < len(s); i++ {
+        c := s[i]
+        switch {
+        case quote != 0:
+            if c == quote {
+                quote = 0
+            }
+        case c == '\'' || c == '"':
+            quote = c
+        case c == '(' || c == '[' || c == '{':
+            depth++
+        case depth > 0:
+            if c == ')' || c == ']' || c == '}' {
+                depth--
+            }
+        case i+1 < len(s) && s[i+1] == '=' && strings.IndexByte("=!<>", c) >= 0:
+            // ==, !=, <= and >= are operators
+            i++
+        case c == '=' || c == '!' || c == ':' || c == '}':
+            return i
+        }
+    }
+    return len(s)
+}
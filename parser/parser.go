@@ -1,8 +1,6 @@
 package parser
 
 import (
-    "fmt"
-
     "github.com/realyixuan/gsubpy/ast"
     "github.com/realyixuan/gsubpy/lexer"
     "github.com/realyixuan/gsubpy/token"
@@ -20,6 +18,8 @@ type Parser struct {
     prefixFns               map[token.TokenType]prefPrefixFn
     infixFns                map[token.TokenType]prefInfixFn
     statementParsingFns     map[token.TokenType]statementParsingFn
+
+    scope                   *scopeState
 }
 
 func New(l *lexer.Lexer) *Parser {
@@ -42,6 +42,12 @@ func New(l *lexer.Lexer) *Parser {
     p.registerStatementParsingFn(token.RETURN, p.parsingReturnStatement)
     p.registerStatementParsingFn(token.RAISE, p.parsingRaiseStatement)
     p.registerStatementParsingFn(token.ASSERT, p.parsingAssertStatement)
+    p.registerStatementParsingFn(token.TRY, p.parsingTryStatement)
+    p.registerStatementParsingFn(token.YIELD, p.parsingExpressionStatement)
+    p.registerStatementParsingFn(token.GLOBAL, p.parsingGlobalStatement)
+    p.registerStatementParsingFn(token.NONLOCAL, p.parsingNonlocalStatement)
+    p.registerStatementParsingFn(token.DEL, p.parsingDelStatement)
+    p.registerStatementParsingFn(token.AT, p.parsingDecoratedStatement)
 
     // a trick, if the a statement doesn't belong to any one above, then
     // it default to the expression-statement, using token IDENTIFIER to 
@@ -51,19 +57,37 @@ func New(l *lexer.Lexer) *Parser {
     // register expression-parsing function
     p.registerPrefixFn(token.IDENTIFIER, p.getIDENTIFIERPrefix)
     p.registerPrefixFn(token.INTEGER, p.getINTEGERPrefix)
+    p.registerPrefixFn(token.FLOAT, p.getFLOATPrefix)
     p.registerPrefixFn(token.STRING, p.getSTRINGPrefix)
+    p.registerPrefixFn(token.BYTES, p.getSTRINGPrefix)
+    p.registerPrefixFn(token.FSTRING, p.getSTRINGPrefix)
     p.registerPrefixFn(token.LBRACKET, p.getLBRACKETPrefix)
     p.registerPrefixFn(token.LBRACE, p.getLBRACEPrefix)
     p.registerPrefixFn(token.LPAREN, p.getLPARENPrefix)
     p.registerPrefixFn(token.NOT, p.getNOTPrefix)
+    p.registerPrefixFn(token.MINUS, p.getUnaryPrefix)
+    p.registerPrefixFn(token.PLUS, p.getUnaryPrefix)
+    p.registerPrefixFn(token.INVERT, p.getUnaryPrefix)
+    p.registerPrefixFn(token.YIELD, p.getYIELDPrefix)
+    p.registerPrefixFn(token.LAMBDA, p.getLAMBDAPrefix)
 
     p.registerInfixFn(token.DOT, p.getDOTInfix)
     p.registerInfixFn(token.PLUS, p.getPLUSInfix)
     p.registerInfixFn(token.MINUS, p.getMINUSInfix)
     p.registerInfixFn(token.MUL, p.getMULInfix)
     p.registerInfixFn(token.DIVIDE, p.getDIVIDEInfix)
+    p.registerInfixFn(token.FLOORDIV, p.getFLOORDIVInfix)
+    p.registerInfixFn(token.MOD, p.getMODInfix)
+    p.registerInfixFn(token.POW, p.getPOWInfix)
+    p.registerInfixFn(token.LSHIFT, p.getLSHIFTInfix)
+    p.registerInfixFn(token.RSHIFT, p.getRSHIFTInfix)
+    p.registerInfixFn(token.BITAND, p.getBITANDInfix)
+    p.registerInfixFn(token.BITOR, p.getBITORInfix)
+    p.registerInfixFn(token.BITXOR, p.getBITXORInfix)
     p.registerInfixFn(token.GT, p.getGTInfix)
     p.registerInfixFn(token.LT, p.getLTInfix)
+    p.registerInfixFn(token.GE, p.getGEInfix)
+    p.registerInfixFn(token.LE, p.getLEInfix)
     p.registerInfixFn(token.EQ, p.getEQInfix)
     p.registerInfixFn(token.NEQ, p.getNEQInfix)
     p.registerInfixFn(token.IN, p.getINInfix)
@@ -99,7 +123,7 @@ func (p *Parser)parsing(indents string) []ast.Statement {
         if isGTIndents(indents, p.l.Indents) {
             break
         } else if isLTIndents(indents, p.l.Indents) {
-            panic(evaluator.Error(fmt.Sprintf("line %v\n\t%s\nIndentError: wrong indents", p.l.LineNum, p.l.Line)))
+            panic(evaluator.IndentationError("wrong indents", p.l.LineNum, p.l.Line))
         }
 
         stmt := p.parsingStatement()
@@ -113,7 +137,7 @@ func (p *Parser)parsing(indents string) []ast.Statement {
 func (p *Parser)parsingStatement() ast.Statement {
     stmtParsingFn := p.getStmtParsingFn()
     if stmtParsingFn == nil {
-        panic(evaluator.Error(fmt.Sprintf("line %v\n\t%s\nSyntaxError: ...", p.l.LineNum, p.l.Line)))
+        panic(evaluator.SyntaxError("invalid syntax", p.l.LineNum, p.l.Line))
     }
     return stmtParsingFn()
 }
@@ -125,7 +149,7 @@ func (p *Parser) registerStatementParsingFn(tokenType token.TokenType, fn statem
 func (p *Parser) getStmtParsingFn() statementParsingFn {
     // Because there is no keyword to identify the assignment statement
     // so have to make a judgement for it
-    if _, ok := token.Keywords[p.l.CurToken.Literals]; ok {
+    if _, ok := token.Keywords[p.l.CurToken.Literals]; ok || p.l.CurToken.Type == token.AT {
         return p.statementParsingFns[p.l.CurToken.Type]
     }
 
@@ -141,7 +165,7 @@ func (p *Parser)parsingExpressionStatement() ast.Statement {
     expr := &ast.ExpressionStatement{
         Literals: ast.Literals{LineNum: p.l.LineNum, Line: p.l.Line},
     }
-    expr.Value = p.parsingExpression(0)
+    expr.Value = p.parsingExpressionList(LOWEST)
     p.skipExpectedLFToken()
     return expr
 }
@@ -162,12 +186,12 @@ func (p *Parser)parsingIfStatement() ast.Statement {
     if p.expectToken(token.COLON) {
         p.skipExpectedLFToken()
     } else {
-        panic(evaluator.Error(fmt.Sprintf("line %v\n\t%s\nSyntaxError: wrong syntax", p.l.LineNum, p.l.Line)))
+        panic(evaluator.SyntaxError("wrong syntax", p.l.LineNum, p.l.Line))
     }
 
     p.skipLF()
     if !isGTIndents(p.l.Indents, curIndents) {
-        panic(evaluator.Error(fmt.Sprintf("line %v\n\t%s\nIndentError: wrong Indents", p.l.LineNum, p.l.Line)))
+        panic(evaluator.IndentationError("wrong Indents", p.l.LineNum, p.l.Line))
     }
     
     ifStatement.Body = p.parsing(p.l.Indents)
@@ -200,12 +224,12 @@ func (p *Parser)parsingWhileStatement() ast.Statement {
     if p.expectToken(token.COLON) {
         p.skipExpectedLFToken()
     } else {
-        panic(evaluator.Error(fmt.Sprintf("line %v\n\t%s\nSyntaxError: wrong syntax", p.l.LineNum, p.l.Line)))
+        panic(evaluator.SyntaxError("wrong syntax", p.l.LineNum, p.l.Line))
     }
 
     p.skipLF()
     if isLTIndents(p.l.Indents, curIndents) && isEQIndents(p.l.Indents, curIndents) {
-        panic(evaluator.Error(fmt.Sprintf("line %v\n\t%s\nIndentError: wrong Indents", p.l.LineNum, p.l.Line)))
+        panic(evaluator.IndentationError("wrong Indents", p.l.LineNum, p.l.Line))
     }
     
     stmt.Body = p.parsing(p.l.Indents)
@@ -236,38 +260,30 @@ func (p *Parser)parsingForStatement() ast.Statement {
         Literals: ast.Literals{LineNum: p.l.LineNum, Line: p.l.Line},
     }
 
+    // targets bind tighter than comparisons, so that
+    // the `in` is not taken as an operator
     p.l.ReadNextToken()
-    var idents []token.Token
-    for ; p.l.CurToken.Type != token.IN; p.l.ReadNextToken() {
-        if p.l.CurToken.Type != token.IDENTIFIER {
-            panic(evaluator.Error(fmt.Sprintf("line %v\n\t%s\nSyntaxError: ", p.l.LineNum, p.l.Line)))
-        }
-
-        idents = append(idents, p.l.CurToken)
-
-        if p.l.PeekNextToken().Type == token.COMMA {
-            p.l.ReadNextToken()
-        }
-    }
+    stmt.Target = p.parsingExpressionList(COMPARISON)
+    p.checkAssignTarget(stmt.Target)
+    p.bindTarget(stmt.Target)
 
+    p.l.ReadNextToken()
     if p.l.CurToken.Type != token.IN {
-        panic(evaluator.Error(fmt.Sprintf("line %v\n\t%s\nSyntaxError: expect in", p.l.LineNum, p.l.Line)))
+        panic(evaluator.SyntaxError("expect in", p.l.LineNum, p.l.Line))
     }
-
-    stmt.Identifiers = idents
     p.l.ReadNextToken()
 
-    stmt.Target = p.parsingExpression(0)
+    stmt.Iter = p.parsingExpressionList(LOWEST)
 
     if p.expectToken(token.COLON) {
         p.skipExpectedLFToken()
     } else {
-        panic(evaluator.Error(fmt.Sprintf("line %v\n\t%s\nSyntaxError: wrong syntax", p.l.LineNum, p.l.Line)))
+        panic(evaluator.SyntaxError("wrong syntax", p.l.LineNum, p.l.Line))
     }
     
     p.skipLF()
     if isLTIndents(p.l.Indents, curIndents) && isEQIndents(p.l.Indents, curIndents) {
-        panic(evaluator.Error(fmt.Sprintf("line %v\n\t%s\nIndentError: wrong Indents", p.l.LineNum, p.l.Line)))
+        panic(evaluator.IndentationError("wrong Indents", p.l.LineNum, p.l.Line))
     }
     
     stmt.Body = p.parsing(p.l.Indents)
@@ -286,30 +302,68 @@ func (p *Parser)parsingDefStatement() ast.Statement {
 
     if p.expectToken(token.LPAREN) {
         p.l.ReadNextToken()
-        stmt.Params = p.parsingDefParams()
+        stmt.Params = p.parsingDefParams(token.RPAREN)
         if p.l.CurToken.Type != token.RPAREN {
-            panic(evaluator.Error(fmt.Sprintf("line %v\n\t%s\nSyntaxError: wrong syntax", p.l.LineNum, p.l.Line)))
+            panic(evaluator.SyntaxError("wrong syntax", p.l.LineNum, p.l.Line))
         }
     } else {
-        panic(evaluator.Error(fmt.Sprintf("line %v\n\t%s\nSyntaxError: wrong syntax", p.l.LineNum, p.l.Line)))
+        panic(evaluator.SyntaxError("wrong syntax", p.l.LineNum, p.l.Line))
     }
 
     if p.expectToken(token.COLON) {
         p.skipExpectedLFToken()
     } else {
-        panic(evaluator.Error(fmt.Sprintf("line %v\n\t%s\nSyntaxError: wrong syntax", p.l.LineNum, p.l.Line)))
+        panic(evaluator.SyntaxError("wrong syntax", p.l.LineNum, p.l.Line))
     }
 
     p.skipLF()
     if isLTIndents(p.l.Indents, curIndents) && isEQIndents(p.l.Indents, curIndents) {
-        panic(evaluator.Error(fmt.Sprintf("line %v\n\t%s\nIndentError: wrong Indents", p.l.LineNum, p.l.Line)))
+        panic(evaluator.IndentationError("wrong Indents", p.l.LineNum, p.l.Line))
     }
-    
+
+    p.bind(stmt.Name.Literals)
+    scope := p.enterScope(true)
+    p.bindParams(stmt.Params)
     stmt.Body = p.parsing(p.l.Indents)
+    p.leaveScope()
+
+    stmt.IsGenerator = scope.isGenerator
+    stmt.Scope = scope.scope
 
     return stmt
 }
 
+// parsingDecoratedStatement parses the `@decorator` lines
+// and the def or class statement they are put on
+func (p *Parser)parsingDecoratedStatement() ast.Statement {
+    curIndents := p.l.Indents
+
+    var decorators []ast.Expression
+    for p.l.CurToken.Type == token.AT {
+        p.l.ReadNextToken()
+        decorators = append(decorators, p.parsingExpression(LOWEST))
+        p.skipExpectedLFToken()
+
+        p.skipLF()
+        if !isEQIndents(p.l.Indents, curIndents) {
+            panic(evaluator.IndentationError("unexpected indent", p.l.LineNum, p.l.Line))
+        }
+    }
+
+    switch p.l.CurToken.Type {
+    case token.DEF:
+        stmt := p.parsingDefStatement().(*ast.DefStatement)
+        stmt.Decorators = decorators
+        return stmt
+    case token.CLASS:
+        stmt := p.parsingClassStatement().(*ast.ClassStatement)
+        stmt.Decorators = decorators
+        return stmt
+    default:
+        panic(evaluator.SyntaxError("invalid syntax", p.l.LineNum, p.l.Line))
+    }
+}
+
 func (p *Parser)parsingClassStatement() ast.Statement {
     classIndents := p.l.Indents
 
@@ -319,63 +373,209 @@ func (p *Parser)parsingClassStatement() ast.Statement {
         Literals: ast.Literals{LineNum: p.l.LineNum, Line: p.l.Line},
     }
 
-    // inheritance
+    // inheritance, the bases are given like the arguments of a call
     if p.l.PeekNextToken().Type == token.LPAREN {
         p.l.ReadNextToken()
-        p.l.ReadNextToken()
+        p.readNotLineFeedToken()
 
-        if p.l.CurToken.Type == token.IDENTIFIER {
-            stmt.Parent = p.l.CurToken
-            p.l.ReadNextToken()
-        } else if p.l.CurToken.Type == token.RPAREN {
-        }else {
-            panic(evaluator.Error(fmt.Sprintf("line %v\n\t%s\nSyntaxError: class define wrong syntax", p.l.LineNum, p.l.Line)))
+        var keywords []*ast.KeywordArg
+        stmt.Bases, keywords = p.parsingCallParams(LOWEST)
+        if len(keywords) > 0 {
+            panic(evaluator.SyntaxError("class keyword arguments are not supported", p.l.LineNum, p.l.Line))
         }
 
         if p.l.CurToken.Type != token.RPAREN {
-            panic(evaluator.Error(fmt.Sprintf("line %v\n\t%s\nSyntaxError: class define wrong syntax", p.l.LineNum, p.l.Line)))
+            panic(evaluator.SyntaxError("class define wrong syntax", p.l.LineNum, p.l.Line))
         }
     }
 
     if p.expectToken(token.COLON) {
         p.skipExpectedLFToken()
     } else {
-        panic(evaluator.Error(fmt.Sprintf("line %v\n\t%s\nSyntaxError: class define wrong syntax", p.l.LineNum, p.l.Line)))
+        panic(evaluator.SyntaxError("class define wrong syntax", p.l.LineNum, p.l.Line))
     }
 
     p.skipLF()
     internalIndents := p.l.Indents
     if !isGTIndents(internalIndents, classIndents) {
-        panic(evaluator.Error(fmt.Sprintf("line %v\n\t%s\nIndentError: in class wrong Indents", p.l.LineNum, p.l.Line)))
+        panic(evaluator.IndentationError("in class wrong Indents", p.l.LineNum, p.l.Line))
     }
 
+    p.bind(stmt.Name.Literals)
+    scope := p.enterScope(false)
     for _, st := range p.parsing(internalIndents) {
         stmt.Body = append(stmt.Body, st)
     }
+    p.leaveScope()
+    stmt.Scope = scope.scope
 
     return stmt
 }
 
 func (p *Parser)parsingReturnStatement() ast.Statement {
-    p.l.ReadNextToken()
     stmt := &ast.ReturnStatement{
-        Value: p.parsingExpression(LOWEST),
         Literals: ast.Literals{LineNum: p.l.LineNum, Line: p.l.Line},
     }
+
+    // a bare `return` returns None
+    if tokType := p.l.PeekNextToken().Type; tokType != token.LINEFEED && tokType != token.EOF {
+        p.l.ReadNextToken()
+        stmt.Value = p.parsingExpressionList(LOWEST)
+    }
+
     p.skipExpectedLFToken()
     return stmt
 }
 
 func (p *Parser)parsingRaiseStatement() ast.Statement {
-    p.l.ReadNextToken()
     stmt := &ast.RaiseStatement{
-        Value: p.parsingExpression(LOWEST),
         Literals: ast.Literals{LineNum: p.l.LineNum, Line: p.l.Line},
     }
+
+    // a bare `raise` re-raises the exception currently being handled
+    if tokType := p.l.PeekNextToken().Type; tokType != token.LINEFEED && tokType != token.EOF {
+        p.l.ReadNextToken()
+        stmt.Value = p.parsingExpression(LOWEST)
+    }
+
     p.skipExpectedLFToken()
     return stmt
 }
 
+func (p *Parser)parsingGlobalStatement() ast.Statement {
+    stmt := &ast.GlobalStatement{
+        Literals: ast.Literals{LineNum: p.l.LineNum, Line: p.l.Line},
+    }
+    stmt.Names = p.parsingNames()
+    for _, name := range stmt.Names {
+        p.declareGlobal(name.Literals)
+    }
+    p.skipExpectedLFToken()
+    return stmt
+}
+
+func (p *Parser)parsingNonlocalStatement() ast.Statement {
+    stmt := &ast.NonlocalStatement{
+        Literals: ast.Literals{LineNum: p.l.LineNum, Line: p.l.Line},
+    }
+    stmt.Names = p.parsingNames()
+    for _, name := range stmt.Names {
+        p.declareNonlocal(name.Literals)
+    }
+    p.skipExpectedLFToken()
+    return stmt
+}
+
+func (p *Parser)parsingDelStatement() ast.Statement {
+    stmt := &ast.DelStatement{
+        Literals: ast.Literals{LineNum: p.l.LineNum, Line: p.l.Line},
+    }
+
+    if !p.isExpressionStart(p.l.PeekNextToken().Type) {
+        panic(evaluator.SyntaxError("invalid syntax", p.l.LineNum, p.l.Line))
+    }
+    p.l.ReadNextToken()
+    stmt.Target = p.parsingExpressionList(LOWEST)
+
+    p.checkDelTarget(stmt.Target)
+    p.bindTarget(stmt.Target)
+
+    p.skipExpectedLFToken()
+    return stmt
+}
+
+// parsingNames parses the comma separated names after a keyword
+func (p *Parser)parsingNames() []token.Token {
+    var names []token.Token
+    for {
+        p.l.ReadNextToken()
+        if p.l.CurToken.Type != token.IDENTIFIER {
+            panic(evaluator.SyntaxError("invalid syntax", p.l.LineNum, p.l.Line))
+        }
+        names = append(names, p.l.CurToken)
+
+        if p.l.PeekNextToken().Type != token.COMMA {
+            return names
+        }
+        p.l.ReadNextToken()
+    }
+}
+
+func (p *Parser)parsingTryStatement() ast.Statement {
+    curIndents := p.l.Indents
+
+    stmt := &ast.TryStatement{
+        Literals: ast.Literals{LineNum: p.l.LineNum, Line: p.l.Line},
+    }
+
+    stmt.Body = p.parsingBlock(curIndents)
+
+    for p.l.CurToken.Type == token.EXCEPT && isEQIndents(p.l.Indents, curIndents) {
+        if len(stmt.Handlers) > 0 && stmt.Handlers[len(stmt.Handlers)-1].Type == nil {
+            panic(evaluator.SyntaxError("default 'except:' must be last", p.l.LineNum, p.l.Line))
+        }
+
+        handler := &ast.ExceptHandler{
+            Literals: ast.Literals{LineNum: p.l.LineNum, Line: p.l.Line},
+        }
+
+        if p.l.PeekNextToken().Type != token.COLON {
+            p.l.ReadNextToken()
+            handler.Type = p.parsingExpression(LOWEST)
+
+            if p.l.PeekNextToken().Type == token.AS {
+                p.l.ReadNextToken()
+                p.l.ReadNextToken()
+                if p.l.CurToken.Type != token.IDENTIFIER {
+                    panic(evaluator.SyntaxError("invalid syntax", p.l.LineNum, p.l.Line))
+                }
+                handler.Name = p.l.CurToken
+                p.bind(handler.Name.Literals)
+            }
+        }
+
+        handler.Body = p.parsingBlock(curIndents)
+        stmt.Handlers = append(stmt.Handlers, handler)
+    }
+
+    if p.l.CurToken.Type == token.ELSE && isEQIndents(p.l.Indents, curIndents) {
+        if len(stmt.Handlers) == 0 {
+            panic(evaluator.SyntaxError("invalid syntax", p.l.LineNum, p.l.Line))
+        }
+        stmt.Else = p.parsingBlock(curIndents)
+    }
+
+    if p.l.CurToken.Type == token.FINALLY && isEQIndents(p.l.Indents, curIndents) {
+        stmt.Finally = p.parsingBlock(curIndents)
+    }
+
+    if len(stmt.Handlers) == 0 && stmt.Finally == nil {
+        panic(evaluator.SyntaxError("expected 'except' or 'finally' block", p.l.LineNum, p.l.Line))
+    }
+
+    return stmt
+}
+
+// parsingBlock parses the `:` ending a clause header and the indented
+// body after it, leaving the current token at the start of the next line
+func (p *Parser)parsingBlock(curIndents string) []ast.Statement {
+    if p.expectToken(token.COLON) {
+        p.skipExpectedLFToken()
+    } else {
+        panic(evaluator.SyntaxError("wrong syntax", p.l.LineNum, p.l.Line))
+    }
+
+    p.skipLF()
+    if !isGTIndents(p.l.Indents, curIndents) {
+        panic(evaluator.IndentationError("wrong Indents", p.l.LineNum, p.l.Line))
+    }
+
+    body := p.parsing(p.l.Indents)
+    p.skipLF()
+
+    return body
+}
+
 func (p *Parser)parsingAssertStatement() ast.Statement {
     p.l.ReadNextToken()
     stmt := &ast.AssertStatement{
@@ -394,68 +594,121 @@ func (p *Parser)parsingAssertStatement() ast.Statement {
     return stmt
 }
 
-func (p *Parser)parsingDefParams() []token.Token {
-    var params []token.Token
+// parsingDefParams parses parameters up to end, which
+// is `)` for def statements and `:` for lambdas
+func (p *Parser)parsingDefParams(end token.TokenType) *ast.Parameters {
+    params := &ast.Parameters{}
+    names := map[string]bool{}
 
-    for p.l.CurToken.Type != token.RPAREN {
-        params = append(params, p.l.CurToken)
-        p.l.ReadNextToken()
-        if p.l.CurToken.Type == token.COMMA {
+    // after `*` or `*args`, the rest are keyword-only parameters
+    var starred bool
+
+    for p.l.CurToken.Type != end && p.l.CurToken.Type != token.EOF {
+        switch p.l.CurToken.Type {
+        case token.MUL:
+            if starred {
+                panic(evaluator.SyntaxError("* argument may appear only once", p.l.LineNum, p.l.Line))
+            }
+            starred = true
+            if tokType := p.l.PeekNextToken().Type; tokType != token.COMMA && tokType != end {
+                p.l.ReadNextToken()
+                params.VarArgs = p.parsingParam(names)
+            }
+        case token.POW:
             p.l.ReadNextToken()
+            params.KwArgs = p.parsingParam(names)
+        default:
+            if params.KwArgs != nil {
+                panic(evaluator.SyntaxError("arguments cannot follow var-keyword argument", p.l.LineNum, p.l.Line))
+            }
+
+            param := p.parsingParam(names)
+            if p.l.PeekNextToken().Type == token.ASSIGN {
+                p.l.ReadNextToken()
+                p.l.ReadNextToken()
+                param.Default = p.parsingExpression(LOWEST)
+            }
+
+            if starred {
+                params.KwOnlyArgs = append(params.KwOnlyArgs, param)
+            } else {
+                if param.Default == nil && len(params.Args) > 0 && params.Args[len(params.Args)-1].Default != nil {
+                    panic(evaluator.SyntaxError("non-default argument follows default argument", p.l.LineNum, p.l.Line))
+                }
+                params.Args = append(params.Args, param)
+            }
+        }
+
+        p.readNotLineFeedToken()
+        if p.l.CurToken.Type == token.COMMA {
+            p.readNotLineFeedToken()
         }
     }
 
+    if starred && params.VarArgs == nil && len(params.KwOnlyArgs) == 0 {
+        panic(evaluator.SyntaxError("named arguments must follow bare *", p.l.LineNum, p.l.Line))
+    }
+
     return params
 }
 
-func (p *Parser)parsingAssignStatement() ast.Statement {
-    expr := p.parsingExpression(LOWEST)
-    switch expr.(type) {
-    case *ast.IdentifierExpression:
-    case *ast.AttributeExpression:
-    case *ast.SubscriptExpression:
-    default:
-        panic(evaluator.Error(fmt.Sprintf("line %v\n\t%s\nSyntaxError: invalid syntax", p.l.LineNum, p.l.Line)))
+func (p *Parser)parsingParam(names map[string]bool) *ast.Param {
+    if p.l.CurToken.Type != token.IDENTIFIER {
+        panic(evaluator.SyntaxError("invalid syntax", p.l.LineNum, p.l.Line))
     }
 
-    assignment := ast.AssignStatement{
-        Target: expr,
-        Literals: ast.Literals{LineNum: p.l.LineNum, Line: p.l.Line},
+    if names[p.l.CurToken.Literals] {
+        panic(evaluator.SyntaxError(
+            "duplicate argument '" + p.l.CurToken.Literals + "' in function definition",
+            p.l.LineNum, p.l.Line))
     }
+    names[p.l.CurToken.Literals] = true
+
+    return &ast.Param{Name: p.l.CurToken}
+}
+
+func (p *Parser)parsingAssignStatement() ast.Statement {
+    expr := p.parsingExpressionList(LOWEST)
+    literals := ast.Literals{LineNum: p.l.LineNum, Line: p.l.Line}
 
     p.l.ReadNextToken()
 
-    symbol := p.l.CurToken.Type
+    operator := p.l.CurToken
 
     p.l.ReadNextToken()
 
-    var val ast.Expression
-    rExpr := p.parsingExpression(0)
-    if symbol == token.PLUSASSIGN {
-        val = &ast.PlusExpression{
-            Left: assignment.Target,
-            Right: rExpr,
-            }
-    } else if symbol == token.MINUSASSIGN {
-        val = &ast.MinusExpression{
-            Left: assignment.Target,
-            Right: rExpr,
-            }
-    } else if symbol == token.MULASSIGN {
-        val = &ast.MulExpression{
-            Left: assignment.Target,
-            Right: rExpr,
-            }
-    } else if symbol == token.DIVIDEASSIGN {
-        val = &ast.DivideExpression{
-            Left: assignment.Target,
-            Right: rExpr,
-            }
-    } else {
-        val = rExpr
+    p.bindTarget(expr)
+    if operator.Type != token.ASSIGN {
+        switch expr.(type) {
+        case *ast.IdentifierExpression:
+        case *ast.AttributeExpression:
+        case *ast.SubscriptExpression:
+        default:
+            panic(evaluator.SyntaxError("illegal expression for augmented assignment", p.l.LineNum, p.l.Line))
+        }
+
+        value := p.parsingExpressionList(LOWEST)
+        p.skipExpectedLFToken()
+        return &ast.AugAssignStatement{Target: expr, Operator: operator, Value: value, Literals: literals}
+    }
+
+    p.checkAssignTarget(expr)
+    assignment := ast.AssignStatement{
+        Targets: []ast.Expression{expr},
+        Literals: literals,
     }
 
-    assignment.Value = val
+    rExpr := p.parsingExpressionList(LOWEST)
+    for p.l.PeekNextToken().Type == token.ASSIGN {
+        // what came before the next `=` is one more target
+        p.bindTarget(rExpr)
+        p.checkAssignTarget(rExpr)
+        assignment.Targets = append(assignment.Targets, rExpr)
+        p.l.ReadNextToken()
+        p.l.ReadNextToken()
+        rExpr = p.parsingExpressionList(LOWEST)
+    }
+    assignment.Value = rExpr
 
     p.skipExpectedLFToken()
 
@@ -483,28 +736,270 @@ func (p *Parser)parsingExpression(precedence int) ast.Expression {
     return left
 }
 
-func (p *Parser)parsingCallParams(precedence int) []ast.Expression {
+// parsingExpressionList parses comma separated expressions, such as
+// both sides of `a, b = b, a`, into a tuple. A single expression
+// without a trailing comma is returned as it is
+func (p *Parser)parsingExpressionList(precedence int) ast.Expression {
+    expr := p.parsingStarredOrExpression(precedence)
+    if p.l.PeekNextToken().Type != token.COMMA {
+        if _, ok := expr.(*ast.StarredExpression); ok {
+            panic(evaluator.SyntaxError("can't use starred expression here", p.l.LineNum, p.l.Line))
+        }
+        return expr
+    }
+
+    tuple := &ast.TupleExpression{Items: []ast.Expression{expr}}
+    for p.l.PeekNextToken().Type == token.COMMA {
+        p.l.ReadNextToken()
+        if !p.isExpressionStart(p.l.PeekNextToken().Type) {
+            break
+        }
+        p.l.ReadNextToken()
+        tuple.Items = append(tuple.Items, p.parsingStarredOrExpression(precedence))
+    }
+    return tuple
+}
+
+// parsingStarredOrExpression parses an item of an expression list,
+// which, unlike a plain expression, can be a `*iterable`
+func (p *Parser)parsingStarredOrExpression(precedence int) ast.Expression {
+    if p.l.CurToken.Type == token.MUL {
+        p.l.ReadNextToken()
+        return &ast.StarredExpression{Value: p.parsingExpression(precedence)}
+    }
+    return p.parsingExpression(precedence)
+}
+
+// parsingComprehensionFors parses the `for ... in ... if ...` clauses
+// of a comprehension, from the first `for` up to the closing token.
+// mark is from before its element, as only the first iterable is
+// evaluated outside the comprehension, and can yield or load names
+func (p *Parser)parsingComprehensionFors(kind string, mark scopeMark) []*ast.ComprehensionFor {
+    var fors []*ast.ComprehensionFor
+    yields := mark.yields
+    var iterUses [2]int     // where the loads of the first iterable are
+
+    checkYields := func() {
+        if p.yieldCount() != yields {
+            panic(evaluator.SyntaxError("'yield' inside " + kind, p.l.LineNum, p.l.Line))
+        }
+    }
+
+    for p.l.CurToken.Type == token.FOR {
+        clause := &ast.ComprehensionFor{}
+
+        // the same as the target of a for statement
+        p.readNotLineFeedToken()
+        clause.Target = p.parsingExpressionList(COMPARISON)
+        p.checkAssignTarget(clause.Target)
+
+        p.readNotLineFeedToken()
+        if p.l.CurToken.Type != token.IN {
+            panic(evaluator.SyntaxError("expect in", p.l.LineNum, p.l.Line))
+        }
+        p.readNotLineFeedToken()
+        if len(fors) == 0 {
+            checkYields()
+            iterUses[0] = p.mark().uses
+            clause.Iter = p.parsingExpression(LOWEST)
+            iterUses[1] = p.mark().uses
+            yields = p.yieldCount()
+        } else {
+            clause.Iter = p.parsingExpression(LOWEST)
+        }
+        p.readNotLineFeedToken()
+
+        for p.l.CurToken.Type == token.IF {
+            p.readNotLineFeedToken()
+            clause.Ifs = append(clause.Ifs, p.parsingExpression(LOWEST))
+            p.readNotLineFeedToken()
+        }
+
+        fors = append(fors, clause)
+    }
+    checkYields()
+
+    if p.scope != nil {
+        used := p.scope.used
+        p.scope.used = append(used[:mark.uses], used[iterUses[0]:iterUses[1]]...)
+    }
+
+    return fors
+}
+
+// comprehensionElt checks the element of a list comprehension
+// or generator expression, which can't be starred
+func (p *Parser)comprehensionElt(expr ast.Expression) ast.Expression {
+    if _, ok := expr.(*ast.StarredExpression); ok {
+        panic(evaluator.SyntaxError("iterable unpacking cannot be used in comprehension", p.l.LineNum, p.l.Line))
+    }
+    return expr
+}
+
+func (p *Parser)isExpressionStart(tokType token.TokenType) bool {
+    _, ok := p.prefixFns[tokType]
+    return ok || tokType == token.MUL
+}
+
+// checkAssignTarget makes sure expr is something that can be
+// assigned to, with at most one starred target in each level
+func (p *Parser)checkAssignTarget(expr ast.Expression) {
+    var items []ast.Expression
+    switch node := expr.(type) {
+    case *ast.IdentifierExpression, *ast.AttributeExpression, *ast.SubscriptExpression:
+        return
+    case *ast.TupleExpression:
+        items = node.Items
+    case *ast.ListExpression:
+        items = node.Items
+    default:
+        panic(evaluator.SyntaxError("cannot assign to expression", p.l.LineNum, p.l.Line))
+    }
+
+    starred := false
+    for _, item := range items {
+        if node, ok := item.(*ast.StarredExpression); ok {
+            if starred {
+                panic(evaluator.SyntaxError("multiple starred expressions in assignment", p.l.LineNum, p.l.Line))
+            }
+            starred = true
+            item = node.Value
+        }
+        p.checkAssignTarget(item)
+    }
+}
+
+func (p *Parser)checkDelTarget(expr ast.Expression) {
+    switch node := expr.(type) {
+    case *ast.IdentifierExpression, *ast.AttributeExpression, *ast.SubscriptExpression:
+    case *ast.TupleExpression:
+        for _, item := range node.Items {
+            p.checkDelTarget(item)
+        }
+    case *ast.ListExpression:
+        for _, item := range node.Items {
+            p.checkDelTarget(item)
+        }
+    case *ast.StarredExpression:
+        panic(evaluator.SyntaxError("cannot delete starred", p.l.LineNum, p.l.Line))
+    case *ast.NumberExpression, *ast.StringExpression:
+        panic(evaluator.SyntaxError("cannot delete literal", p.l.LineNum, p.l.Line))
+    case *ast.CallExpression:
+        panic(evaluator.SyntaxError("cannot delete function call", p.l.LineNum, p.l.Line))
+    default:
+        panic(evaluator.SyntaxError("cannot delete expression", p.l.LineNum, p.l.Line))
+    }
+}
+
+func (p *Parser)parsingCallParams(precedence int) ([]ast.Expression, []*ast.KeywordArg) {
     var params []ast.Expression
+    var keywords []*ast.KeywordArg
+    names := map[string]bool{}
+    unpacked := false   // a `**` argument has been seen
+
     for p.l.CurToken.Type != token.RPAREN && p.l.CurToken.Type != token.EOF {
-        param := p.parsingExpression(LOWEST)
+        if p.l.CurToken.Type == token.MUL {
+            if unpacked {
+                panic(evaluator.SyntaxError("iterable argument unpacking follows keyword argument unpacking", p.l.LineNum, p.l.Line))
+            }
+            p.l.ReadNextToken()
+            params = append(params, &ast.StarredExpression{Value: p.parsingExpression(LOWEST)})
+        } else if p.l.CurToken.Type == token.POW {
+            unpacked = true
+            p.l.ReadNextToken()
+            keywords = append(keywords, &ast.KeywordArg{Value: p.parsingExpression(LOWEST)})
+        } else if p.l.CurToken.Type == token.IDENTIFIER && p.l.PeekNextToken().Type == token.ASSIGN {
+            name := p.l.CurToken
+            if names[name.Literals] {
+                panic(evaluator.SyntaxError("keyword argument repeated: " + name.Literals, p.l.LineNum, p.l.Line))
+            }
+            names[name.Literals] = true
+
+            p.l.ReadNextToken()
+            p.l.ReadNextToken()
+            keywords = append(keywords, &ast.KeywordArg{Name: name, Value: p.parsingExpression(LOWEST)})
+        } else {
+            if unpacked {
+                panic(evaluator.SyntaxError("positional argument follows keyword argument unpacking", p.l.LineNum, p.l.Line))
+            }
+            if len(keywords) > 0 {
+                panic(evaluator.SyntaxError("positional argument follows keyword argument", p.l.LineNum, p.l.Line))
+            }
+            mark := p.mark()
+            arg := p.parsingExpression(LOWEST)
+
+            // the parentheses of the call are enough for
+            // a generator expression as the only argument
+            if p.l.PeekNextToken().Type == token.FOR {
+                p.l.ReadNextToken()
+                arg = &ast.GeneratorExpression{Elt: arg, Fors: p.parsingComprehensionFors("generator expression", mark)}
+                if len(params) > 0 || p.l.CurToken.Type != token.RPAREN {
+                    panic(evaluator.SyntaxError("Generator expression must be parenthesized", p.l.LineNum, p.l.Line))
+                }
+                return append(params, arg), keywords
+            }
+            params = append(params, arg)
+        }
+
         p.readNotLineFeedToken()
-        params = append(params, param)
         if p.l.CurToken.Type == token.COMMA {
             p.readNotLineFeedToken()
         }
     }
 
-    return params
+    return params, keywords
 }
 
+// parsingSubscript parses what is between the brackets of a
+// subscription, an index or a slice, or a tuple of them like `a[1:, 2]`
 func (p *Parser)parsingSubscript(precedence int) ast.Expression {
-    var val ast.Expression
-    for p.l.CurToken.Type != token.RBRACKET && p.l.CurToken.Type != token.EOF {
-        val = p.parsingExpression(LOWEST)
+    var items []ast.Expression
+    isTuple := false
+    for p.l.CurToken.Type != token.RBRACKET {
+        items = append(items, p.parsingSliceOrExpression())
         p.readNotLineFeedToken()
+
+        if p.l.CurToken.Type == token.COMMA {
+            isTuple = true
+            p.readNotLineFeedToken()
+        } else if p.l.CurToken.Type != token.RBRACKET {
+            panic(evaluator.SyntaxError("invalid syntax", p.l.LineNum, p.l.Line))
+        }
     }
 
-    return val
+    if len(items) == 0 {
+        panic(evaluator.SyntaxError("invalid syntax", p.l.LineNum, p.l.Line))
+    } else if isTuple {
+        return &ast.TupleExpression{Items: items}
+    }
+    return items[0]
+}
+
+// parsingSliceOrExpression parses an item of a subscription, which
+// is a slice if there is a colon
+func (p *Parser)parsingSliceOrExpression() ast.Expression {
+    var start ast.Expression
+    if p.l.CurToken.Type != token.COLON {
+        start = p.parsingExpression(LOWEST)
+        if p.l.PeekNextToken().Type != token.COLON {
+            return start
+        }
+        p.l.ReadNextToken()
+    }
+
+    slice := &ast.SliceExpression{Start: start}
+    if p.isExpressionStart(p.l.PeekNextToken().Type) {
+        p.l.ReadNextToken()
+        slice.Stop = p.parsingExpression(LOWEST)
+    }
+    if p.l.PeekNextToken().Type == token.COLON {
+        p.l.ReadNextToken()
+        if p.isExpressionStart(p.l.PeekNextToken().Type) {
+            p.l.ReadNextToken()
+            slice.Step = p.parsingExpression(LOWEST)
+        }
+    }
+    return slice
 }
 
 func (p *Parser)isWhiteLine() bool {
@@ -517,7 +1012,7 @@ func (p *Parser)isWhiteLine() bool {
 func (p *Parser) getPrefixFn() prefPrefixFn {
     prefixFn, ok := p.prefixFns[p.l.CurToken.Type] 
     if !ok {
-        panic(evaluator.Error(fmt.Sprintf("line %v\n\t%s\nSyntaxError: invalid syntax", p.l.LineNum, p.l.Line)))
+        panic(evaluator.SyntaxError("invalid syntax", p.l.LineNum, p.l.Line))
     }
     return prefixFn
 }
@@ -539,6 +1034,10 @@ func getPrecedence(tok token.TokenType) int {
         return COMPARISON
     case token.GT:
         return COMPARISON
+    case token.LE:
+        return COMPARISON
+    case token.GE:
+        return COMPARISON
     case token.EQ:
         return COMPARISON
     case token.NEQ:
@@ -551,6 +1050,16 @@ func getPrecedence(tok token.TokenType) int {
         return COMPARISON
     case token.ISN:
         return COMPARISON
+    case token.BITOR:
+        return BITOR
+    case token.BITXOR:
+        return BITXOR
+    case token.BITAND:
+        return BITAND
+    case token.LSHIFT:
+        return SHIFT
+    case token.RSHIFT:
+        return SHIFT
     case token.PLUS:
         return SUM
     case token.MINUS:
@@ -559,6 +1068,12 @@ func getPrecedence(tok token.TokenType) int {
         return PRODUCT
     case token.DIVIDE:
         return PRODUCT
+    case token.FLOORDIV:
+        return PRODUCT
+    case token.MOD:
+        return PRODUCT
+    case token.POW:
+        return POWER
     case token.AND:
         return AND
     case token.OR:
@@ -572,16 +1087,22 @@ func getPrecedence(tok token.TokenType) int {
 }
 
 func (p *Parser) getIDENTIFIERPrefix() ast.Expression {
-    return &ast.IdentifierExpression{p.l.CurToken}
+    p.use(p.l.CurToken.Literals)
+    return &ast.IdentifierExpression{Identifier: p.l.CurToken}
 }
 
 func (p *Parser) isAssignStatement() bool {
     cl := *p.l
+    mark := p.mark()
     defer func() {
         p.l = &cl
+        // the names are loaded when the statement is parsed for real
+        if p.scope != nil {
+            p.scope.used = p.scope.used[:mark.uses]
+        }
     }()
 
-    p.parsingExpression(LOWEST)
+    p.parsingExpressionList(LOWEST)
 
     p.l.ReadNextToken()
 
@@ -597,7 +1118,15 @@ func (p *Parser) isAssignType(tokType token.TokenType) bool {
        tokType == token.PLUSASSIGN ||
        tokType == token.MINUSASSIGN ||
        tokType == token.MULASSIGN ||
-       tokType == token.DIVIDEASSIGN {
+       tokType == token.POWASSIGN ||
+       tokType == token.DIVIDEASSIGN ||
+       tokType == token.FLOORDIVASSIGN ||
+       tokType == token.MODASSIGN ||
+       tokType == token.LSHIFTASSIGN ||
+       tokType == token.RSHIFTASSIGN ||
+       tokType == token.BITANDASSIGN ||
+       tokType == token.BITORASSIGN ||
+       tokType == token.BITXORASSIGN {
         return true
     } else {
         return false
@@ -605,21 +1134,69 @@ func (p *Parser) isAssignType(tokType token.TokenType) bool {
 }
 
 func (p *Parser) getINTEGERPrefix() ast.Expression {
-    return &ast.NumberExpression{p.l.CurToken}
+    return &ast.NumberExpression{Value: p.l.CurToken}
+}
+
+func (p *Parser) getFLOATPrefix() ast.Expression {
+    return &ast.NumberExpression{Value: p.l.CurToken}
 }
 
+// getSTRINGPrefix reads a str, bytes or f-string literal, together
+// with those right after it, which are joined into one like in
+// `'ab' 'cd'`, and make an f-string if any of them is
 func (p *Parser) getSTRINGPrefix() ast.Expression {
-    return &ast.StringExpression{p.l.CurToken}
+    toks := []token.Token{p.l.CurToken}
+    isFString := toks[0].Type == token.FSTRING
+    for next := p.l.PeekNextToken(); isStringToken(next.Type); next = p.l.PeekNextToken() {
+        p.l.ReadNextToken()
+        if (next.Type == token.BYTES) != (toks[0].Type == token.BYTES) {
+            panic(evaluator.SyntaxError("cannot mix bytes and nonbytes literals", p.l.LineNum, p.l.Line))
+        }
+        isFString = isFString || next.Type == token.FSTRING
+        toks = append(toks, next)
+    }
+
+    if !isFString {
+        tok := toks[0]
+        for _, next := range toks[1:] {
+            tok.Literals += next.Literals
+        }
+        return &ast.StringExpression{Value: tok}
+    }
+
+    expr := &ast.FStringExpression{}
+    for _, tok := range toks {
+        if tok.Type == token.FSTRING {
+            expr.Parts = append(expr.Parts, p.parsingFString(tok.Literals, 0).Parts...)
+        } else {
+            expr.Parts = append(expr.Parts, &ast.StringExpression{Value: tok})
+        }
+    }
+    return expr
+}
+
+func isStringToken(tokType token.TokenType) bool {
+    return tokType == token.STRING || tokType == token.BYTES || tokType == token.FSTRING
 }
 
 func (p *Parser) getLBRACKETPrefix() ast.Expression {
     expr := &ast.ListExpression{}
+    mark := p.mark()
 
     p.readNotLineFeedToken()
     for p.l.CurToken.Type != token.EOF && p.l.CurToken.Type != token.RBRACKET {
-        expr.Items = append(expr.Items, p.parsingExpression(LOWEST))
+        expr.Items = append(expr.Items, p.parsingStarredOrExpression(LOWEST))
         p.readNotLineFeedToken()
 
+        if p.l.CurToken.Type == token.FOR && len(expr.Items) == 1 {
+            comp := &ast.ListCompExpression{Elt: p.comprehensionElt(expr.Items[0])}
+            comp.Fors = p.parsingComprehensionFors("list comprehension", mark)
+            if p.l.CurToken.Type != token.RBRACKET {
+                panic(evaluator.SyntaxError("expect ']'", p.l.LineNum, p.l.Line))
+            }
+            return comp
+        }
+
         if p.l.CurToken.Type == token.COMMA {
             p.readNotLineFeedToken()
         }
@@ -630,43 +1207,124 @@ func (p *Parser) getLBRACKETPrefix() ast.Expression {
 
 func (p *Parser) getLBRACEPrefix() ast.Expression {
     expr := &ast.DictExpression{}
+    mark := p.mark()
 
     p.readNotLineFeedToken()
     for p.l.CurToken.Type != token.EOF && p.l.CurToken.Type != token.RBRACE {
-        expr.Keys = append(expr.Keys, p.parsingExpression(LOWEST))
-        p.readNotLineFeedToken()
+        if len(expr.Keys) == 0 {
+            // the first item tells a set from a dict, a set has no colon after it
+            key := p.parsingStarredOrExpression(LOWEST)
+            p.readNotLineFeedToken()
+
+            if p.l.CurToken.Type == token.FOR {
+                comp := &ast.SetCompExpression{Elt: p.comprehensionElt(key)}
+                comp.Fors = p.parsingComprehensionFors("set comprehension", mark)
+                if p.l.CurToken.Type != token.RBRACE {
+                    panic(evaluator.SyntaxError("there is a syntax error in set, expect '}'", p.l.LineNum, p.l.Line))
+                }
+                return comp
+            }
+            if p.l.CurToken.Type != token.COLON {
+                return p.parsingSetItems(key)
+            }
+            if _, ok := key.(*ast.StarredExpression); ok {
+                panic(evaluator.SyntaxError("invalid syntax", p.l.LineNum, p.l.Line))
+            }
+            expr.Keys = append(expr.Keys, key)
+        } else {
+            expr.Keys = append(expr.Keys, p.parsingExpression(LOWEST))
+            p.readNotLineFeedToken()
+        }
 
         if p.l.CurToken.Type != token.COLON {
-            panic(evaluator.Error(fmt.Sprintf("line %v\n\t%s\nSyntaxError: there is a syntax error in dict", p.l.LineNum, p.l.Line)))
+            panic(evaluator.SyntaxError("there is a syntax error in dict", p.l.LineNum, p.l.Line))
         }
         p.readNotLineFeedToken()
 
         expr.Vals = append(expr.Vals, p.parsingExpression(LOWEST))
         p.readNotLineFeedToken()
 
+        if p.l.CurToken.Type == token.FOR && len(expr.Keys) == 1 {
+            comp := &ast.DictCompExpression{Key: expr.Keys[0], Val: expr.Vals[0]}
+            comp.Fors = p.parsingComprehensionFors("dict comprehension", mark)
+            if p.l.CurToken.Type != token.RBRACE {
+                panic(evaluator.SyntaxError("there is a syntax error in dict, expect '}'", p.l.LineNum, p.l.Line))
+            }
+            return comp
+        }
+
         if p.l.CurToken.Type == token.COMMA {
             p.readNotLineFeedToken()
         }
     }
 
     if p.l.CurToken.Type != token.RBRACE {
-        panic(evaluator.Error(fmt.Sprintf("line %v\n\t%s\nSyntaxError: there is a syntax error in dict, expect '}'", p.l.LineNum, p.l.Line)))
+        panic(evaluator.SyntaxError("there is a syntax error in dict, expect '}'", p.l.LineNum, p.l.Line))
+    }
+
+    return expr
+}
+
+// parsingSetItems parses the rest of a set display after its first item,
+// from the token following it up to the closing brace
+func (p *Parser) parsingSetItems(first ast.Expression) ast.Expression {
+    expr := &ast.SetExpression{Items: []ast.Expression{first}}
+    for p.l.CurToken.Type == token.COMMA {
+        p.readNotLineFeedToken()
+        if p.l.CurToken.Type == token.RBRACE {
+            break
+        }
+        expr.Items = append(expr.Items, p.parsingStarredOrExpression(LOWEST))
+        p.readNotLineFeedToken()
     }
 
+    if p.l.CurToken.Type != token.RBRACE {
+        panic(evaluator.SyntaxError("there is a syntax error in set, expect '}'", p.l.LineNum, p.l.Line))
+    }
     return expr
 }
 
 func (p *Parser) getLPARENPrefix() ast.Expression {
     p.readNotLineFeedToken()
+    if p.l.CurToken.Type == token.RPAREN {
+        return &ast.TupleExpression{}
+    }
+
+    mark := p.mark()
+    expr := p.parsingStarredOrExpression(LOWEST)
+    p.readNotLineFeedToken()
+    if p.l.CurToken.Type == token.FOR {
+        gen := &ast.GeneratorExpression{Elt: p.comprehensionElt(expr)}
+        gen.Fors = p.parsingComprehensionFors("generator expression", mark)
+        if p.l.CurToken.Type != token.RPAREN {
+            panic(evaluator.SyntaxError("expect ')'", p.l.LineNum, p.l.Line))
+        }
+        return gen
+    }
 
-    expr := p.parsingExpression(LOWEST)
+    if p.l.CurToken.Type == token.RPAREN {
+        if _, ok := expr.(*ast.StarredExpression); ok {
+            panic(evaluator.SyntaxError("can't use starred expression here", p.l.LineNum, p.l.Line))
+        }
+        return expr
+    }
 
-    if p.l.PeekNextToken().Type == token.RPAREN {
+    // a comma makes it a tuple, like `(1,)` or `(1, 2)`
+    tuple := &ast.TupleExpression{Items: []ast.Expression{expr}}
+    for p.l.CurToken.Type == token.COMMA {
+        p.readNotLineFeedToken()
+        if p.l.CurToken.Type == token.RPAREN {
+            break
+        }
+        tuple.Items = append(tuple.Items, p.parsingStarredOrExpression(LOWEST))
         p.readNotLineFeedToken()
-    } else {
-        panic(evaluator.Error(fmt.Sprintf("line %v\n\t%s\nSyntaxError: expect ')'", p.l.LineNum, p.l.Line)))
     }
-    return expr
+
+    if p.l.CurToken.Type != token.RPAREN {
+        panic(evaluator.SyntaxError("expect ')'", p.l.LineNum, p.l.Line))
+    }
+
+    return tuple
 }
 
 func (p *Parser) getNOTPrefix() ast.Expression {
@@ -678,6 +1336,65 @@ func (p *Parser) getNOTPrefix() ast.Expression {
     return expr
 }
 
+// getUnaryPrefix parses `-x`, `+x` and `~x`, which bind tighter
+// than binary operators except `**`, so `-2**2` is `-(2**2)`
+func (p *Parser) getUnaryPrefix() ast.Expression {
+    expr := &ast.UnaryExpression{Operator: p.l.CurToken}
+
+    p.l.ReadNextToken()
+    expr.Expr = p.parsingExpression(UNARY)
+
+    return expr
+}
+
+// getYIELDPrefix parses `yield`, `yield x` and `yield from x`,
+// which make the function they are in a generator
+func (p *Parser) getYIELDPrefix() ast.Expression {
+    if p.scope == nil || !p.scope.isFunction {
+        panic(evaluator.SyntaxError("'yield' outside function", p.l.LineNum, p.l.Line))
+    }
+    p.scope.isGenerator = true
+    p.scope.yields++
+
+    expr := &ast.YieldExpression{}
+    if p.l.PeekNextToken().Type == token.FROM {
+        p.l.ReadNextToken()
+        p.l.ReadNextToken()
+        expr.From = true
+        expr.Value = p.parsingExpression(LOWEST)
+    } else if p.isExpressionStart(p.l.PeekNextToken().Type) {
+        p.l.ReadNextToken()
+        expr.Value = p.parsingExpressionList(LOWEST)
+    }
+
+    return expr
+}
+
+// getLAMBDAPrefix parses `lambda params: body`, the body is a single
+// expression, so `lambda: 1, 2` is a tuple with a lambda in it
+func (p *Parser) getLAMBDAPrefix() ast.Expression {
+    expr := &ast.LambdaExpression{
+        Literals: ast.Literals{LineNum: p.l.LineNum, Line: p.l.Line},
+    }
+
+    p.l.ReadNextToken()
+    expr.Params = p.parsingDefParams(token.COLON)
+    if p.l.CurToken.Type != token.COLON {
+        panic(evaluator.SyntaxError("invalid syntax", p.l.LineNum, p.l.Line))
+    }
+    p.l.ReadNextToken()
+
+    scope := p.enterScope(true)
+    p.bindParams(expr.Params)
+    expr.Body = p.parsingExpression(LOWEST)
+    p.leaveScope()
+
+    expr.IsGenerator = scope.isGenerator
+    expr.Scope = scope.scope
+
+    return expr
+}
+
 func (p *Parser) registerPrefixFn(tok token.TokenType, fn prefPrefixFn) {
     p.prefixFns[tok] = fn
 }
@@ -718,75 +1435,125 @@ func (p *Parser) getDIVIDEInfix(left ast.Expression) ast.Expression {
     }
 }
 
-func (p *Parser) getGTInfix(left ast.Expression) ast.Expression {
-    return &ast.ComparisonExpression{
-        Operator: token.Token{token.GT, ">"},
+func (p *Parser) getFLOORDIVInfix(left ast.Expression) ast.Expression {
+    return &ast.FloorDivExpression{
         Left: left,
-        Right: p.parsingExpression(getPrecedence(token.GT)),
+        Right: p.parsingExpression(getPrecedence(token.FLOORDIV)),
     }
 }
 
-func (p *Parser) getLTInfix(left ast.Expression) ast.Expression {
-    return &ast.ComparisonExpression{
-        Operator: token.Token{token.LT, "<"},
+func (p *Parser) getMODInfix(left ast.Expression) ast.Expression {
+    return &ast.ModExpression{
         Left: left,
-        Right: p.parsingExpression(getPrecedence(token.LT)),
+        Right: p.parsingExpression(getPrecedence(token.MOD)),
     }
 }
 
-func (p *Parser) getEQInfix(left ast.Expression) ast.Expression {
-    return &ast.ComparisonExpression{
-        Operator: token.Token{token.EQ, "=="},
+// getPOWInfix parses the right operand with a lower precedence
+// than its own, so that `**` is right-associative
+func (p *Parser) getPOWInfix(left ast.Expression) ast.Expression {
+    return &ast.PowExpression{
         Left: left,
-        Right: p.parsingExpression(getPrecedence(token.EQ)),
+        Right: p.parsingExpression(UNARY),
     }
 }
 
-func (p *Parser) getNEQInfix(left ast.Expression) ast.Expression {
-    return &ast.ComparisonExpression{
-        Operator: token.Token{token.NEQ, "!="},
+func (p *Parser) getLSHIFTInfix(left ast.Expression) ast.Expression {
+    return &ast.LShiftExpression{
         Left: left,
-        Right: p.parsingExpression(getPrecedence(token.NEQ)),
+        Right: p.parsingExpression(getPrecedence(token.LSHIFT)),
     }
 }
 
-func (p *Parser) getINInfix(left ast.Expression) ast.Expression {
-    return &ast.ComparisonExpression{
-        Operator: token.Token{token.IN, "in"},
+func (p *Parser) getRSHIFTInfix(left ast.Expression) ast.Expression {
+    return &ast.RShiftExpression{
         Left: left,
-        Right: p.parsingExpression(getPrecedence(token.IN)),
+        Right: p.parsingExpression(getPrecedence(token.RSHIFT)),
     }
 }
 
-func (p *Parser) getNINInfix(left ast.Expression) ast.Expression {
-    return &ast.ComparisonExpression{
-        Operator: token.Token{token.NIN, "not in"},
+func (p *Parser) getBITANDInfix(left ast.Expression) ast.Expression {
+    return &ast.BitAndExpression{
         Left: left,
-        Right: p.parsingExpression(getPrecedence(token.NIN)),
+        Right: p.parsingExpression(getPrecedence(token.BITAND)),
     }
 }
 
-func (p *Parser) getISInfix(left ast.Expression) ast.Expression {
-    return &ast.ComparisonExpression{
-        Operator: token.Token{token.IS, "is"},
+func (p *Parser) getBITORInfix(left ast.Expression) ast.Expression {
+    return &ast.BitOrExpression{
         Left: left,
-        Right: p.parsingExpression(getPrecedence(token.IS)),
+        Right: p.parsingExpression(getPrecedence(token.BITOR)),
     }
 }
 
-func (p *Parser) getISNInfix(left ast.Expression) ast.Expression {
-    return &ast.ComparisonExpression{
-        Operator: token.Token{token.ISN, "is not"},
+func (p *Parser) getBITXORInfix(left ast.Expression) ast.Expression {
+    return &ast.BitXorExpression{
         Left: left,
-        Right: p.parsingExpression(getPrecedence(token.ISN)),
+        Right: p.parsingExpression(getPrecedence(token.BITXOR)),
     }
 }
 
-func (p *Parser) getLPARENInfix(left ast.Expression) ast.Expression {
-    return &ast.CallExpression{
-        Name: left,
-        Params: p.parsingCallParams(getPrecedence(token.LPAREN)),
+func (p *Parser) getGEInfix(left ast.Expression) ast.Expression {
+    return p.parsingComparison(left, token.Token{Type: token.GE, Literals: ">="})
+}
+
+func (p *Parser) getLEInfix(left ast.Expression) ast.Expression {
+    return p.parsingComparison(left, token.Token{Type: token.LE, Literals: "<="})
+}
+
+// parsingComparison parses the rest of a chain of comparisons, whose
+// first operator op is just read, like `< b <= c` of `a < b <= c`
+func (p *Parser) parsingComparison(left ast.Expression, op token.Token) ast.Expression {
+    expr := &ast.ComparisonExpression{Left: left}
+    expr.Operators = append(expr.Operators, op)
+    expr.Comparators = append(expr.Comparators, p.parsingExpression(COMPARISON))
+
+    for getPrecedence(p.l.PeekNextToken().Type) == COMPARISON {
+        p.l.ReadNextToken()
+        expr.Operators = append(expr.Operators, p.l.CurToken)
+        p.l.ReadNextToken()
+        expr.Comparators = append(expr.Comparators, p.parsingExpression(COMPARISON))
     }
+
+    return expr
+}
+
+func (p *Parser) getGTInfix(left ast.Expression) ast.Expression {
+    return p.parsingComparison(left, token.Token{Type: token.GT, Literals: ">"})
+}
+
+func (p *Parser) getLTInfix(left ast.Expression) ast.Expression {
+    return p.parsingComparison(left, token.Token{Type: token.LT, Literals: "<"})
+}
+
+func (p *Parser) getEQInfix(left ast.Expression) ast.Expression {
+    return p.parsingComparison(left, token.Token{Type: token.EQ, Literals: "=="})
+}
+
+func (p *Parser) getNEQInfix(left ast.Expression) ast.Expression {
+    return p.parsingComparison(left, token.Token{Type: token.NEQ, Literals: "!="})
+}
+
+func (p *Parser) getINInfix(left ast.Expression) ast.Expression {
+    return p.parsingComparison(left, token.Token{Type: token.IN, Literals: "in"})
+}
+
+func (p *Parser) getNINInfix(left ast.Expression) ast.Expression {
+    return p.parsingComparison(left, token.Token{Type: token.NIN, Literals: "not in"})
+}
+
+func (p *Parser) getISInfix(left ast.Expression) ast.Expression {
+    return p.parsingComparison(left, token.Token{Type: token.IS, Literals: "is"})
+}
+
+func (p *Parser) getISNInfix(left ast.Expression) ast.Expression {
+    return p.parsingComparison(left, token.Token{Type: token.ISN, Literals: "is not"})
+}
+
+func (p *Parser) getLPARENInfix(left ast.Expression) ast.Expression {
+    expr := &ast.CallExpression{Name: left}
+    expr.Params, expr.Keywords = p.parsingCallParams(getPrecedence(token.LPAREN))
+    return expr
 }
 
 func (p *Parser) getLBRACKETInfix(left ast.Expression) ast.Expression {
@@ -831,7 +1598,7 @@ func (p *Parser) skipExpectedLFToken() {
     p.l.ReadNextToken()
 
     if p.l.CurToken.Type != token.LINEFEED && p.l.CurToken.Type != token.EOF {
-        panic(evaluator.Error(fmt.Sprintf("line %v\n\t%s\nSyntaxError: expect linefeed", p.l.LineNum, p.l.Line)))
+        panic(evaluator.SyntaxError("expect linefeed", p.l.LineNum, p.l.Line))
     }
 
     p.l.ReadNextToken()
@@ -843,8 +1610,14 @@ const (
     AND
     NOT
     COMPARISON
+    BITOR
+    BITXOR
+    BITAND
+    SHIFT
     SUM
     PRODUCT
+    UNARY
+    POWER
     CALL
     ATTR
 )
@@ -1,9 +1,13 @@
 package parser
 
 import (
+    "fmt"
     "testing"
 
+    "github.com/realyixuan/gsubpy/ast"
     "github.com/realyixuan/gsubpy/lexer"
+    "github.com/realyixuan/gsubpy/token"
+    "github.com/realyixuan/gsubpy/evaluator"
 )
 
 func TestSpaceIndentParsing(t *testing.T) {
@@ -84,3 +88,283 @@ func TestTabSpaceIndentParsing(t *testing.T) {
     }
 }
 
+
+func TestFunctionScope(t *testing.T) {
+    input := "" +
+    "def f(a, *args):\n" +
+    "    global g\n" +
+    "    b, *c = a\n" +
+    "    g = b\n" +
+    "    for i in c:\n" +
+    "        d = [j for j in args]\n" +
+    "    def inner():\n" +
+    "        nonlocal b\n" +
+    "        b = 1\n"
+
+    p := New(lexer.New(input))
+    stmt := p.parsingStatement().(*ast.DefStatement)
+
+    for _, name := range []string{"a", "args", "b", "c", "i", "d", "inner"} {
+        if !stmt.Scope.Locals[name] {
+            t.Errorf("expect %v to be local", name)
+        }
+    }
+    for _, name := range []string{"g", "j"} {
+        if stmt.Scope.Locals[name] {
+            t.Errorf("expect %v not to be local", name)
+        }
+    }
+    if !stmt.Scope.Globals["g"] {
+        t.Errorf("expect g to be global")
+    }
+
+    inner := stmt.Body[len(stmt.Body)-1].(*ast.DefStatement)
+    if !inner.Scope.Nonlocals["b"] || inner.Scope.Locals["b"] {
+        t.Errorf("expect b to be nonlocal in inner")
+    }
+}
+
+func TestDecoratedStatement(t *testing.T) {
+    input := "" +
+    "@a.b\n" +
+    "\n" +
+    "@c(1)\n" +
+    "class C:\n" +
+    "    @d\n" +
+    "    def f(self):\n" +
+    "        return 1\n"
+
+    p := New(lexer.New(input))
+    stmt := p.parsingStatement().(*ast.ClassStatement)
+    if len(stmt.Decorators) != 2 {
+        t.Fatalf("expect 2 decorators, got %v", len(stmt.Decorators))
+    }
+    if _, ok := stmt.Decorators[0].(*ast.AttributeExpression); !ok {
+        t.Errorf("expect an attribute expression, got %T", stmt.Decorators[0])
+    }
+    if _, ok := stmt.Decorators[1].(*ast.CallExpression); !ok {
+        t.Errorf("expect a call expression, got %T", stmt.Decorators[1])
+    }
+
+    method := stmt.Body[0].(*ast.DefStatement)
+    if len(method.Decorators) != 1 || method.Name.Literals != "f" {
+        t.Errorf("expect f decorated once")
+    }
+}
+
+func TestDelStatement(t *testing.T) {
+    input := "" +
+    "def f(d):\n" +
+    "    del x, d['k'], d.a\n"
+
+    p := New(lexer.New(input))
+    stmt := p.parsingStatement().(*ast.DefStatement)
+    if !stmt.Scope.Locals["x"] {
+        t.Errorf("expect x to be local")
+    }
+
+    del := stmt.Body[0].(*ast.DelStatement)
+    targets, ok := del.Target.(*ast.TupleExpression)
+    if !ok || len(targets.Items) != 3 {
+        t.Fatalf("expect 3 targets, got %T", del.Target)
+    }
+    if _, ok := targets.Items[1].(*ast.SubscriptExpression); !ok {
+        t.Errorf("expect a subscript expression, got %T", targets.Items[1])
+    }
+}
+
+func TestSetDisplay(t *testing.T) {
+    tests := []struct {
+        input   string
+        expect  string
+    }{
+        {"{1, *a}", "*ast.SetExpression"},
+        {"{1}", "*ast.SetExpression"},
+        {"{1: 2}", "*ast.DictExpression"},
+        {"{}", "*ast.DictExpression"},
+        {"{x for x in a}", "*ast.SetCompExpression"},
+    }
+
+    for _, tt := range tests {
+        p := New(lexer.New(tt.input))
+        stmt := p.parsingStatement().(*ast.ExpressionStatement)
+        if got := fmt.Sprintf("%T", stmt.Value); got != tt.expect {
+            t.Errorf("%v: expect %v, got %v", tt.input, tt.expect, got)
+        }
+    }
+}
+
+func TestStringConcatenation(t *testing.T) {
+    tests := []struct {
+        input   string
+        tokType token.TokenType
+        expect  string
+    }{
+        {`'a' "b" r'\n'`, token.STRING, `ab\n`},
+        {"('a'\n 'b')", token.STRING, "ab"},
+        {`b'a' rb'\x00'`, token.BYTES, `a\x00`},
+    }
+
+    for _, tt := range tests {
+        p := New(lexer.New(tt.input))
+        stmt := p.parsingStatement().(*ast.ExpressionStatement)
+        str, ok := stmt.Value.(*ast.StringExpression)
+        if !ok {
+            t.Fatalf("%v: expect a string expression, got %T", tt.input, stmt.Value)
+        }
+        if str.Value.Type != tt.tokType || str.Value.Literals != tt.expect {
+            t.Errorf("%v: expect %v %q, got %v %q", tt.input, tt.tokType, tt.expect, str.Value.Type, str.Value.Literals)
+        }
+    }
+}
+
+func TestFString(t *testing.T) {
+    p := New(lexer.New(`'a' f'{x = !s:>{w}}'`))
+    stmt := p.parsingStatement().(*ast.ExpressionStatement)
+    fstr, ok := stmt.Value.(*ast.FStringExpression)
+    if !ok {
+        t.Fatalf("expect an f-string expression, got %T", stmt.Value)
+    }
+    if len(fstr.Parts) != 3 {
+        t.Fatalf("expect 3 parts, got %v", len(fstr.Parts))
+    }
+    for i, expect := range []string{"a", "x = "} {
+        if str := fstr.Parts[i].(*ast.StringExpression); str.Value.Literals != expect {
+            t.Errorf("expect part %v to be %q, got %q", i, expect, str.Value.Literals)
+        }
+    }
+
+    field := fstr.Parts[2].(*ast.FormattedExpression)
+    if ident := field.Value.(*ast.IdentifierExpression); ident.Identifier.Literals != "x" || field.Conversion != 's' {
+        t.Errorf("expect x!s, got %v!%c", ident.Identifier.Literals, field.Conversion)
+    }
+    if len(field.Spec.Parts) != 2 {
+        t.Fatalf("expect 2 parts of the spec, got %v", len(field.Spec.Parts))
+    }
+    if _, ok := field.Spec.Parts[1].(*ast.FormattedExpression); !ok {
+        t.Errorf("expect a field in the spec, got %T", field.Spec.Parts[1])
+    }
+}
+
+func TestChainedAssignment(t *testing.T) {
+    p := New(lexer.New("a = b, c = d = 1\n"))
+    stmt := p.parsingStatement().(*ast.AssignStatement)
+    if len(stmt.Targets) != 3 {
+        t.Fatalf("expect 3 targets, got %v", len(stmt.Targets))
+    }
+    if _, ok := stmt.Targets[1].(*ast.TupleExpression); !ok {
+        t.Errorf("expect the second target to be a tuple, got %T", stmt.Targets[1])
+    }
+    if _, ok := stmt.Value.(*ast.NumberExpression); !ok {
+        t.Errorf("expect the value to be a number, got %T", stmt.Value)
+    }
+}
+
+func TestAugmentedAssignment(t *testing.T) {
+    p := New(lexer.New("xs[0] |= y\n"))
+    stmt := p.parsingStatement().(*ast.AugAssignStatement)
+    if stmt.Operator.Type != token.BITORASSIGN {
+        t.Errorf("expect |=, got %v", stmt.Operator.Literals)
+    }
+    if _, ok := stmt.Target.(*ast.SubscriptExpression); !ok {
+        t.Errorf("expect the target to be a subscript, got %T", stmt.Target)
+    }
+    if _, ok := stmt.Value.(*ast.IdentifierExpression); !ok {
+        t.Errorf("expect the value to be a name, got %T", stmt.Value)
+    }
+}
+
+func TestYieldInComprehension(t *testing.T) {
+    testCases := []struct {
+        input   string
+        expected    string
+    }{
+        {"def f():\n    [(yield x) for x in xs]\n", "SyntaxError: 'yield' inside list comprehension"},
+        {"def f():\n    {x: (yield) for x in xs}\n", "SyntaxError: 'yield' inside dict comprehension"},
+        {"def f():\n    list((yield x) for x in xs)\n", "SyntaxError: 'yield' inside generator expression"},
+        {"def f():\n    {x for x in xs if (yield)}\n", "SyntaxError: 'yield' inside set comprehension"},
+        {"def f():\n    [x for x in xs for y in (yield)]\n", "SyntaxError: 'yield' inside list comprehension"},
+    }
+
+    for _, testCase := range testCases {
+        func() {
+            defer func() {
+                e, ok := recover().(*evaluator.ExceptionInst)
+                if !ok || e.String() != testCase.expected {
+                    t.Errorf("%q: expected %q, got %v", testCase.input, testCase.expected, e)
+                }
+            } ()
+            New(lexer.New(testCase.input)).Parsing()
+        }()
+    }
+
+    // the first iterable is evaluated outside the comprehension
+    stmt := New(lexer.New("def f():\n    [x for x in (yield)]\n")).parsingStatement().(*ast.DefStatement)
+    if !stmt.IsGenerator {
+        t.Errorf("expect f to be a generator")
+    }
+}
+
+func TestCallUnpackingOrder(t *testing.T) {
+    testCases := []struct {
+        input   string
+        expected    string
+    }{
+        {"f(**x, *y)\n", "SyntaxError: iterable argument unpacking follows keyword argument unpacking"},
+        {"f(**x, y)\n", "SyntaxError: positional argument follows keyword argument unpacking"},
+        {"f(k=1, y)\n", "SyntaxError: positional argument follows keyword argument"},
+    }
+
+    for _, testCase := range testCases {
+        func() {
+            defer func() {
+                e, ok := recover().(*evaluator.ExceptionInst)
+                if !ok || e.String() != testCase.expected {
+                    t.Errorf("%q: expected %q, got %v", testCase.input, testCase.expected, e)
+                }
+            } ()
+            New(lexer.New(testCase.input)).Parsing()
+        }()
+    }
+
+    // keywords and `*` may still follow one another
+    stmt := New(lexer.New("f(k=1, *y, **x, z=2)\n")).parsingStatement().(*ast.ExpressionStatement)
+    if call := stmt.Value.(*ast.CallExpression); len(call.Params) != 1 || len(call.Keywords) != 3 {
+        t.Errorf("expect 1 positional and 3 keyword arguments, got %v and %v", len(call.Params), len(call.Keywords))
+    }
+}
+
+func TestDeclarationAfterUse(t *testing.T) {
+    testCases := []struct {
+        input   string
+        expected    string
+    }{
+        {"def f():\n    y = x\n    global x\n", "SyntaxError: name 'x' is used prior to global declaration"},
+        {"def f():\n    x = x\n    global x\n", "SyntaxError: name 'x' is used prior to global declaration"},
+        {"def f():\n    x += 1\n    global x\n", "SyntaxError: name 'x' is assigned to before global declaration"},
+        {"def f():\n    [1 for y in x]\n    global x\n", "SyntaxError: name 'x' is used prior to global declaration"},
+        {"def f():\n    x = 1\n    def g():\n        x.a = 1\n        nonlocal x\n", "SyntaxError: name 'x' is used prior to nonlocal declaration"},
+        // a comprehension loads its element in a scope of its own
+        {"def f():\n    [x for y in z]\n    global x\n", ""},
+        {"def f():\n    def g():\n        return x\n    global x\n", ""},
+    }
+
+    for _, testCase := range testCases {
+        func() {
+            defer func() {
+                r := recover()
+                if testCase.expected == "" {
+                    if r != nil {
+                        t.Errorf("%q: expected no error, got %v", testCase.input, r)
+                    }
+                    return
+                }
+                e, ok := r.(*evaluator.ExceptionInst)
+                if !ok || e.String() != testCase.expected {
+                    t.Errorf("%q: expected %q, got %v", testCase.input, testCase.expected, e)
+                }
+            } ()
+            New(lexer.New(testCase.input)).Parsing()
+        }()
+    }
+}
@@ -0,0 +1,226 @@
+package parser
+
+import (
+    "github.com/realyixuan/gsubpy/ast"
+    "github.com/realyixuan/gsubpy/evaluator"
+)
+
+/*
+    The scope analysis is done along with parsing. Each function and
+    class body gets an ast.Scope telling which of its names are local,
+    declared global or declared nonlocal, so that the evaluator knows
+    where to look a name up or bind it without guessing at runtime.
+
+    A name can't be declared global or nonlocal after it's loaded, so
+    the loads of each body are recorded in order. The element of a
+    comprehension is parsed before it's known to be one, and the names
+    it loads are dropped once its clauses are, the first iterable
+    being the only part of it evaluated in the enclosing scope.
+
+    A nonlocal declaration has to be bound by an enclosing function,
+    which may assign the name only after the nested function, so it
+    is checked when the enclosing function is done.
+*/
+
+// scopeState is what is learned of a function or class body while
+// parsing it, p.scope is that of the innermost one, nil at module level
+type scopeState struct {
+    isFunction  bool
+    isGenerator bool
+    yields      int     // the yields parsed so far
+    used        []string    // the names loaded so far
+    scope       *ast.Scope
+    params      map[string]bool
+    nonlocals   []nonlocalDecl
+    parent      *scopeState
+
+    // nonlocal declarations of nested bodies, waiting
+    // for this body to be done to tell if it binds them
+    pending     []nonlocalDecl
+}
+
+type nonlocalDecl struct {
+    name        string
+    lineNum     int
+    line        string
+}
+
+// yieldCount gives the number of yields parsed in the innermost scope
+func (p *Parser) yieldCount() int {
+    if p.scope == nil {
+        return 0
+    }
+    return p.scope.yields
+}
+
+// scopeMark is how far parsing has got in the innermost scope,
+// taken before what may turn out the element of a comprehension
+type scopeMark struct {
+    yields      int
+    uses        int
+}
+
+func (p *Parser) mark() scopeMark {
+    if p.scope == nil {
+        return scopeMark{}
+    }
+    return scopeMark{yields: p.scope.yields, uses: len(p.scope.used)}
+}
+
+func (p *Parser) enterScope(isFunction bool) *scopeState {
+    p.scope = &scopeState{
+        isFunction: isFunction,
+        scope: ast.NewScope(),
+        params: map[string]bool{},
+        parent: p.scope,
+    }
+    return p.scope
+}
+
+// leaveScope ends the innermost scope, passing the nonlocal
+// declarations it doesn't bind on to the enclosing scope
+func (p *Parser) leaveScope() {
+    s := p.scope
+    p.scope = s.parent
+
+    var unbound []nonlocalDecl
+    for _, decl := range s.pending {
+        if s.isFunction && (s.scope.Locals[decl.name] || s.scope.Nonlocals[decl.name]) {
+            continue
+        }
+        unbound = append(unbound, decl)
+    }
+    unbound = append(unbound, s.nonlocals...)
+
+    if len(unbound) == 0 {
+        return
+    }
+    if p.scope == nil {
+        decl := unbound[0]
+        panic(evaluator.SyntaxError("no binding for nonlocal '" + decl.name + "' found", decl.lineNum, decl.line))
+    }
+    p.scope.pending = append(p.scope.pending, unbound...)
+}
+
+// bind records that name is bound in the current scope,
+// which makes it local unless it's declared otherwise
+func (p *Parser) bind(name string) {
+    if p.scope == nil {
+        return
+    }
+    scope := p.scope.scope
+    if !scope.Globals[name] && !scope.Nonlocals[name] {
+        scope.Locals[name] = true
+    }
+}
+
+// use records that name is loaded in the current scope
+func (p *Parser) use(name string) {
+    if p.scope != nil {
+        p.scope.used = append(p.scope.used, name)
+    }
+}
+
+// unuse takes back the last load of name, which was parsed
+// as an expression but is the target of an assignment
+func (p *Parser) unuse(name string) {
+    if p.scope == nil {
+        return
+    }
+    used := p.scope.used
+    for i := len(used) - 1; i >= 0; i-- {
+        if used[i] == name {
+            p.scope.used = append(used[:i], used[i+1:]...)
+            return
+        }
+    }
+}
+
+// isUsed tells if name is loaded in the current scope so far
+func (p *Parser) isUsed(name string) bool {
+    for _, used := range p.scope.used {
+        if used == name {
+            return true
+        }
+    }
+    return false
+}
+
+// bindTarget binds the names assigned to by the target of
+// an assignment or a for loop
+func (p *Parser) bindTarget(target ast.Expression) {
+    switch node := target.(type) {
+    case *ast.IdentifierExpression:
+        p.unuse(node.Identifier.Literals)
+        p.bind(node.Identifier.Literals)
+    case *ast.StarredExpression:
+        p.bindTarget(node.Value)
+    case *ast.TupleExpression:
+        for _, item := range node.Items {
+            p.bindTarget(item)
+        }
+    case *ast.ListExpression:
+        for _, item := range node.Items {
+            p.bindTarget(item)
+        }
+    }
+}
+
+func (p *Parser) bindParams(params *ast.Parameters) {
+    var names []*ast.Param
+    names = append(names, params.Args...)
+    names = append(names, params.KwOnlyArgs...)
+    if params.VarArgs != nil {
+        names = append(names, params.VarArgs)
+    }
+    if params.KwArgs != nil {
+        names = append(names, params.KwArgs)
+    }
+
+    for _, param := range names {
+        p.scope.params[param.Name.Literals] = true
+        p.bind(param.Name.Literals)
+    }
+}
+
+func (p *Parser) declareGlobal(name string) {
+    if p.scope == nil {
+        return
+    }
+
+    scope := p.scope.scope
+    switch {
+    case p.scope.params[name]:
+        panic(evaluator.SyntaxError("name '" + name + "' is parameter and global", p.l.LineNum, p.l.Line))
+    case scope.Nonlocals[name]:
+        panic(evaluator.SyntaxError("name '" + name + "' is nonlocal and global", p.l.LineNum, p.l.Line))
+    case p.isUsed(name):
+        panic(evaluator.SyntaxError("name '" + name + "' is used prior to global declaration", p.l.LineNum, p.l.Line))
+    case scope.Locals[name]:
+        panic(evaluator.SyntaxError("name '" + name + "' is assigned to before global declaration", p.l.LineNum, p.l.Line))
+    }
+    scope.Globals[name] = true
+}
+
+func (p *Parser) declareNonlocal(name string) {
+    if p.scope == nil {
+        panic(evaluator.SyntaxError("nonlocal declaration not allowed at module level", p.l.LineNum, p.l.Line))
+    }
+
+    scope := p.scope.scope
+    switch {
+    case p.scope.params[name]:
+        panic(evaluator.SyntaxError("name '" + name + "' is parameter and nonlocal", p.l.LineNum, p.l.Line))
+    case scope.Globals[name]:
+        panic(evaluator.SyntaxError("name '" + name + "' is nonlocal and global", p.l.LineNum, p.l.Line))
+    case p.isUsed(name):
+        panic(evaluator.SyntaxError("name '" + name + "' is used prior to nonlocal declaration", p.l.LineNum, p.l.Line))
+    case scope.Locals[name]:
+        panic(evaluator.SyntaxError("name '" + name + "' is assigned to before nonlocal declaration", p.l.LineNum, p.l.Line))
+    }
+
+    if !scope.Nonlocals[name] {
+        scope.Nonlocals[name] = true
+        p.scope.nonlocals = append(p.scope.nonlocals, nonlocalDecl{name: name, lineNum: p.l.LineNum, line: p.l.Line})
+    }
+}
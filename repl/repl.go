@@ -28,7 +28,7 @@ func REPLRunning() {
         tl := lexer.New(line)
 
         var input string
-        if _, ok := token.Keywords[tl.CurToken.Literals]; !ok {
+        if _, ok := token.Keywords[tl.CurToken.Literals]; !ok && tl.CurToken.Type != token.AT {
             input = line
         } else {
             input += line
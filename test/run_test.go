@@ -1,7 +1,9 @@
 package test
 
 import (
+    "runtime"
     "testing"
+    "time"
 
     "github.com/realyixuan/gsubpy/lexer"
     "github.com/realyixuan/gsubpy/parser"
@@ -12,7 +14,7 @@ func TestOneLineAssignStatement(t *testing.T) {
     input := `val = 10 + 20 * 10 / 2 - 50`
     env := testRunProgram(input)
 
-    if resultedObj := env.GetFromString("val").(*evaluator.IntegerInst); resultedObj.Value != 60 {
+    if resultedObj := env.GetFromString("val").(*evaluator.FloatInst); resultedObj.Value != 60 {
         t.Errorf("expected %v, got %v", 60, resultedObj.Value)
     }
 }
@@ -69,7 +71,7 @@ val = 2
 val /= 2
 `
     env := testRunProgram(input)
-    if obj := env.GetFromString("val").(*evaluator.IntegerInst); obj.Value != 1 {
+    if obj := env.GetFromString("val").(*evaluator.FloatInst); obj.Value != 1 {
         t.Errorf("expect 1, got %v", obj.Value)
     }
 }
@@ -84,6 +86,43 @@ res = 2 == 2
     }
 }
 
+func TestChainedComparison(t *testing.T) {
+    testCases := []struct {
+        input       string
+        expected    evaluator.Object
+    }{
+        {"res = 0 < 5 <= 5", evaluator.Py_True},
+        {"res = 0 < 5 >= 6", evaluator.Py_False},
+        {"res = (1 < 2) < 2", evaluator.Py_True},
+        {"res = 1 < 2 < 2", evaluator.Py_False},
+    }
+
+    for _, testCase := range testCases {
+        env := testRunProgram(testCase.input)
+        if obj := env.GetFromString("res"); obj != testCase.expected {
+            t.Errorf("%v: expect %v, got %v", testCase.input, testCase.expected, obj)
+        }
+    }
+}
+
+func TestReflectedOperator(t *testing.T) {
+    input := `
+class Meter:
+    def __init__(self, n):
+        self.n = n
+    def __add__(self, other):
+        return NotImplemented
+    def __radd__(self, other):
+        return other + self.n
+
+res = 1 + Meter(2)
+`
+    env := testRunProgram(input)
+    if obj := env.GetFromString("res").(*evaluator.IntegerInst); obj.Value != 3 {
+        t.Errorf("expect 3, got %v", obj.Value)
+    }
+}
+
 func TestIN(t *testing.T) {
     input := `
 res = 's' in 'abcs'
@@ -366,7 +405,7 @@ res = Foo(1).a
 func TestInheritanceWithoutObject(t *testing.T) {
     input := `
 class Foo:
-    def __new__(cls):
+    def __new__(cls, *args):
         return object.__new__(cls)
         
     def __init__(self, a):
@@ -502,6 +541,32 @@ func TestTypeError(t *testing.T) {
     
 }
 
+func TestBuiltinExceptionClass(t *testing.T) {
+    testCases := []struct {
+        input       string
+        expected    string
+    }{
+        {"1 / 0", "ZeroDivisionError: division by zero"},
+        {"{'a': 1}['b']", "KeyError: 'b'"},
+        {"[1][1]", "IndexError: list index out of range"},
+        {"int('x')", "ValueError: invalid literal for int() with base 10: 'x'"},
+        {"foo", "NameError: name 'foo' is not defined"},
+        {"assert 1 > 2", "AssertionError"},
+    }
+
+    for _, testCase := range testCases {
+        func() {
+            defer func() {
+                e, ok := recover().(*evaluator.ExceptionInst)
+                if !ok || e.String() != testCase.expected {
+                    t.Errorf("expected %q, got %v", testCase.expected, e)
+                }
+            } ()
+            testRunProgram(testCase.input)
+        }()
+    }
+}
+
 func TestIntClass(t *testing.T) {
     input := `
 res = int() + int(1) + int('2')
@@ -533,7 +598,7 @@ res = str() + str(1) + str("2")
     }
 }
 
-func TesthashFunction(t *testing.T) {
+func TestHashFunction(t *testing.T) {
     input := `
 res = hash(".")
 `
@@ -543,7 +608,7 @@ res = hash(".")
     }
 }
 
-func TestboolFunction(t *testing.T) {
+func TestBoolFunction(t *testing.T) {
     input := `
 res = bool(1)
 `
@@ -586,6 +651,17 @@ res = list[2]
     }
 }
 
+func TestListSlice(t *testing.T) {
+    input := `
+list = [1, 2, 3, 4]
+res = list[-3:-1]
+`
+    env := testRunProgram(input)
+    if res := env.GetFromString("res"); evaluator.StringOf(res).(*evaluator.StringInst).Value != "[2, 3]" {
+        t.Errorf("expect [2, 3], got %v", evaluator.StringOf(res))
+    }
+}
+
 func TestListWithMultiline(t *testing.T) {
     input := `
 list = [1,
@@ -635,6 +711,418 @@ res = 1 + \
     }
 }
 
+func TestTryExceptStatement(t *testing.T) {
+    input := `
+res = 0
+try:
+    1 / 0
+    res = 1
+except Exception as e:
+    res = 2
+`
+    env := testRunProgram(input)
+    if res := env.GetFromString("res"); res.(*evaluator.IntegerInst).Value != 2 {
+        t.Errorf("expect 2, got %v", evaluator.StringOf(res))
+    }
+}
+
+func TestTryFinallyOnReturn(t *testing.T) {
+    input := `
+res = 0
+def foo():
+    try:
+        return 1
+    finally:
+        res.append(2)
+
+res = []
+val = foo()
+`
+    env := testRunProgram(input)
+    if res := env.GetFromString("val"); res.(*evaluator.IntegerInst).Value != 1 {
+        t.Errorf("expect 1, got %v", evaluator.StringOf(res))
+    }
+    if res := env.GetFromString("res"); evaluator.StringOf(res).(*evaluator.StringInst).Value != "[2]" {
+        t.Errorf("expect [2], got %v", evaluator.StringOf(res))
+    }
+}
+
+func TestUncaughtExceptionInTry(t *testing.T) {
+    defer func() {
+        if r := recover(); r != nil {
+            if _, ok := r.(*evaluator.ExceptionInst); !ok {
+                t.Errorf("expected exception, got %v", r)
+            }
+        } else {
+            t.Errorf("expected exception to propagate")
+        }
+    } ()
+
+    testRunProgram(`
+try:
+    1 / 0
+except StopIteration:
+    res = 1
+`)
+}
+
+func TestFunctionArguments(t *testing.T) {
+    input := `
+def foo(a, b=2, *args, c, **kwargs):
+    return a + b + len(args) + c + kwargs['d']
+
+res = foo(1, *[10, 20], c=3, **{'d': 4})
+`
+    env := testRunProgram(input)
+    if res := env.GetFromString("res"); res.(*evaluator.IntegerInst).Value != 19 {
+        t.Errorf("expect 19, got %v", evaluator.StringOf(res))
+    }
+}
+
+func TestTupleUnpacking(t *testing.T) {
+    input := `
+a, b = 1, 2
+a, b = b, a
+res = []
+for k, *v in [(a, b, 3)]:
+    res = v
+`
+    env := testRunProgram(input)
+    if res := env.GetFromString("a"); res.(*evaluator.IntegerInst).Value != 2 {
+        t.Errorf("expect 2, got %v", evaluator.StringOf(res))
+    }
+    if res := env.GetFromString("res"); evaluator.StringOf(res).(*evaluator.StringInst).Value != "[1, 3]" {
+        t.Errorf("expect [1, 3], got %v", evaluator.StringOf(res))
+    }
+}
+
+func TestFLOORDIVASSIGN(t *testing.T) {
+    input := `
+val = 7
+val //= 2
+`
+    env := testRunProgram(input)
+    if obj := env.GetFromString("val").(*evaluator.IntegerInst); obj.Value != 3 {
+        t.Errorf("expect 3, got %v", obj.Value)
+    }
+}
+
+func TestOperatorPrecedence(t *testing.T) {
+    testCases := []struct {
+        input       string
+        expected    int64
+    }{
+        {"res = -2 ** 2", -4},
+        {"res = 2 ** 3 ** 2", 512},
+        {"res = 1 + 2 * 3 % 4", 3},
+        {"res = 1 | 6 ^ 3 & 5", 7},
+        {"res = 1 + 1 << 2", 8},
+        {"res = ~-1", 0},
+    }
+
+    for _, testCase := range testCases {
+        env := testRunProgram(testCase.input)
+        if obj := env.GetFromString("res").(*evaluator.IntegerInst); obj.Value != testCase.expected {
+            t.Errorf("%v: expect %v, got %v", testCase.input, testCase.expected, obj.Value)
+        }
+    }
+}
+
+func TestFloatArithmetic(t *testing.T) {
+    input := `
+res = 1 + 0.5 * 3
+`
+    env := testRunProgram(input)
+    if obj := env.GetFromString("res").(*evaluator.FloatInst); obj.Value != 2.5 {
+        t.Errorf("expect 2.5, got %v", obj.Value)
+    }
+}
+
+func TestIntegerOverflow(t *testing.T) {
+    input := `
+res = 9223372036854775807 * 10 + 5
+`
+    env := testRunProgram(input)
+    if obj := env.GetFromString("res").(*evaluator.IntegerInst); obj.String() != "92233720368547758075" {
+        t.Errorf("expect 92233720368547758075, got %v", obj.String())
+    }
+}
+
+func TestGenerator(t *testing.T) {
+    input := `
+def gen():
+    x = yield 1
+    yield x * 2
+g = gen()
+first = next(g)
+second = g.send(21)
+`
+    env := testRunProgram(input)
+    if res := env.GetFromString("first"); res.(*evaluator.IntegerInst).Value != 1 {
+        t.Errorf("expect 1, got %v", evaluator.StringOf(res))
+    }
+    if res := env.GetFromString("second"); res.(*evaluator.IntegerInst).Value != 42 {
+        t.Errorf("expect 42, got %v", evaluator.StringOf(res))
+    }
+}
+
+func TestListComprehension(t *testing.T) {
+    input := `
+x = 'outer'
+res = [x * x for x in range(5) if x % 2 == 0]
+`
+    env := testRunProgram(input)
+    if res := env.GetFromString("res"); evaluator.StringOf(res).(*evaluator.StringInst).Value != "[0, 4, 16]" {
+        t.Errorf("expect [0, 4, 16], got %v", evaluator.StringOf(res))
+    }
+    if res := env.GetFromString("x"); res.(*evaluator.StringInst).Value != "outer" {
+        t.Errorf("expect 'outer', got %v", evaluator.StringOf(res))
+    }
+}
+
+func TestLambda(t *testing.T) {
+    input := `
+pairs = [(1, 'b'), (3, 'a'), (2, 'c')]
+res = sorted(pairs, key=lambda p: p[1])[0][0]
+`
+    env := testRunProgram(input)
+    if res := env.GetFromString("res"); res.(*evaluator.IntegerInst).Value != 3 {
+        t.Errorf("expect 3, got %v", evaluator.StringOf(res))
+    }
+}
+
+func TestNonlocal(t *testing.T) {
+    input := `
+def counter():
+    n = 0
+    def inc():
+        nonlocal n
+        n += 1
+    inc()
+    inc()
+    return n
+res = counter()
+`
+    env := testRunProgram(input)
+    if res := env.GetFromString("res"); res.(*evaluator.IntegerInst).Value != 2 {
+        t.Errorf("expect 2, got %v", evaluator.StringOf(res))
+    }
+}
+
+func TestDecorator(t *testing.T) {
+    input := `
+def twice(func):
+    def wrapper(x):
+        return func(func(x))
+    return wrapper
+def add(n):
+    def decorator(func):
+        return lambda x: func(x) + n
+    return decorator
+@twice
+@add(1)
+def double(x):
+    return x * 2
+res = double(3)
+`
+    env := testRunProgram(input)
+    if res := env.GetFromString("res"); res.(*evaluator.IntegerInst).Value != 15 {
+        t.Errorf("expect 15, got %v", evaluator.StringOf(res))
+    }
+}
+
+func TestMultipleInheritance(t *testing.T) {
+    input := `
+class A:
+    def who(self):
+        return 'A'
+class B(A):
+    'b'
+class C(A):
+    def who(self):
+        return 'C'
+class D(B, C):
+    'd'
+res = D().who()
+mro = len(D.__mro__)
+`
+    env := testRunProgram(input)
+    if res := env.GetFromString("res"); res.(*evaluator.StringInst).Value != "C" {
+        t.Errorf("expect 'C', got %v", evaluator.StringOf(res))
+    }
+    if res := env.GetFromString("mro"); res.(*evaluator.IntegerInst).Value != 5 {
+        t.Errorf("expect 5, got %v", evaluator.StringOf(res))
+    }
+}
+
+func TestSuper(t *testing.T) {
+    input := `
+class A:
+    def name(self):
+        return 'A'
+class B(A):
+    def name(self):
+        return 'B' + super().name()
+class C(A):
+    def name(self):
+        return 'C' + super().name()
+class D(B, C):
+    def name(self):
+        return 'D' + super(D, self).name()
+res = D().name()
+`
+    env := testRunProgram(input)
+    if res := env.GetFromString("res"); res.(*evaluator.StringInst).Value != "DBCA" {
+        t.Errorf("expect 'DBCA', got %v", evaluator.StringOf(res))
+    }
+}
+
+func TestProperty(t *testing.T) {
+    input := `
+class Circle:
+    def __init__(self, r):
+        self.r = r
+    @property
+    def diameter(self):
+        return self.r * 2
+    @diameter.setter
+    def diameter(self, d):
+        self.r = d // 2
+    @classmethod
+    def unit(cls):
+        return cls(1)
+c = Circle.unit()
+c.diameter = 10
+res = c.r
+`
+    env := testRunProgram(input)
+    if res := env.GetFromString("res"); res.(*evaluator.IntegerInst).Value != 5 {
+        t.Errorf("expect 5, got %v", evaluator.StringOf(res))
+    }
+}
+
+func TestDunder(t *testing.T) {
+    input := `
+class Stack(list):
+    def __getattr__(self, name):
+        return len(self)
+s = Stack([1, 2, 3])
+res = s.size
+`
+    env := testRunProgram(input)
+    if res := env.GetFromString("res"); res.(*evaluator.IntegerInst).Value != 3 {
+        t.Errorf("expect 3, got %v", evaluator.StringOf(res))
+    }
+}
+
+func TestDel(t *testing.T) {
+    input := `
+xs = [0, 1, 2, 3]
+d = {'a': 1, 'b': 2}
+x = 1
+del xs[1:3], d['a'], x
+res = len(xs) + len(d)
+`
+    env := testRunProgram(input)
+    if res := env.GetFromString("res"); res.(*evaluator.IntegerInst).Value != 3 {
+        t.Errorf("expect 3, got %v", evaluator.StringOf(res))
+    }
+    if x := env.GetFromString("x"); x != nil {
+        t.Errorf("expect x to be deleted, got %v", evaluator.StringOf(x))
+    }
+}
+
+func TestSet(t *testing.T) {
+    input := `
+a = {1, 2, 3}
+b = frozenset([2, 3, 4])
+c = a | b
+c.discard(1)
+res = len(c - (a & b)) + len({frozenset([1]), frozenset([1])})
+`
+    env := testRunProgram(input)
+    if res := env.GetFromString("res"); res.(*evaluator.IntegerInst).Value != 2 {
+        t.Errorf("expect 2, got %v", evaluator.StringOf(res))
+    }
+}
+
+func TestStrMethods(t *testing.T) {
+    input := `
+words = '  a-b c  '.strip().replace('-', ' ').split()
+res = '+'.join(words).upper().center(7, '*') + 'x' * 2
+`
+    env := testRunProgram(input)
+    if res := env.GetFromString("res"); res.(*evaluator.StringInst).Value != "*A+B+C*xx" {
+        t.Errorf("expect *A+B+C*xx, got %v", evaluator.StringOf(res))
+    }
+}
+
+func TestUnicode(t *testing.T) {
+    input := `
+s = 'héllo wörld'
+res = [len(s), s[1], s[-4:], ord('€'), chr(960)]
+`
+    env := testRunProgram(input)
+    if res := evaluator.StringOf(env.GetFromString("res")).(*evaluator.StringInst).Value; res != "[11, 'é', 'örld', 8364, 'π']" {
+        t.Errorf("expect [11, 'é', 'örld', 8364, 'π'], got %v", res)
+    }
+}
+
+func TestBytesLiteral(t *testing.T) {
+    input := `
+data = 'tab\there'.encode() + b'\xc3\xa9'
+res = [len(data), data[3], data.decode()]
+`
+    env := testRunProgram(input)
+    if res := evaluator.ReprOf(env.GetFromString("res")).(*evaluator.StringInst).Value; res != "[10, 9, 'tab\\thereé']" {
+        t.Errorf("expect [10, 9, 'tab\\thereé'], got %v", res)
+    }
+}
+
+func TestFormatting(t *testing.T) {
+    input := `
+width = 6
+item = 'tea'
+res = [f'{item!r:>{width}}|{width=}', '{0:<5}|{0:^+7.2f}'.format(2.5), '%-4s|%03d' % (item, 7)]
+`
+    env := testRunProgram(input)
+    expect := `[" 'tea'|width=6", '2.5  | +2.50 ', 'tea |007']`
+    if res := evaluator.ReprOf(env.GetFromString("res")).(*evaluator.StringInst).Value; res != expect {
+        t.Errorf("expect %v, got %v", expect, res)
+    }
+}
+
+func TestAbandonedGenerator(t *testing.T) {
+    before := runtime.NumGoroutine()
+    input := `
+closed = []
+def gen():
+    try:
+        yield 1
+        yield 2
+    finally:
+        closed.append(1)
+for i in range(100):
+    g = gen()
+    next(g)
+del g
+`
+    env := testRunProgram(input)
+
+    // the collected generators are closed when the next one is made
+    for i := 0; i < 100 && runtime.NumGoroutine() > before; i++ {
+        runtime.GC()
+        time.Sleep(10 * time.Millisecond)
+        testRunProgram("def gen():\n    yield 1\ngen()\n")
+    }
+    if n := runtime.NumGoroutine(); n > before {
+        t.Errorf("expect %v goroutines, got %v", before, n)
+    }
+    if res := evaluator.StringOf(env.GetFromString("closed")).(*evaluator.StringInst).Value; len(res) != 300 {
+        t.Errorf("expect every generator closed, got %v", res)
+    }
+}
+
 func testRunProgram(input string) *evaluator.Environment{
     l := lexer.New(input)
     p := parser.New(l)
@@ -0,0 +1,45 @@
+def factorial(n):
+    result = 1
+    i = 2
+    while i < n + 1:
+        result = result * i
+        i = i + 1
+    return result
+
+assert factorial(20) == 2432902008176640000
+assert factorial(25) == 15511210043330985984000000
+assert str(factorial(30)) == '265252859812191058636308480000000'
+assert factorial(30) // factorial(28) == 870
+
+
+def fibonacci(n):
+    a, b = 0, 1
+    for i in range(n):
+        a, b = b, a + b
+    return a
+
+assert fibonacci(100) == 354224848179261915075
+
+
+big = 123456789012345678901234567890
+assert big == int('123456789012345678901234567890')
+assert big + 1 == 123456789012345678901234567891
+assert big - big == 0
+assert big > 9223372036854775807
+assert 9223372036854775807 < big
+assert 9223372036854775807 + 1 == 9223372036854775808
+assert -9223372036854775807 - 2 == -9223372036854775809
+assert type(big) is int
+
+assert hash(big) == hash(int('123456789012345678901234567890'))
+assert hash(9223372036854775808 - 1) == hash(9223372036854775807)
+assert hash(100000000000000000000) == hash(1e20)
+d = {big: 'big'}
+assert d[123456789012345678901234567890] == 'big'
+
+assert big // 10 == 12345678901234567890123456789
+assert -big // 10 == -12345678901234567890123456789
+assert big // -7 == -17636684144620811271604938270
+assert big / big == 1.0
+assert 100000000000000000000 / 10 == 1e19
+assert int(1e20) == 100000000000000000000
@@ -0,0 +1,123 @@
+# literals
+b = b'abc'
+assert type(b) is bytes
+assert len(b) == 3
+assert b[0] == 97
+assert b[-1] == 99
+assert b[1:] == b'bc'
+assert b[::-1] == b'cba'
+assert list(b) == [97, 98, 99]
+assert b'\x00\xff' == bytes([0, 255])
+assert rb'\x00' == b'\\x00'
+assert B'a' b'b' == b'ab'
+assert len(b'''a
+b''') == 3
+
+# construction
+assert bytes() == b''
+assert bytes(3) == b'\x00\x00\x00'
+assert bytes([104, 105]) == b'hi'
+assert bytes(range(3)) == b'\x00\x01\x02'
+assert bytes(b'x') == b'x'
+assert bytes('hé', 'utf-8') == b'h\xc3\xa9'
+try:
+    bytes('a')
+    assert False
+except TypeError as e:
+    assert str(e) == 'string argument without an encoding'
+try:
+    bytes([256])
+    assert False
+except ValueError as e:
+    assert str(e) == 'bytes must be in range(0, 256)'
+try:
+    bytes(-1)
+    assert False
+except ValueError as e:
+    assert str(e) == 'negative count'
+
+# operators
+assert b'ab' + b'c' == b'abc'
+assert b'ab' * 2 == b'abab'
+assert 2 * b'-' == b'--'
+assert 98 in b'abc'
+assert b'bc' in b'abc'
+assert b'ac' not in b'abc'
+assert b'a' < b'b'
+assert b'ab' > b'a'
+assert b'a' != 'a'
+assert hash(b'ab') == hash(b'a' + b'b')
+assert {b'k': 1}[b'k'] == 1
+try:
+    b'a' + 'b'
+    assert False
+except TypeError as e:
+    assert str(e) == "can't concat str to bytes"
+try:
+    'a' in b'abc'
+    assert False
+except TypeError as e:
+    assert str(e) == "a bytes-like object is required, not 'str'"
+try:
+    b'a'[1]
+    assert False
+except IndexError as e:
+    assert str(e) == 'index out of range'
+
+# repr
+assert repr(b'abc') == "b'abc'"
+assert str(b'x') == "b'x'"
+assert repr(b'\x00\n\x7f') == "b'\\x00\\n\\x7f'"
+assert repr(b"it's") == 'b"it\'s"'
+
+# methods
+assert b'abc'.hex() == '616263'
+assert b', '.join([b'a', b'b']) == b'a, b'
+assert b'abc'.startswith(b'ab')
+assert b'abc'.endswith((b'x', b'c'))
+assert b'abc'.find(b'c') == 2
+assert b'abc'.find(120) == -1
+assert b'aab'.count(b'a') == 2
+
+# encoding and decoding
+assert 'hé'.encode() == b'h\xc3\xa9'
+assert 'hé'.encode('latin-1') == b'h\xe9'
+assert 'hé'.encode('ascii', 'replace') == b'h?'
+assert 'hé'.encode('ascii', 'ignore') == b'h'
+assert b'h\xc3\xa9'.decode() == 'hé'
+assert b'h\xe9'.decode('latin-1') == 'hé'
+assert b'a\xffb'.decode('utf-8', 'ignore') == 'ab'
+assert b'a\xffb'.decode(errors='replace') == 'a�b'
+try:
+    b'\xff'.decode()
+    assert False
+except UnicodeDecodeError as e:
+    assert str(e) == "'utf-8' codec can't decode byte 0xff in position 0: invalid start byte"
+try:
+    b'a\xc3'.decode()
+    assert False
+except UnicodeDecodeError as e:
+    assert str(e) == "'utf-8' codec can't decode byte 0xc3 in position 1: unexpected end of data"
+try:
+    'é'.encode('ascii')
+    assert False
+except UnicodeEncodeError as e:
+    assert str(e) == "'ascii' codec can't encode character '\\xe9' in position 0: ordinal not in range(128)"
+try:
+    'a'.encode('nope')
+    assert False
+except LookupError as e:
+    assert str(e) == 'unknown encoding: nope'
+assert issubclass(UnicodeDecodeError, ValueError)
+
+def arity(fn, *args):
+    try:
+        fn(*args)
+    except TypeError as e:
+        return str(e)
+
+assert arity(b'a'.__add__) == 'expected 1 argument, got 0'
+assert arity(b'a'.__eq__) == 'expected 1 argument, got 0'
+assert arity(b'a'.__getitem__) == 'expected 1 argument, got 0'
+assert arity(b'a'.__len__, 1) == 'expected 0 arguments, got 1'
+assert arity(b'a'.__hash__, 1) == 'expected 0 arguments, got 1'
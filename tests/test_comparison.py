@@ -0,0 +1,68 @@
+assert 1 <= 1
+assert 1 <= 2
+assert not 2 <= 1
+assert 2 >= 2
+assert 1.5 >= 1
+assert 2 <= 2.5
+assert 'a' <= 'b' <= 'b'
+assert [1, 2] <= [1, 2]
+assert [1] < [1, 0]
+assert [2] > [1, 5]
+assert (1, 3) >= (1, 2, 9)
+assert 123456789012345678901234567890 >= 123456789012345678901234567890
+
+
+x = 5
+assert 0 < x < 10
+assert not 0 < x > 10
+assert 1 == 1 != 2
+assert 1 in [1] in [[1]]
+assert not (1 < 2) < 1
+assert (1 < 2) < 2
+assert not 1 < 2 < 2
+
+
+calls = []
+
+def f(n):
+    calls.append(n)
+    return n
+
+assert f(1) < f(2) <= f(3)
+assert calls == [1, 2, 3]
+
+calls = []
+assert not f(3) < f(2) < f(1)
+assert calls == [3, 2]
+
+
+def catch(fn):
+    try:
+        fn()
+    except BaseException as e:
+        return type(e)
+    return None
+
+def le_mixed():
+    return 1 <= 'a'
+
+def ge_mixed():
+    return [1] >= (1,)
+
+assert catch(le_mixed) is TypeError
+assert catch(ge_mixed) is TypeError
+
+
+class Version:
+    def __init__(self, n):
+        self.n = n
+
+    def __le__(self, other):
+        return self.n <= other.n
+
+    def __ge__(self, other):
+        return self.n >= other.n
+
+assert Version(1) <= Version(2)
+assert Version(3) >= Version(2) >= Version(2)
+assert not Version(3) <= Version(2)
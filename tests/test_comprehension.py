@@ -0,0 +1,87 @@
+xs = [1, 2, 3, 4, 5, 6]
+
+assert [x * 2 for x in xs] == [2, 4, 6, 8, 10, 12]
+assert [x for x in xs if x % 2 == 0] == [2, 4, 6]
+assert [x for x in xs if x > 1 if x < 4] == [2, 3]
+assert [(a, b) for a in range(3) for b in range(a)] == [(1, 0), (2, 0), (2, 1)]
+assert [y for row in [[1, 2], [3]] for y in row] == [1, 2, 3]
+assert [x for x in []] == []
+assert [[c for c in w] for w in ['ab', 'cd']] == [['a', 'b'], ['c', 'd']]
+assert [a + b for a, b in [(1, 2), (3, 4)]] == [3, 7]
+assert [first for first, *rest in [(1, 2, 3)]] == [1]
+
+m = [[1, 2], [3, 4]]
+assert [[row[i] for row in m] for i in range(2)] == [[1, 3], [2, 4]]
+
+# multiline
+assert [
+    x
+    for x in xs
+    if x > 4
+] == [5, 6]
+
+
+d = {k: k * k for k in range(4)}
+assert len(d) == 4
+assert d[3] == 9
+d = {v: k for k, v in [('a', 1), ('b', 2)]}
+assert d[2] == 'b'
+
+
+s = {x % 3 for x in xs}
+assert len(s) == 3
+assert s == set([0, 1, 2])
+assert 2 in s
+assert 5 not in s
+assert {x for x in []} == set()
+
+
+# generator expressions are lazy
+log = []
+def note(x):
+    log.append(x)
+    return x
+
+g = (note(x) for x in xs)
+assert log == []
+assert next(g) == 1
+assert log == [1]
+assert list(g) == [2, 3, 4, 5, 6]
+assert type(g).__name__ == 'generator'
+
+assert max(x * 2 for x in xs) == 12
+assert list(x for x in range(3) if x) == [1, 2]
+assert tuple((a, b) for a in 'ab' for b in 'c') == (('a', 'c'), ('b', 'c'))
+
+# but the first iterable is evaluated right away
+try:
+    (x for x in 1)
+    assert False
+except TypeError:
+    x = 1
+
+
+# the loop variables don't leak
+x = 'outer'
+ys = [x for x in range(3)]
+assert x == 'outer'
+ys = {x: x for x in range(3)}
+assert x == 'outer'
+ys = list(x for x in range(3))
+assert x == 'outer'
+
+def f():
+    [inner for inner in range(3)]
+    return inner
+try:
+    f()
+    assert False
+except NameError:
+    x = 1
+
+# while the enclosing scope is visible
+n = 10
+def scaled(xs):
+    k = 3
+    return [x * k + n for x in xs]
+assert scaled([1, 2]) == [13, 16]
@@ -0,0 +1,167 @@
+def memoize(func):
+    cache = {}
+    def wrapper(n):
+        if n not in cache:
+            cache[n] = func(n)
+        return cache[n]
+    wrapper.cache = cache
+    return wrapper
+
+calls = []
+
+@memoize
+def fib(n):
+    calls.append(n)
+    if n < 2:
+        return n
+    return fib(n-1) + fib(n-2)
+
+assert fib(30) == 832040
+assert len(calls) == 31
+assert len(fib.cache) == 31
+assert fib.__name__ == 'wrapper'
+
+
+# registration, the decorator giving back what it's given
+registry = {}
+
+def register(name):
+    def decorator(func):
+        registry[name] = func
+        return func
+    return decorator
+
+@register('add')
+def add(a, b):
+    return a + b
+
+@register('sub')
+def sub(a, b):
+    return a - b
+
+assert registry['add'](1, 2) == 3
+assert registry['sub'](5, 2) == 3
+assert registry['add'] is add
+
+
+# decorators are evaluated top-down and applied bottom-up
+order = []
+
+def tag(label):
+    order.append('eval ' + label)
+    def decorator(func):
+        order.append('apply ' + label)
+        def wrapper(*args, **kwargs):
+            return label + '(' + func(*args, **kwargs) + ')'
+        return wrapper
+    return decorator
+
+@tag('a')
+
+@tag('b')
+def greet(name, punct='!'):
+    return name + punct
+
+assert order == ['eval a', 'eval b', 'apply b', 'apply a']
+assert greet('x') == 'a(b(x!))'
+assert greet('y', punct='?') == 'a(b(y?))'
+
+
+# any expression is allowed as a decorator
+class Tracer:
+    def __init__(self):
+        self.log = []
+    def trace(self, func):
+        def wrapper(*args):
+            self.log.append(func.__name__)
+            return func(*args)
+        return wrapper
+
+tracer = Tracer()
+
+@tracer.trace
+def square(x):
+    return x * x
+
+@(lambda f: f)
+def ident(x):
+    return x
+
+assert square(3) == 9
+assert ident(4) == 4
+assert tracer.log == ['square']
+
+
+# the decorated name isn't bound until the decorators are done
+def replace(func):
+    return 'replaced'
+
+@replace
+def f():
+    return 1
+
+assert f == 'replaced'
+
+
+# class decorators
+def add_hello(cls):
+    cls.hello = lambda self: 'hello from ' + self.name
+    return cls
+
+classes = []
+
+def collect(cls):
+    classes.append(cls)
+    return cls
+
+@collect
+@add_hello
+class Person:
+    def __init__(self, name):
+        self.name = name
+
+assert Person('bob').hello() == 'hello from bob'
+assert classes == [Person]
+
+
+# decorators on methods and nested functions
+def double(func):
+    def wrapper(*args):
+        return func(*args) * 2
+    return wrapper
+
+class Number:
+    def __init__(self, n):
+        self.n = n
+    @double
+    def value(self):
+        return self.n
+
+assert Number(5).value() == 10
+
+def outer():
+    @double
+    def inner(x):
+        return x + 1
+    return inner(1)
+
+assert outer() == 4
+
+
+# an exception in a decorator propagates
+def failing(func):
+    raise ValueError('bad decorator')
+
+try:
+    @failing
+    def g():
+        return 1
+    assert False
+except ValueError as e:
+    assert str(e) == 'bad decorator'
+
+try:
+    g
+    assert False
+except NameError as e:
+    assert str(e) == "name 'g' is not defined"
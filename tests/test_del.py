@@ -0,0 +1,170 @@
+# names
+x = 1
+del x
+try:
+    x
+    assert False
+except NameError as e:
+    assert str(e) == "name 'x' is not defined"
+
+try:
+    del x
+    assert False
+except NameError as e:
+    assert str(e) == "name 'x' is not defined"
+
+# deleting a global shadowing a builtin brings the builtin back
+len = 5
+del len
+assert len([1, 2]) == 2
+
+a, b, c = 1, 2, 3
+del a, b
+assert c == 3
+try:
+    a
+    assert False
+except NameError as e:
+    assert str(e) == "name 'a' is not defined"
+
+def local():
+    y = 1
+    del y
+    return y
+
+try:
+    local()
+    assert False
+except UnboundLocalError as e:
+    assert str(e) == "cannot access local variable 'y' where it is not associated with a value"
+
+def unbound():
+    del z
+
+try:
+    unbound()
+    assert False
+except UnboundLocalError as e:
+    assert str(e) == "cannot access local variable 'z' where it is not associated with a value"
+
+g = 1
+def delete_global():
+    global g
+    del g
+
+delete_global()
+try:
+    g
+    assert False
+except NameError as e:
+    assert str(e) == "name 'g' is not defined"
+
+def outer():
+    n = 1
+    def inner():
+        nonlocal n
+        del n
+    inner()
+    try:
+        n
+        return False
+    except NameError:
+        return True
+
+assert outer()
+
+# dict keys
+d = {'a': 1, 'b': 2, 'c': 3}
+del d['a']
+assert d == {'b': 2, 'c': 3}
+del d['b'], d['c']
+assert len(d) == 0
+try:
+    del d['missing']
+    assert False
+except KeyError as e:
+    assert str(e) == "'missing'"
+
+# list items and slices
+xs = [0, 1, 2, 3, 4, 5, 6, 7]
+del xs[0]
+assert xs == [1, 2, 3, 4, 5, 6, 7]
+del xs[-1]
+assert xs == [1, 2, 3, 4, 5, 6]
+del xs[1:3]
+assert xs == [1, 4, 5, 6]
+del xs[::2]
+assert xs == [4, 6]
+del xs[:]
+assert xs == []
+try:
+    del xs[0]
+    assert False
+except IndexError as e:
+    assert str(e) == 'list assignment index out of range'
+
+try:
+    del (1, 2)[0]
+    assert False
+except TypeError as e:
+    assert str(e) == "'tuple' object doesn't support item deletion"
+
+# attributes
+class C:
+    k = 1
+
+c = C()
+c.a = 1
+del c.a
+try:
+    c.a
+    assert False
+except AttributeError as e:
+    assert str(e) == "'C' object has no attribute 'a'"
+
+try:
+    del c.a
+    assert False
+except AttributeError as e:
+    assert str(e) == "'C' object has no attribute 'a'"
+
+del C.k
+try:
+    C.k
+    assert False
+except AttributeError as e:
+    assert str(e) == "type object 'C' has no attribute 'k'"
+
+try:
+    del C.k
+    assert False
+except AttributeError as e:
+    assert str(e) == "type object 'C' has no attribute 'k'"
+
+# user-defined __delitem__ and __delattr__
+class Log:
+    def __init__(self):
+        self.log = []
+
+    def __delitem__(self, key):
+        self.log.append(('item', key))
+
+    def __delattr__(self, name):
+        self.log.append(('attr', name))
+
+lg = Log()
+del lg[1], lg[2:], lg.x
+assert lg.log[0] == ('item', 1)
+assert lg.log[2] == ('attr', 'x')
+
+# tuple and list targets, nested
+p, q, r = [1], {'k': 1}, C()
+r.v = 1
+del (p[0], q['k']), [r.v]
+assert p == [] and q == {} and not hasattr(r, 'v')
+
+# a deleted name can be bound again
+v = 1
+del v
+v = 2
+assert v == 2
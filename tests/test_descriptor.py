@@ -0,0 +1,218 @@
+# property
+class Temperature:
+    def __init__(self, celsius):
+        self._celsius = celsius
+
+    @property
+    def celsius(self):
+        return self._celsius
+
+    @celsius.setter
+    def celsius(self, value):
+        if value < -273:
+            raise ValueError('too cold')
+        self._celsius = value
+
+    @celsius.deleter
+    def celsius(self):
+        self._celsius = None
+
+    @property
+    def fahrenheit(self):
+        return self._celsius * 9 / 5 + 32
+
+t = Temperature(100)
+assert t.celsius == 100
+assert t.fahrenheit == 212.0
+t.celsius = 0
+assert t.celsius == 0
+assert t.fahrenheit == 32.0
+
+try:
+    t.celsius = -300
+    assert False
+except ValueError as e:
+    assert str(e) == 'too cold'
+assert t.celsius == 0
+
+t.__delattr__('celsius')
+assert t.celsius is None
+
+try:
+    t.fahrenheit = 1
+    assert False
+except AttributeError as e:
+    assert str(e) == "property 'fahrenheit' of 'Temperature' object has no setter"
+
+try:
+    t.__delattr__('fahrenheit')
+    assert False
+except AttributeError as e:
+    assert str(e) == "property 'fahrenheit' of 'Temperature' object has no deleter"
+
+# got from the class, a property is itself
+assert type(Temperature.celsius) is property
+assert isinstance(Temperature.fahrenheit, property)
+assert Temperature.celsius.fset is not None
+assert Temperature.fahrenheit.fset is None
+
+
+# property() called directly
+class Box:
+    def get_size(self):
+        return self._size
+    def set_size(self, value):
+        self._size = value * 2
+    size = property(get_size, set_size)
+    width = property(fget=get_size)
+    empty = property()
+
+b = Box()
+b.size = 3
+assert b.size == 6
+assert b.width == 6
+
+try:
+    b.empty
+    assert False
+except AttributeError as e:
+    assert str(e) == "property 'empty' of 'Box' object has no getter"
+
+
+# a data descriptor wins over the instance dict, a non-data one doesn't
+class Data:
+    def __get__(self, obj, owner):
+        return 'data'
+    def __set__(self, obj, value):
+        obj.stored = value
+
+class NonData:
+    def __get__(self, obj, owner):
+        if obj is None:
+            return 'from class'
+        return 'non-data'
+
+class Holder:
+    d = Data()
+    n = NonData()
+
+h = Holder()
+assert h.d == 'data'
+assert h.n == 'non-data'
+assert Holder.n == 'from class'
+
+h.d = 5
+assert h.stored == 5
+assert h.d == 'data'
+
+h.n = 'shadowed'
+assert h.n == 'shadowed'
+
+
+# descriptors learn their name through __set_name__
+class Field:
+    def __set_name__(self, owner, name):
+        self.name = '_' + name
+    def __get__(self, obj, owner):
+        if obj is None:
+            return self
+        return getattr_or(obj, self.name)
+    def __set__(self, obj, value):
+        if value < 0:
+            raise ValueError(self.name + ' must not be negative')
+        obj.__dict_set__(self.name, value)
+    def __delete__(self, obj):
+        obj.__dict_set__(self.name, 'deleted')
+
+def getattr_or(obj, name):
+    return obj.values[name]
+
+class Point:
+    x = Field()
+    y = Field()
+    def __init__(self, x, y):
+        self.values = {}
+        self.x = x
+        self.y = y
+    def __dict_set__(self, name, value):
+        self.values[name] = value
+
+p = Point(1, 2)
+assert p.x == 1
+assert p.y == 2
+assert Point.x.name == '_x'
+try:
+    p.y = -1
+    assert False
+except ValueError as e:
+    assert str(e) == '_y must not be negative'
+p.__delattr__('x')
+assert p.x == 'deleted'
+
+
+# staticmethod and classmethod
+class Shape:
+    count = 0
+
+    def __init__(self, sides):
+        self.sides = sides
+
+    @staticmethod
+    def describe(n):
+        return str(n) + ' sides'
+
+    @classmethod
+    def triangle(cls):
+        cls.count += 1
+        return cls(3)
+
+    @classmethod
+    def kind(cls):
+        return cls.__name__
+
+class Square(Shape):
+    def __init__(self):
+        super().__init__(4)
+
+    @classmethod
+    def kind(cls):
+        return 'square ' + super().kind()
+
+assert Shape.describe(3) == '3 sides'
+assert Shape(5).describe(5) == '5 sides'
+
+tri = Shape.triangle()
+assert type(tri) is Shape
+assert tri.sides == 3
+assert Shape.count == 1
+assert Shape(1).triangle().sides == 3
+assert Shape.count == 2
+
+assert Shape.kind() == 'Shape'
+assert Square.kind() == 'square Square'
+assert Square().kind() == 'square Square'
+
+sm = staticmethod(len)
+assert sm('abc') == 3
+assert sm.__func__ is len
+cm = classmethod(Shape.describe)
+assert cm.__func__ is Shape.describe
+
+
+# object.__delattr__
+class Plain:
+    'pass'
+
+pl = Plain()
+pl.a = 1
+pl.__delattr__('a')
+try:
+    pl.a
+    assert False
+except AttributeError as e:
+    assert str(e) == "'Plain' object has no attribute 'a'"
+try:
+    pl.__delattr__('a')
+    assert False
+except AttributeError as e:
+    assert str(e) == "'Plain' object has no attribute 'a'"
@@ -0,0 +1,319 @@
+# __bool__ and the __len__ fallback
+class Empty:
+    def __len__(self):
+        return 0
+
+class Full:
+    def __len__(self):
+        return 3
+
+class No:
+    def __bool__(self):
+        return False
+
+assert not Empty()
+assert Full()
+assert not No()
+assert bool(Full()) is True
+assert bool() is False
+
+ok = False
+if Full():
+    ok = True
+assert ok
+
+n = 0
+e = Empty()
+while e:
+    n = n + 1
+assert n == 0
+
+class BadBool:
+    def __bool__(self):
+        return 1
+
+try:
+    bool(BadBool())
+    assert False
+except TypeError as e:
+    assert str(e) == '__bool__ should return bool, returned int'
+
+class Negative:
+    def __len__(self):
+        return -1
+
+try:
+    len(Negative())
+    assert False
+except ValueError as e:
+    assert str(e) == '__len__() should return >= 0'
+
+# and/or give an operand and short-circuit
+calls = []
+def mark(v):
+    calls.append(v)
+    return v
+
+assert (mark(0) and mark(1)) == 0
+assert (mark(2) or mark(3)) == 2
+assert calls == [0, 2]
+assert (Empty() or 5) == 5
+
+# __contains__, and the iteration fallback of in
+class Evens:
+    def __contains__(self, x):
+        return x % 2 == 0
+
+assert 4 in Evens()
+assert 3 not in Evens()
+
+class Squares:
+    def __getitem__(self, i):
+        if i >= 5:
+            raise IndexError(i)
+        return i * i
+
+assert 16 in Squares()
+assert 15 not in Squares()
+assert list(Squares()) == [0, 1, 4, 9, 16]
+
+total = 0
+for x in Squares():
+    total = total + x
+assert total == 30
+
+it = iter(Squares())
+assert next(it) == 0
+assert next(it) == 1
+
+class NotIterable:
+    'pass'
+
+try:
+    1 in NotIterable()
+    assert False
+except TypeError as e:
+    assert str(e) == "argument of type 'NotIterable' is not iterable"
+
+# repr and str
+class Point:
+    def __init__(self, x, y):
+        self.x = x
+        self.y = y
+
+    def __repr__(self):
+        return 'Point(' + str(self.x) + ', ' + str(self.y) + ')'
+
+p = Point(1, 2)
+assert repr(p) == 'Point(1, 2)'
+assert str(p) == 'Point(1, 2)'
+assert str([p]) == '[Point(1, 2)]'
+assert repr('a') == "'a'"
+assert str(['a', 1]) == "['a', 1]"
+assert str({'a': 1}) == "{'a': 1}"
+assert repr(int) == "<class 'int'>"
+
+class Named:
+    def __str__(self):
+        return 'named'
+
+assert str(Named()) == 'named'
+
+class BadStr:
+    def __str__(self):
+        return 1
+
+try:
+    str(BadStr())
+    assert False
+except TypeError as e:
+    assert str(e) == '__str__ returned non-string (type int)'
+
+# __getattr__ is called only when the lookup fails
+class Default:
+    def __init__(self):
+        self.real = 1
+
+    def __getattr__(self, name):
+        if name == 'missing':
+            raise AttributeError(name)
+        return name + '!'
+
+d = Default()
+assert d.real == 1
+assert d.anything == 'anything!'
+assert getattr(d, 'other') == 'other!'
+assert hasattr(d, 'x')
+assert not hasattr(d, 'missing')
+assert getattr(d, 'missing', 0) == 0
+
+# getattr, setattr, hasattr, delattr
+class Plain:
+    'pass'
+
+o = Plain()
+setattr(o, 'a', 1)
+assert o.a == 1
+assert getattr(o, 'a') == 1
+assert getattr(o, 'b', None) is None
+assert hasattr(o, 'a')
+delattr(o, 'a')
+assert not hasattr(o, 'a')
+
+try:
+    getattr(o, 'a')
+    assert False
+except AttributeError as e:
+    assert str(e) == "'Plain' object has no attribute 'a'"
+
+try:
+    getattr(o, 1)
+    assert False
+except TypeError as e:
+    assert str(e) == "attribute name must be string, not 'int'"
+
+# __delattr__
+class Guarded:
+    def __delattr__(self, name):
+        raise AttributeError('cannot delete ' + name)
+
+try:
+    delattr(Guarded(), 'x')
+    assert False
+except AttributeError as e:
+    assert str(e) == 'cannot delete x'
+
+# __delitem__
+class Store:
+    def __init__(self):
+        self.deleted = []
+
+    def __delitem__(self, key):
+        self.deleted.append(key)
+
+s = Store()
+s.__delitem__('k')
+assert s.deleted == ['k']
+
+d = {'a': 1, 'b': 2}
+d.__delitem__('a')
+assert d == {'b': 2}
+
+# __eq__ without __hash__ makes instances unhashable
+class Value:
+    def __init__(self, v):
+        self.v = v
+
+    def __eq__(self, other):
+        return self.v == other.v
+
+assert Value(1) == Value(1)
+assert Value.__hash__ is None
+try:
+    hash(Value(1))
+    assert False
+except TypeError as e:
+    assert str(e) == "unhashable type: 'Value'"
+
+class HashedValue(Value):
+    def __hash__(self):
+        return hash(self.v)
+
+assert hash(HashedValue(3)) == hash(3)
+assert {HashedValue(3): 'x'}[HashedValue(3)] == 'x'
+
+try:
+    hash([])
+    assert False
+except TypeError as e:
+    assert str(e) == "unhashable type: 'list'"
+
+# subclassing builtins
+class Stack(list):
+    def push(self, x):
+        self.append(x)
+
+    def peek(self):
+        return self[-1]
+
+st = Stack([1, 2])
+st.push(3)
+assert st.peek() == 3
+assert len(st) == 3
+assert type(st) is Stack
+assert isinstance(st, list)
+assert st == [1, 2, 3]
+
+class Counter(dict):
+    def __missing__(self, key):
+        return 0
+
+    def add(self, key):
+        self[key] = self[key] + 1
+
+c = Counter()
+c.add('a')
+c.add('a')
+assert c['a'] == 2
+assert c['b'] == 0
+assert 'b' not in c
+assert type(c) is Counter
+assert isinstance(c, dict)
+
+class Name(str):
+    def shout(self):
+        return self + '!'
+
+nm = Name('hi')
+assert nm.shout() == 'hi!'
+assert nm == 'hi'
+assert type(nm) is Name
+assert type(str(nm)) is str
+
+class Money(int):
+    def cents(self):
+        return self * 100
+
+m = Money(3)
+assert m.cents() == 300
+assert m + 1 == 4
+assert type(m) is Money
+assert type(int('3')) is int
+
+class Celsius(float):
+    'pass'
+
+assert type(Celsius(1.5)) is Celsius
+assert Celsius(1.5) == 1.5
+
+try:
+    class B(bool):
+        'pass'
+    assert False
+except TypeError as e:
+    assert str(e) == "type 'bool' is not an acceptable base type"
+
+# __init__ isn't called when __new__ returns something else
+class Other:
+    def __new__(cls):
+        return 42
+
+    def __init__(self):
+        raise RuntimeError('called')
+
+assert Other() == 42
+
+# iterating a list goes by its items, not its __len__ and __getitem__
+class Odd(list):
+    def __len__(self):
+        return 5
+
+    def __getitem__(self, index):
+        return 'J'
+
+odd = Odd([1, 2])
+assert list(odd) == [1, 2]
+assert tuple(odd) == (1, 2)
+assert sorted(odd) == [1, 2]
+assert [x for x in odd] == [1, 2]
+assert odd[0] == 'J' and len(odd) == 5
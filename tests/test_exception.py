@@ -0,0 +1,86 @@
+assert issubclass(Exception, BaseException)
+assert issubclass(ZeroDivisionError, ArithmeticError)
+assert issubclass(KeyError, LookupError)
+assert issubclass(IndexError, LookupError)
+assert issubclass(RecursionError, RuntimeError)
+assert issubclass(IndentationError, SyntaxError)
+assert not issubclass(TypeError, ValueError)
+
+
+e = ValueError('bad value')
+assert str(e) == 'bad value'
+assert e.args == ('bad value',)
+assert str(ValueError()) == ''
+assert str(KeyError('a')) == "'a'"
+
+
+def catch(fn):
+    try:
+        fn()
+    except BaseException as e:
+        return type(e)
+    return None
+
+def div():
+    return 1 / 0
+
+def add():
+    return 1 + 'a'
+
+def concat():
+    return 'a' + 1
+
+def getkey():
+    return {'a': 1}['b']
+
+def getindex():
+    return [1, 2][5]
+
+def getattr_():
+    return object().missing
+
+def getname():
+    return undefined_name
+
+def badint():
+    return int('abc')
+
+def fail():
+    assert 1 > 2, 'never'
+
+def call():
+    return 1()
+
+def recurse():
+    return recurse()
+
+assert catch(div) is ZeroDivisionError
+assert catch(add) is TypeError
+assert catch(concat) is TypeError
+assert catch(getkey) is KeyError
+assert catch(getindex) is IndexError
+assert catch(getattr_) is AttributeError
+assert catch(getname) is NameError
+assert catch(badint) is ValueError
+assert catch(fail) is AssertionError
+assert catch(call) is TypeError
+assert catch(recurse) is RecursionError
+
+
+try:
+    {}['missing']
+except LookupError as e:
+    assert isinstance(e, KeyError)
+    assert e.args == ('missing',)
+
+
+class AppError(ValueError):
+    def __init__(self, msg, code):
+        ValueError.__init__(self, msg)
+        self.code = code
+
+try:
+    raise AppError('oops', 42)
+except ValueError as e:
+    assert str(e) == 'oops'
+    assert e.code == 42
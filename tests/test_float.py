@@ -0,0 +1,147 @@
+assert 1.5 + 1.5 == 3.0
+assert 3.0 == 3
+assert 1 + 0.5 == 1.5
+assert 0.5 + 1 == 1.5
+assert 2 * 1.5 == 3.0
+assert 1 - 0.25 == 0.75
+assert 1e-3 == 0.001
+assert .5 == 0.5
+assert 2E3 == 2000
+assert 1 < 1.5 and 2.5 > 2
+
+
+assert 1 / 2 == 0.5
+assert 4 / 2 == 2.0
+assert type(4 / 2) is float
+assert 7 // 2 == 3
+assert -7 // 2 == -4
+assert type(7 // 2) is int
+assert 7.5 // 2 == 3.0
+assert -7.5 // 2 == -4.0
+assert type(7.5 // 2) is float
+
+val = 3
+val /= 2
+assert val == 1.5
+val //= 1
+assert val == 1.0
+
+
+assert str(1.0) == '1.0'
+assert str(0.1 + 0.2) == '0.30000000000000004'
+assert str(1 / 3) == '0.3333333333333333'
+assert str(1e16) == '1e+16'
+assert str(1.5e-5) == '1.5e-05'
+assert str(0.0001) == '0.0001'
+assert str(123456789.0) == '123456789.0'
+assert str(float('inf')) == 'inf'
+assert str(float('-inf')) == '-inf'
+assert str(float('nan')) == 'nan'
+
+
+assert float() == 0.0
+assert float(3) == 3.0
+assert float('  2.5 ') == 2.5
+assert int(2.9) == 2
+assert int(-2.9) == -2
+
+assert round(2.5) == 2
+assert round(3.5) == 4
+assert round(-0.5) == 0
+assert type(round(2.7)) is int
+assert round(2.675, 2) == 2.67
+assert round(1.25, 1) == 1.2
+assert round(1234.5, -2) == 1200.0
+assert round(7) == 7
+assert round(1250, -2) == 1200
+assert round(1350, -2) == 1400
+
+
+assert hash(2.0) == hash(2)
+d = {1: 'a'}
+assert d[1.0] == 'a'
+assert bool(0.0) is False
+assert bool(0.1) is True
+assert max(1, 2.5, 2) == 2.5
+assert (2.0).is_integer()
+
+
+def catch(fn):
+    try:
+        fn()
+    except Exception as e:
+        return str(e)
+    return None
+
+def int_div_zero():
+    return 1 / 0
+
+def float_div_zero():
+    return 1.0 / 0
+
+def floordiv_zero():
+    return 1 // 0
+
+def bad_float():
+    return float('abc')
+
+def bad_add():
+    return 1 + 'a'
+
+def bad_compare():
+    return 1 < 'a'
+
+assert catch(int_div_zero) == 'division by zero'
+assert catch(float_div_zero) == 'float division by zero'
+assert catch(floordiv_zero) == 'integer division or modulo by zero'
+assert catch(bad_float) == "could not convert string to float: 'abc'"
+assert catch(bad_add) == "unsupported operand type(s) for +: 'int' and 'str'"
+assert catch(bad_compare) == "'<' not supported between instances of 'int' and 'str'"
+
+def hex_float():
+    return float('0x1p-2')
+
+def float_two_args():
+    return float(1, 2)
+
+def bad_underscore():
+    return float('1__0')
+
+assert catch(hex_float) == "could not convert string to float: '0x1p-2'"
+assert catch(float_two_args) == 'float expected at most 1 argument, got 2'
+assert catch(bad_underscore) == "could not convert string to float: '1__0'"
+assert float('1_000.5') == 1000.5
+assert float(' -Infinity ') == -float('inf')
+assert float('+NaN') != float('+NaN')
+assert float('١٢') == 12.0
+
+assert 1_000 == 1000
+assert 0x1f == 31
+assert 0o17 == 15
+assert 0b_101 == 5
+assert 1e1_0 == 10000000000.0
+
+assert 2**53 + 1 != 9007199254740992.0
+assert 9007199254740992.0 != 2**53 + 1
+assert 9007199254740993 > 9007199254740992.0
+assert 9007199254740992.0 < 9007199254740993
+assert 2**1024 != float('inf')
+assert 2**1024 < float('inf')
+assert -2**1024 > -float('inf')
+assert not (2**1024 == float('nan'))
+assert 2**53 == 9007199254740992.0
+assert hash(2**53) == hash(9007199254740992.0)
+assert 0.5 > 0 and 0.5 < 1
+
+def arity(fn, *args):
+    try:
+        fn(*args)
+    except TypeError as e:
+        return str(e)
+
+assert arity(1.5.__add__) == 'expected 1 argument, got 0'
+assert arity(1.5.__eq__) == 'expected 1 argument, got 0'
+assert arity(1.5.__neg__, 1) == 'expected 0 arguments, got 1'
+assert arity(1.5.__hash__, 1) == 'expected 0 arguments, got 1'
+assert arity(1.5.__round__, 1, 2) == '__round__ expected at most 1 argument, got 2'
+assert arity(1.5.is_integer, 1) == 'float.is_integer() takes no arguments (1 given)'
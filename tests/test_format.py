@@ -0,0 +1,203 @@
+# format() and the format spec mini-language
+assert format(42) == '42'
+assert format(42, '>5') == '   42'
+assert format(42, '*<6') == '42****'
+assert format(42, '^7') == '  42   '
+assert format(-42, '=+8') == '-     42'
+assert format(42, '+') == '+42'
+assert format(42, ' ') == ' 42'
+assert format(42, '08') == '00000042'
+assert format(1234567, ',') == '1,234,567'
+assert format(1234567, '_') == '1_234_567'
+assert format(255, 'x') == 'ff'
+assert format(255, '#X') == '0XFF'
+assert format(255, '#o') == '0o377'
+assert format(5, '#b') == '0b101'
+assert format(65, 'c') == 'A'
+assert format(3.14159, '.2f') == '3.14'
+assert format(3.14159, '10.3f') == '     3.142'
+assert format(1234.5, ',.2f') == '1,234.50'
+assert format(0.25, '%') == '25.000000%'
+assert format(0.25, '.0%') == '25%'
+assert format(12345.678, 'e') == '1.234568e+04'
+assert format(12345.678, '.2E') == '1.23E+04'
+assert format(0.00001, 'g') == '1e-05'
+assert format(1.0, '') == '1.0'
+assert format(2, '.1f') == '2.0'
+assert format(float('inf'), '>5') == '  inf'
+assert format('abc', '>5') == '  abc'
+assert format('abc', '.2') == 'ab'
+assert format('é', '^3') == ' é '
+assert format(True) == 'True'
+assert format(None) == 'None'
+assert format([1, 2]) == '[1, 2]'
+try:
+    format('a', 'd')
+    assert False
+except ValueError as e:
+    assert str(e) == "Unknown format code 'd' for object of type 'str'"
+try:
+    format(1.5, 'd')
+    assert False
+except ValueError as e:
+    assert str(e) == "Unknown format code 'd' for object of type 'float'"
+try:
+    format('a', '+')
+    assert False
+except ValueError as e:
+    assert str(e) == 'Sign not allowed in string format specifier'
+try:
+    format(1, ',c')
+    assert False
+except ValueError as e:
+    assert str(e) == "Cannot specify ',' with 'c'."
+try:
+    format([], 'x')
+    assert False
+except TypeError as e:
+    assert str(e) == 'unsupported format string passed to list.__format__'
+
+# __format__
+class Money:
+    def __init__(self, cents):
+        self.cents = cents
+    def __format__(self, spec):
+        if spec == 'short':
+            return '$' + str(self.cents // 100)
+        return format(self.cents / 100, spec or '.2f')
+    def __repr__(self):
+        return 'Money(' + str(self.cents) + ')'
+
+m = Money(1250)
+assert format(m) == '12.50'
+assert format(m, 'short') == '$12'
+assert '{:short} {}'.format(m, m) == '$12 12.50'
+assert f'{m:>8.1f}' == '    12.5'
+assert f'{m!r}' == 'Money(1250)'
+
+class Bad:
+    def __format__(self, spec):
+        return 1
+try:
+    format(Bad())
+    assert False
+except TypeError as e:
+    assert str(e) == '__format__ must return a str, not int'
+
+# str.format
+assert '{} and {}'.format('a', 'b') == 'a and b'
+assert '{1} {0} {1}'.format('a', 'b') == 'b a b'
+assert '{name} is {age}'.format(name='Ann', age=7) == 'Ann is 7'
+assert '{0[1]} {0[x]}'.format({1: 'one', 'x': 'ex'}) == 'one ex'
+assert '{0.cents}'.format(m) == '1250'
+assert '{!r:>6}|{!s}'.format('a', 'b') == "   'a'|b"
+assert '{:{}{}}'.format(3, '>', 4) == '   3'
+assert '{{}} {}'.format(1) == '{} 1'
+assert '{:!^9,}'.format(12345) == '!12,345!!'
+try:
+    '{}'.format()
+    assert False
+except IndexError as e:
+    assert str(e) == 'Replacement index 0 out of range for positional args tuple'
+try:
+    '{0} {}'.format(1, 2)
+    assert False
+except ValueError as e:
+    assert str(e) == 'cannot switch from manual field specification to automatic field numbering'
+try:
+    '{x}'.format(y=1)
+    assert False
+except KeyError as e:
+    assert str(e) == "'x'"
+try:
+    '}'.format()
+    assert False
+except ValueError as e:
+    assert str(e) == "Single '}' encountered in format string"
+
+# printf-style formatting
+assert '%s and %r' % ('a', 'b') == "a and 'b'"
+assert '%d%%' % 50 == '50%'
+assert '%5d|%-5d|%05d' % (1, 2, -3) == '    1|2    |-0003'
+assert '%+d % d' % (1, 1) == '+1  1'
+assert '%x %X %#x %o %#o' % (255, 255, 255, 8, 8) == 'ff FF 0xff 10 0o10'
+assert '%.2f %e %g' % (3.14159, 1234.5, 0.0001) == '3.14 1.234500e+03 0.0001'
+assert '%*d|%.*f' % (4, 7, 1, 2.25) == '   7|2.2'
+assert '%c%c' % (104, 'i') == 'hi'
+assert '%.2s' % 'abc' == 'ab'
+assert '%(a)s=%(b)d' % {'a': 'x', 'b': 1} == 'x=1'
+assert '%s' % [1, 2] == '[1, 2]'
+assert '%s' % (1,) == '1'
+assert '%d' % 3.9 == '3'
+assert '%a' % 'é' == "'\\xe9'"
+try:
+    '%d %d' % (1,)
+    assert False
+except TypeError as e:
+    assert str(e) == 'not enough arguments for format string'
+try:
+    '%d' % (1, 2)
+    assert False
+except TypeError as e:
+    assert str(e) == 'not all arguments converted during string formatting'
+try:
+    '%d' % 'a'
+    assert False
+except TypeError as e:
+    assert str(e) == '%d format: a real number is required, not str'
+try:
+    '%y' % 1
+    assert False
+except ValueError as e:
+    assert str(e) == "unsupported format character 'y' (0x79) at index 1"
+try:
+    '%(a)s' % (1,)
+    assert False
+except TypeError as e:
+    assert str(e) == 'format requires a mapping'
+
+# f-strings
+name = 'World'
+n = 3
+assert f'Hello, {name}!' == 'Hello, World!'
+assert f'{n + 1}' == '4'
+assert f'{n * 2:03d}' == '006'
+assert f'{name!r}' == "'World'"
+assert f'{name!r:>9}' == "  'World'"
+assert f'{"é"!a}' == "'\\xe9'"
+assert f'{n=}' == 'n=3'
+assert f'{n = }' == 'n = 3'
+assert f'{name=!s}' == 'name=World'
+assert f'{n=:>3}' == 'n=  3'
+assert f'{{}}{n}{{' == '{}3{'
+assert f'{3.14159:.{n}f}' == '3.142'
+assert f'{name:{"*"}^{n + 6}}' == '**World**'
+assert f'{[1, 2][n - 3]}' == '1'
+assert f'{ {"k": n}["k"] }' == '3'
+assert f'{n, n}' == '(3, 3)'
+assert f'{n == 3} {n != 3} {n <= 3}' == 'True False True'
+assert f'\x7b{n}\N{RIGHT CURLY BRACKET}' == '{3}'
+assert f'{n:\x3e3}' == '  3'
+assert rf'\n{n}' == '\\n3'
+assert F'{n}' 'b' f'{n}' == '3b3'
+assert 'a' f'{n}' '{n}' == 'a3{n}'
+assert f'''{
+    n
+    + 1}''' == '4'
+assert [f'{i}{c}' for i, c in [(1, 'a'), (2, 'b')]] == ['1a', '2b']
+
+def scoped():
+    k = 'local'
+    return (lambda: f'{k}')()
+assert scoped() == 'local'
+
+try:
+    f'{undefined}'
+    assert False
+except NameError as e:
+    assert str(e) == "name 'undefined' is not defined"
+try:
+    f'{n:q}'
+    assert False
+except ValueError as e:
+    assert str(e) == "Unknown format code 'q' for object of type 'int'"
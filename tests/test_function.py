@@ -11,3 +11,83 @@ def foo():
 
 assert foo() == 1
 
+
+
+def foo(a, b=2, *args, c, d=4, **kwargs):
+    return [a, b, args, c, d, kwargs]
+
+res = foo(1, c=3)
+assert res[0] == 1 and res[1] == 2 and res[2] == () and res[3] == 3 and res[4] == 4
+assert len(res[5]) == 0
+res = foo(1, 5, 6, 7, c=3, d=8, e=9)
+assert res[1] == 5 and res[2] == (6, 7) and res[4] == 8
+assert len(res[5]) == 1 and res[5]['e'] == 9
+res = foo(b=5, a=1, c=3)
+assert res[0] == 1 and res[1] == 5
+
+
+def foo(a, b, c):
+    return a + b + c
+
+xs = [1, 2]
+kw = {'c': 3}
+assert foo(*xs, **kw) == 6
+assert foo(1, *[2, 3]) == 6
+assert foo(**{'a': 1, 'b': 2, 'c': 3}) == 6
+
+
+def foo(*, a, b=1):
+    return a + b
+
+assert foo(a=1) == 2
+
+
+def foo(a, b=[]):
+    b.append(a)
+    return b
+
+foo(1)
+assert foo(2) == [1, 2]
+
+
+def catch(fn, *args, **kwargs):
+    try:
+        fn(*args, **kwargs)
+    except TypeError as e:
+        return str(e)
+    return None
+
+def foo(a, b=2):
+    return a
+
+assert catch(foo) == "foo() missing 1 required positional argument: 'a'"
+assert catch(foo, 1, 2, 3) == 'foo() takes from 1 to 2 positional arguments but 3 were given'
+assert catch(foo, 1, a=1) == "foo() got multiple values for argument 'a'"
+assert catch(foo, 1, c=1) == "foo() got an unexpected keyword argument 'c'"
+
+# builtins check how many arguments they're given
+assert catch(len) == 'len() takes exactly one argument (0 given)'
+assert catch(len, [1], [2]) == 'len() takes exactly one argument (2 given)'
+assert catch(hash) == 'hash() takes exactly one argument (0 given)'
+assert catch(iter) == 'iter expected at least 1 argument, got 0'
+assert catch(next) == 'next expected at least 1 argument, got 0'
+assert catch(isinstance, 1) == 'isinstance expected 2 arguments, got 1'
+assert catch(issubclass, int) == 'issubclass expected 2 arguments, got 1'
+assert catch([].append) == 'list.append() takes exactly one argument (0 given)'
+assert catch(range) == 'range expected at least 1 argument, got 0'
+assert catch(range, 1, 2, 3, 4) == 'range expected at most 3 arguments, got 4'
+assert catch(bool, 1, 2) == 'bool expected at most 1 argument, got 2'
+assert next(iter([]), 'end') == 'end'
+assert [1, 2, 3].pop(0) == 1
+assert list(iter([3, 2, 1, 0].pop, 1)) == [0]
+
+class Plain:
+    'pass'
+
+class WithInit:
+    def __init__(self, x):
+        self.x = x
+
+assert catch(Plain, 1, 2, k=3) == 'Plain() takes no arguments'
+assert catch(object, 1) == 'object() takes no arguments'
+assert WithInit(1).x == 1
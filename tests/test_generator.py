@@ -0,0 +1,222 @@
+def count(n):
+    i = 0
+    while i < n:
+        yield i
+        i += 1
+
+assert list(count(3)) == [0, 1, 2]
+assert list(count(0)) == []
+
+g = count(2)
+assert iter(g) is g
+assert next(g) == 0
+assert next(g) == 1
+try:
+    next(g)
+    assert False
+except StopIteration:
+    x = 1
+
+# nothing runs until the first next()
+log = []
+def lazy():
+    log.append('started')
+    yield 1
+g = lazy()
+assert log == []
+next(g)
+assert log == ['started']
+
+
+# the value of a return is carried by StopIteration
+def ret():
+    yield 1
+    return 'done'
+g = ret()
+next(g)
+try:
+    next(g)
+    assert False
+except StopIteration as e:
+    assert e.value == 'done'
+    assert e.args == ('done',)
+
+def bare():
+    yield
+    return
+assert list(bare()) == [None]
+
+
+# send
+def accumulate():
+    total = 0
+    while True:
+        x = yield total
+        total += x
+
+acc = accumulate()
+assert next(acc) == 0
+assert acc.send(3) == 3
+assert acc.send(4) == 7
+try:
+    accumulate().send(1)
+    assert False
+except TypeError as e:
+    assert str(e) == "can't send non-None value to a just-started generator"
+
+
+# throw
+def catcher():
+    while True:
+        try:
+            yield 'ready'
+        except ValueError as e:
+            log.append(str(e))
+
+log = []
+g = catcher()
+next(g)
+assert g.throw(ValueError('bad')) == 'ready'
+assert g.throw(ValueError, 'worse') == 'ready'
+assert log == ['bad', 'worse']
+try:
+    g.throw(KeyError('k'))
+    assert False
+except KeyError:
+    x = 1
+try:
+    next(g)
+    assert False
+except StopIteration:
+    x = 1
+
+# a generator that hasn't started dies without running
+g = catcher()
+try:
+    g.throw(ValueError)
+    assert False
+except ValueError:
+    x = 1
+assert list(g) == []
+
+
+# close
+def closing():
+    try:
+        yield 1
+        yield 2
+    finally:
+        log.append('closed')
+
+log = []
+g = closing()
+next(g)
+g.close()
+assert log == ['closed']
+assert list(g) == []
+g.close()
+
+def stubborn():
+    try:
+        yield 1
+    except GeneratorExit:
+        yield 2
+g = stubborn()
+next(g)
+try:
+    g.close()
+    assert False
+except RuntimeError as e:
+    assert str(e) == 'generator ignored GeneratorExit'
+
+assert issubclass(GeneratorExit, BaseException)
+assert not issubclass(GeneratorExit, Exception)
+
+
+# yield from
+def inner():
+    x = yield 1
+    yield x
+    return 'inner done'
+
+def outer():
+    result = yield from inner()
+    yield result
+
+g = outer()
+assert next(g) == 1
+assert g.send('sent') == 'sent'
+assert next(g) == 'inner done'
+
+def chain(*iterables):
+    for it in iterables:
+        yield from it
+assert list(chain([1, 2], (3,), 'ab')) == [1, 2, 3, 'a', 'b']
+
+def tree(n):
+    if n > 0:
+        yield from tree(n - 1)
+        yield n
+        yield from tree(n - 1)
+assert list(tree(3)) == [1, 2, 1, 3, 1, 2, 1]
+
+def delegating():
+    yield from catcher()
+log = []
+g = delegating()
+next(g)
+assert g.throw(ValueError('passed on')) == 'ready'
+assert log == ['passed on']
+
+log = []
+def delegating_close():
+    try:
+        yield from closing()
+    finally:
+        log.append('outer closed')
+g = delegating_close()
+next(g)
+g.close()
+assert log == ['closed', 'outer closed']
+
+
+# errors
+def raising():
+    raise StopIteration
+    yield
+try:
+    next(raising())
+    assert False
+except RuntimeError as e:
+    assert str(e) == 'generator raised StopIteration'
+
+def reentrant():
+    yield next(me)
+me = reentrant()
+try:
+    next(me)
+    assert False
+except ValueError as e:
+    assert str(e) == 'generator already executing'
+
+def failing():
+    yield 1
+    1 / 0
+g = failing()
+next(g)
+try:
+    next(g)
+    assert False
+except ZeroDivisionError:
+    x = 1
+assert list(g) == []
+
+assert type(count(1)).__name__ == 'generator'
+
+g = count(1)
+try:
+    g.send()
+    raised = None
+except TypeError as e:
+    raised = str(e)
+assert raised == 'generator.send() takes exactly one argument (0 given)'
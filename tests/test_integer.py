@@ -29,3 +29,24 @@ assert (not 1 > 2) is True
 
 assert (2 > 1 and 1 > 2) is False
 
+
+
+def catch(fn):
+    try:
+        fn()
+    except Exception as e:
+        return str(e)
+    return None
+
+assert int('1_000') == 1000
+assert int('ff', 16) == 255
+assert int('0x_1F', 0) == 31
+assert int('-0b11', 0) == -3
+assert int('z', 36) == 35
+assert int(b'12', 8) == 10
+assert catch(lambda: int(1, 2, 3)) == 'int() takes at most 2 arguments (3 given)'
+assert catch(lambda: int(5, 10)) == "int() can't convert non-string with explicit base"
+assert catch(lambda: int('1', 37)) == 'int() base must be >= 2 and <= 36, or 0'
+assert catch(lambda: int('012', 0)) == "invalid literal for int() with base 0: '012'"
+assert catch(lambda: int('8', 8)) == "invalid literal for int() with base 8: '8'"
+assert catch(lambda: int('1__0')) == "invalid literal for int() with base 10: '1__0'"
@@ -0,0 +1,105 @@
+f = lambda a, b=1: a + b
+assert f(1) == 2
+assert f(1, 2) == 3
+assert f(b=5, a=1) == 6
+assert (lambda: 7)() == 7
+assert (lambda *args, **kwargs: (args, len(kwargs)))(1, 2, x=3) == ((1, 2), 1)
+assert (lambda *, key: key)(key=4) == 4
+assert (lambda: 0).__name__ == '<lambda>'
+
+t = lambda: 1, 2
+assert type(t) == tuple
+assert t[1] == 2
+
+compose = lambda f, g: lambda x: f(g(x))
+assert compose(lambda x: x * 2, lambda x: x + 1)(3) == 8
+
+gen = lambda n: (yield n)
+assert next(gen(5)) == 5
+
+
+# key functions
+pairs = [(1, 'b'), (3, 'a'), (2, 'c')]
+assert max(pairs, key=lambda p: p[1]) == (2, 'c')
+assert min(pairs, key=lambda p: p[1]) == (3, 'a')
+assert max(3, 1, 2) == 3
+assert min(3, 1, 2, key=lambda x: -x) == 3
+assert max([], default=None) is None
+assert min([1, 5], key=None) == 1
+# the first of equal items wins
+assert max([(1, 'a'), (1, 'b')], key=lambda p: p[0]) == (1, 'a')
+
+try:
+    max()
+    assert False
+except TypeError as e:
+    assert str(e) == 'max expected at least 1 argument, got 0'
+try:
+    min([])
+    assert False
+except ValueError as e:
+    assert str(e) == 'min() arg is an empty sequence'
+try:
+    max(1, 2, default=0)
+    assert False
+except TypeError:
+    x = 1
+
+
+assert sorted([3, 1, 2]) == [1, 2, 3]
+assert sorted((3, 1, 2), reverse=True) == [3, 2, 1]
+assert sorted('cab') == ['a', 'b', 'c']
+assert sorted(pairs, key=lambda p: p[1]) == [(3, 'a'), (1, 'b'), (2, 'c')]
+assert sorted([]) == []
+# stable, in either direction
+assert sorted([3, 1, 2, 4], key=lambda x: x % 2) == [2, 4, 3, 1]
+assert sorted([(1, 'a'), (0, 'x'), (1, 'b')], key=lambda t: t[0], reverse=True) == [(1, 'a'), (1, 'b'), (0, 'x')]
+
+xs = [5, 3, 9]
+ys = sorted(xs)
+assert xs == [5, 3, 9]
+assert xs.sort() is None
+assert xs == [3, 5, 9]
+xs.sort(key=lambda x: -x)
+assert xs == [9, 5, 3]
+
+try:
+    sorted([1], foo=1)
+    assert False
+except TypeError:
+    x = 1
+try:
+    sorted([1, 'a'])
+    assert False
+except TypeError:
+    x = 1
+
+
+# closures look free variables up when called, not when made
+fs = [lambda: i for i in range(3)]
+assert [f() for f in fs] == [2, 2, 2]
+fs = [lambda i=i: i for i in range(3)]
+assert [f() for f in fs] == [0, 1, 2]
+
+def make():
+    x = 1
+    get = lambda: x
+    x = 2
+    return get
+assert make()() == 2
+
+# and closures made by the same call share the variables
+def counter():
+    def inc():
+        count[0] += 1
+    def get():
+        return count[0]
+    count = [0]
+    return inc, get
+
+inc, get = counter()
+inc()
+inc()
+assert get() == 2
+inc2, get2 = counter()
+assert get2() == 0
@@ -0,0 +1,177 @@
+class A:
+    def who(self):
+        return 'A'
+    def only_a(self):
+        return 'only A'
+
+class B(A):
+    def who(self):
+        return 'B'
+
+class C(A):
+    def who(self):
+        return 'C'
+    def only_c(self):
+        return 'only C'
+
+class D(B, C):
+    'pass'
+
+assert D.__mro__ == (D, B, C, A, object)
+assert D.__bases__ == (B, C)
+assert A.__bases__ == (object,)
+assert A.__mro__ == (A, object)
+
+d = D()
+assert d.who() == 'B'
+assert d.only_c() == 'only C'
+assert d.only_a() == 'only A'
+assert isinstance(d, C)
+assert isinstance(d, A)
+assert issubclass(D, C)
+assert issubclass(D, (int, A))
+assert not issubclass(C, B)
+
+
+# the textbook example of C3
+class O:
+    'pass'
+class F(O):
+    'pass'
+class E(O):
+    'pass'
+class Dd(O):
+    'pass'
+class Cc(Dd, F):
+    'pass'
+class Bb(Dd, E):
+    'pass'
+class Aa(Bb, Cc, E):
+    'pass'
+
+assert Aa.__mro__ == (Aa, Bb, Cc, Dd, E, F, O, object)
+
+
+# class attributes follow the mro too
+class X:
+    value = 'X'
+    other = 'X'
+
+class Y:
+    value = 'Y'
+
+class Z(Y, X):
+    'pass'
+
+assert Z.value == 'Y'
+assert Z.other == 'X'
+assert Z().other == 'X'
+
+
+# mixins
+class ReprMixin:
+    def describe(self):
+        return type(self).__name__ + ' ' + str(self.data())
+
+class Base:
+    def __init__(self, n):
+        self.n = n
+    def data(self):
+        return self.n
+
+class Item(ReprMixin, Base):
+    'pass'
+
+assert Item(3).describe() == 'Item 3'
+
+
+# bases are any expressions
+bases = (B, C)
+class G(*bases):
+    'pass'
+
+assert G.__mro__ == (G, B, C, A, object)
+
+class Holder:
+    base = A
+
+class H(Holder.base):
+    'pass'
+
+assert H().who() == 'A'
+
+
+# exceptions can be mixed in
+class Tagged:
+    tag = 'tagged'
+
+class TaggedError(Tagged, ValueError):
+    'pass'
+
+try:
+    raise TaggedError('oops')
+except ValueError as e:
+    assert e.tag == 'tagged'
+    assert str(e) == 'oops'
+
+class BothError(ValueError, KeyError):
+    'pass'
+
+assert issubclass(BothError, LookupError)
+
+
+# inconsistent hierarchies
+try:
+    class Bad(A, B):
+        'pass'
+    assert False
+except TypeError as e:
+    assert str(e)[:44] == 'Cannot create a consistent method resolution'
+    assert str(e)[-26:] == 'order (MRO) for bases A, B'
+
+class P(X, Y):
+    'pass'
+class Q(Y, X):
+    'pass'
+try:
+    class R(P, Q):
+        'pass'
+    assert False
+except TypeError as e:
+    assert str(e)[-26:] == 'order (MRO) for bases X, Y'
+
+try:
+    class Dup(A, A):
+        'pass'
+    assert False
+except TypeError as e:
+    assert str(e) == 'duplicate base class A'
+
+try:
+    class Conflict(int, str):
+        'pass'
+    assert False
+except TypeError as e:
+    assert str(e) == 'multiple bases have instance lay-out conflict'
+
+try:
+    class NotClass(A, 1):
+        'pass'
+    assert False
+except TypeError:
+    assert True
+
+
+# type() makes classes out of a tuple of bases too
+T = type('T', (B, C), {'x': 1})
+assert T.__mro__ == (T, B, C, A, object)
+assert T().who() == 'B'
+assert T.x == 1
+assert type('U', (), {}).__bases__ == (object,)
+
+# the builtin classes have one too
+assert object.__mro__ == (object,)
+assert int.__mro__ == (int, object)
+assert bool.__mro__ == (bool, int, object)
+assert KeyError.__mro__ == (KeyError, LookupError, Exception, BaseException, object)
+assert type.__mro__ == (type, object)
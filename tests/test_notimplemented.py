@@ -0,0 +1,118 @@
+assert str(NotImplemented) == 'NotImplemented'
+assert type(NotImplemented).__name__ == 'NotImplementedType'
+
+
+class Money:
+    def __init__(self, amount):
+        self.amount = amount
+
+    def __add__(self, other):
+        if isinstance(other, Money):
+            return Money(self.amount + other.amount)
+        if isinstance(other, int):
+            return Money(self.amount + other)
+        return NotImplemented
+
+    def __radd__(self, other):
+        if isinstance(other, int):
+            return Money(other + self.amount)
+        return NotImplemented
+
+    def __eq__(self, other):
+        if isinstance(other, Money):
+            return self.amount == other.amount
+        return NotImplemented
+
+    def __lt__(self, other):
+        if isinstance(other, Money):
+            return self.amount < other.amount
+        return NotImplemented
+
+assert (Money(1) + Money(2)).amount == 3
+assert (Money(1) + 2).amount == 3
+assert (2 + Money(1)).amount == 3
+assert Money(1) == Money(1)
+assert Money(1) != Money(2)
+assert not Money(1) != Money(1)
+assert Money(1) != 1
+assert not Money(1) == 1
+assert Money(1) < Money(2)
+assert Money(2) > Money(1)
+
+
+def catch(fn):
+    try:
+        fn()
+    except BaseException as e:
+        return type(e)
+    return None
+
+def add_str():
+    return Money(1) + 'a'
+
+def radd_str():
+    return 'a' + Money(1)
+
+def lt_int():
+    return Money(1) < 1
+
+assert catch(add_str) is TypeError
+assert catch(radd_str) is TypeError
+assert catch(lt_int) is TypeError
+
+
+class Plain:
+    x = 1
+
+p = Plain()
+assert p == p
+assert not p != p
+assert p != Plain()
+assert p != 1
+assert 1 != p
+assert not (p == None)
+assert [1, 'a'] != [1, 2]
+assert (1, 2) != [1, 2]
+
+
+# a subclass overriding the reflected method is asked first
+order = []
+
+class Base:
+    def __add__(self, other):
+        order.append('Base.__add__')
+        return 'base'
+
+    def __radd__(self, other):
+        order.append('Base.__radd__')
+        return 'base'
+
+class Derived(Base):
+    def __radd__(self, other):
+        order.append('Derived.__radd__')
+        return 'derived'
+
+assert Base() + Derived() == 'derived'
+assert order == ['Derived.__radd__']
+
+order = []
+assert Base() + Base() == 'base'
+assert order == ['Base.__add__']
+
+
+class Reversed:
+    def __gt__(self, other):
+        return 'reflected'
+
+assert (1 < Reversed()) == 'reflected'
+
+
+class OnlyEq:
+    def __init__(self, v):
+        self.v = v
+
+    def __eq__(self, other):
+        return self.v == other.v
+
+assert OnlyEq(1) != OnlyEq(2)
+assert not OnlyEq(1) != OnlyEq(1)
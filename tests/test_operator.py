@@ -1,8 +1,181 @@
-assert 1 == 1
+assert -1 + 3 == 2
+assert +5 == 5
+assert ~5 == -6
+assert --3 == 3
+assert -2 ** 2 == -4
+assert 2 ** -1 == 0.5
+assert 2 ** 3 ** 2 == 512
+assert (2 ** 3) ** 2 == 64
+assert 1 + 2 * 3 ** 2 == 19
+assert 3 ** 100 == 515377520732011331036461129765621272702107522001
 
-assert 2 > 1
+assert 7 % 3 == 1
+assert -7 % 3 == 2
+assert 7 % -3 == -2
+assert -7.5 % 2 == 0.5
+assert 10 // 3 % 2 == 1
+assert 2.0 ** 0.5 > 1.414
 
-assert 1 < 2
+assert 1 << 70 == 1180591620717411303424
+assert (1 << 70) >> 68 == 4
+assert -1 >> 100 == -1
+assert -8 >> 1 == -4
+assert 6 & 3 == 2
+assert 6 | 3 == 7
+assert 6 ^ 3 == 5
+assert -6 & 255 == 250
+assert 1 | 2 ^ 3 & 4 << 1 == 3
+assert 1 + 1 << 2 == 8
 
-assert 1 != 2
+assert (True & False) is False
+assert (True | False) is True
+assert (True ^ True) is False
+assert True | 2 == 3
+assert -True == -1
 
+x = 5
+x %= 3
+x **= 4
+x <<= 2
+x >>= 1
+x |= 1
+x &= 7
+x ^= 2
+assert x == 3
+
+
+def catch(fn):
+    try:
+        fn()
+    except BaseException as e:
+        return type(e)
+    return None
+
+def mod_zero():
+    return 1 % 0
+
+def neg_shift():
+    return 1 << -1
+
+def neg_str():
+    return -'a'
+
+def pow_str():
+    return 'a' ** 2
+
+def invert_float():
+    return ~1.5
+
+assert catch(mod_zero) is ZeroDivisionError
+assert catch(neg_shift) is ValueError
+assert catch(neg_str) is TypeError
+assert catch(pow_str) is TypeError
+assert catch(invert_float) is TypeError
+
+
+class Vector:
+    def __init__(self, x, y):
+        self.x = x
+        self.y = y
+
+    def __neg__(self):
+        return Vector(-self.x, -self.y)
+
+    def __mod__(self, n):
+        return Vector(self.x % n, self.y % n)
+
+    def __pow__(self, n):
+        return Vector(self.x ** n, self.y ** n)
+
+    def __rpow__(self, n):
+        return Vector(n ** self.x, n ** self.y)
+
+    def __and__(self, other):
+        return self.x & other.x
+
+    def __lshift__(self, n):
+        return Vector(self.x << n, self.y << n)
+
+    def __invert__(self):
+        return Vector(self.y, self.x)
+
+v = -Vector(3, 4)
+assert v.x == -3 and v.y == -4
+v = Vector(7, 8) % 3
+assert v.x == 1 and v.y == 2
+v = Vector(2, 3) ** 2
+assert v.x == 4 and v.y == 9
+v = 2 ** Vector(2, 3)
+assert v.x == 4 and v.y == 8
+assert Vector(6, 0) & Vector(3, 0) == 2
+v = Vector(1, 2)
+v <<= 3
+assert v.x == 8 and v.y == 16
+v = ~v
+assert v.x == 16 and v.y == 8
+
+calls = []
+def key():
+    calls.append('key')
+    return 0
+xs = [1]
+xs[key()] += 1
+assert xs == [2] and calls == ['key']
+
+def target():
+    calls.append('target')
+    return v
+v.x += 1
+target().x -= 2
+assert v.x == 15 and calls == ['key', 'target']
+
+l = [1]
+alias = l
+l += [2]
+l += (3,)
+assert alias == [1, 2, 3] and alias is l
+s = {1}
+alias = s
+s |= {2}
+s &= {2, 3}
+s ^= {4}
+s -= {4}
+assert alias == {2} and alias is s
+f = frozenset({1})
+alias = f
+f |= {2}
+assert alias == frozenset({1}) and f == frozenset({1, 2})
+
+class InPlace:
+    def __iadd__(self, other):
+        return 'iadd'
+    def __add__(self, other):
+        return 'add'
+    def __imul__(self, other):
+        return NotImplemented
+    def __mul__(self, other):
+        return 'mul'
+i = InPlace()
+i += 1
+assert i == 'iadd'
+i = InPlace()
+i *= 1
+assert i == 'mul'
+
+def augmented_error(x, y, op):
+    try:
+        if op == '|=':
+            x |= y
+        if op == '**=':
+            x **= y
+        if op == '*=':
+            x *= y
+        if op == '+=':
+            x += y
+    except TypeError as e:
+        return str(e)
+
+assert augmented_error({1}, [2], '|=') == "unsupported operand type(s) for |=: 'set' and 'list'"
+assert augmented_error(2, 'a', '**=') == "unsupported operand type(s) for **=: 'int' and 'str'"
+assert augmented_error((1,), 'a', '*=') == "can't multiply sequence by non-int of type 'str'"
+assert augmented_error([1], 3, '+=') == "'int' object is not iterable"
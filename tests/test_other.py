@@ -16,3 +16,20 @@ for i in Foo():
 
 assert not 1 > 2 is True
 
+
+# lines are joined inside brackets
+def f(a,
+      b=2,
+      *args):
+    return [a,
+            b] + list(args)
+x = f(1,
+      3, 4)
+assert x == [1, 3, 4]
+y = (1
+     + 2)
+assert y == 3
+z = [i
+     for i in range(3)
+     if i]
+assert z == [1, 2]
@@ -0,0 +1,152 @@
+count = 0
+def inc():
+    global count
+    count += 1
+inc()
+inc()
+assert count == 2
+
+def define():
+    global made, other
+    made = 'made'
+    def other():
+        return 'other'
+define()
+assert made == 'made'
+assert other() == 'other'
+
+# global at module level is allowed, and does nothing
+global module_level
+module_level = 1
+
+
+def make_counter():
+    n = 0
+    def inc():
+        nonlocal n
+        n += 1
+        return n
+    def get():
+        return n
+    return inc, get
+
+inc1, get1 = make_counter()
+inc2, get2 = make_counter()
+inc1()
+inc1()
+inc2()
+assert get1() == 2
+assert get2() == 1
+
+def outer():
+    x = 1
+    def middle():
+        def inner():
+            nonlocal x
+            x = 5
+        inner()
+    middle()
+    return x
+assert outer() == 5
+
+def through():
+    x = 1
+    def middle():
+        nonlocal x
+        def inner():
+            nonlocal x
+            x += 1
+        inner()
+        x += 1
+    middle()
+    return x
+assert through() == 3
+
+# the binding can come after the nested function
+def later():
+    def set_it():
+        nonlocal v
+        v = 'set'
+    v = None
+    set_it()
+    return v
+assert later() == 'set'
+
+
+# a name assigned anywhere in a function is local to all of it
+x = 'global'
+def shadow():
+    x = 'local'
+    return x
+assert shadow() == 'local'
+assert x == 'global'
+
+def read_first():
+    y = x
+    x = 1
+try:
+    read_first()
+    assert False
+except UnboundLocalError as e:
+    assert str(e) == "cannot access local variable 'x' where it is not associated with a value"
+
+def augment():
+    x += 1
+try:
+    augment()
+    assert False
+except UnboundLocalError:
+    y = 1
+
+assert issubclass(UnboundLocalError, NameError)
+
+def missing():
+    return not_defined_anywhere
+try:
+    missing()
+    assert False
+except NameError as e:
+    assert str(e) == "name 'not_defined_anywhere' is not defined"
+    assert type(e) is NameError
+
+
+# free variables are looked up when used
+def late():
+    def inner():
+        return v
+    v = 3
+    return inner
+assert late()() == 3
+
+
+# class bodies are not seen from the methods, but are from the body
+class C:
+    a = 1
+    b = a + 1
+    def m(self):
+        try:
+            return a
+        except NameError:
+            return 'not visible'
+assert C.b == 2
+assert C().m() == 'not visible'
+
+def make_class():
+    z = 4
+    class K:
+        w = z
+        def m(self):
+            return z
+    return K
+K = make_class()
+assert K.w == 4
+assert K().m() == 4
+
+# while a class body falls back on globals for unassigned names
+gv = 'global'
+def class_fallback():
+    gv = 'enclosing'
+    class A:
+        r = gv
+    return A.r
+assert class_fallback() == 'enclosing'
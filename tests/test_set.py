@@ -0,0 +1,206 @@
+# literals
+s = {1, 2, 3}
+assert len(s) == 3
+assert type(s) is set
+assert {1, 1, 2} == {1, 2}
+assert {1, 2,} == {2, 1}
+assert {'a'} == set('a')
+assert type({}) is dict
+assert set() == set([])
+xs = [3, 4]
+assert {1, *xs, 2} == {1, 2, 3, 4}
+assert {
+    1,
+    2,
+} == {1, 2}
+assert {x * 2 for x in range(3)} == {0, 2, 4}
+
+try:
+    {[1]}
+    assert False
+except TypeError as e:
+    assert str(e) == "unhashable type: 'list'"
+
+# repr
+assert repr({1}) == '{1}'
+assert repr(set()) == 'set()'
+assert repr(frozenset()) == 'frozenset()'
+assert repr(frozenset([1])) == 'frozenset({1})'
+assert str({'a'}) == "{'a'}"
+
+# add, discard, remove, pop, clear
+s = set()
+s.add(1)
+s.add(1)
+s.add(2)
+assert s == {1, 2}
+s.discard(2)
+s.discard(5)
+assert s == {1}
+s.remove(1)
+assert s == set()
+try:
+    s.remove(1)
+    assert False
+except KeyError as e:
+    assert str(e) == '1'
+
+s = {7}
+assert s.pop() == 7
+assert len(s) == 0
+try:
+    s.pop()
+    assert False
+except KeyError as e:
+    assert str(e) == "'pop from an empty set'"
+
+s = {1, 2}
+s.clear()
+assert not s
+
+# membership and iteration
+s = {1, 2, 3}
+assert 2 in s
+assert 4 not in s
+assert (1, 2) in {(1, 2)}
+assert sorted(s) == [1, 2, 3]
+total = 0
+for x in s:
+    total = total + x
+assert total == 6
+
+# operations as methods, taking any iterables
+a = {1, 2, 3}
+b = {2, 3, 4}
+assert a.union(b) == {1, 2, 3, 4}
+assert a.union([5], (6,)) == {1, 2, 3, 5, 6}
+assert a.intersection(b) == {2, 3}
+assert a.intersection([2], {2, 3}) == {2}
+assert a.difference(b) == {1}
+assert a.difference([1], [2]) == {3}
+assert a.symmetric_difference(b) == {1, 4}
+assert a.symmetric_difference([3, 5]) == {1, 2, 5}
+assert a == {1, 2, 3}
+
+# and as operators, taking sets only
+assert a | b == {1, 2, 3, 4}
+assert a & b == {2, 3}
+assert a - b == {1}
+assert b - a == {4}
+assert a ^ b == {1, 4}
+try:
+    a | [1]
+    assert False
+except TypeError as e:
+    assert str(e) == "unsupported operand type(s) for |: 'set' and 'list'"
+
+c = {1}
+c |= {2}
+c &= {2, 3}
+assert c == {2}
+c ^= {2, 5}
+c -= {9}
+assert c == {5}
+
+# in-place updates
+s = {1, 2, 3}
+s.update([4], {5})
+assert s == {1, 2, 3, 4, 5}
+s.intersection_update({1, 2, 3, 9})
+assert s == {1, 2, 3}
+s.difference_update([1])
+assert s == {2, 3}
+s.symmetric_difference_update({3, 4})
+assert s == {2, 4}
+
+# copy
+s = {1}
+t = s.copy()
+t.add(2)
+assert s == {1}
+
+# subset comparisons
+assert {1} <= {1, 2}
+assert {1, 2} <= {1, 2}
+assert {1} < {1, 2}
+assert not {1, 2} < {1, 2}
+assert {1, 2} >= {1}
+assert {1, 2} > {1}
+assert not {1, 3} <= {1, 2}
+assert not {1, 3} >= {1, 2}
+assert {1, 2} != {1, 3}
+assert {1}.issubset([1, 2])
+assert {1, 2}.issuperset([1])
+assert {1}.isdisjoint([2, 3])
+assert not {1}.isdisjoint([1])
+try:
+    {1} < [1]
+    assert False
+except TypeError as e:
+    assert str(e) == "'<' not supported between instances of 'set' and 'list'"
+
+# frozenset
+f = frozenset([1, 2, 2])
+assert len(f) == 2
+assert f == {1, 2}
+assert {1, 2} == f
+assert type(f) is frozenset
+assert frozenset() == frozenset([])
+assert frozenset(f) is f
+assert f.copy() is f
+assert hash(frozenset([1, 2])) == hash(frozenset([2, 1]))
+assert {frozenset([1]): 'one'}[frozenset([1])] == 'one'
+assert frozenset([1]) in {frozenset([1])}
+assert not isinstance(f, set)
+assert not hasattr(f, 'add')
+
+# the result is of the kind of the left operand
+assert type(f | {3}) is frozenset
+assert type({3} | f) is set
+assert type(f.union([3])) is frozenset
+assert type(f - {1}) is frozenset
+
+try:
+    hash({1})
+    assert False
+except TypeError as e:
+    assert str(e) == "unhashable type: 'set'"
+
+# subclasses
+class Tags(set):
+    def tag(self, t):
+        self.add(t)
+
+t = Tags(['a'])
+t.tag('b')
+assert t == {'a', 'b'}
+assert type(t) is Tags
+assert type(t | {'c'}) is set
+assert repr(Tags()) == 'Tags()'
+assert repr(Tags([1])) == 'Tags({1})'
+
+# deduplication keeping the order
+seen = set()
+unique = []
+for x in [3, 1, 3, 2, 1]:
+    if x not in seen:
+        seen.add(x)
+        unique.append(x)
+assert unique == [3, 1, 2]
+
+def catch(fn):
+    try:
+        fn()
+    except TypeError as e:
+        return str(e)
+    return None
+
+assert catch(lambda: set().add()) == 'set.add() takes exactly one argument (0 given)'
+assert catch(lambda: set().remove(1, 2)) == 'set.remove() takes exactly one argument (2 given)'
+assert catch(lambda: set().pop(1)) == 'set.pop() takes no arguments (1 given)'
+assert catch(lambda: frozenset().copy(1)) == 'frozenset.copy() takes no arguments (1 given)'
+assert catch(lambda: set().issubset()) == 'set.issubset() takes exactly one argument (0 given)'
+assert catch(lambda: frozenset().isdisjoint()) == 'frozenset.isdisjoint() takes exactly one argument (0 given)'
+assert catch(lambda: set().__or__()) == 'expected 1 argument, got 0'
+assert catch(lambda: frozenset().__hash__(1)) == 'expected 0 arguments, got 1'
+assert catch(lambda: set().__ior__()) == 'expected 1 argument, got 0'
@@ -0,0 +1,110 @@
+xs = [0, 1, 2, 3, 4, 5]
+assert xs[-1] == 5
+assert xs[-6] == 0
+assert xs[1:3] == [1, 2]
+assert xs[:2] == [0, 1]
+assert xs[4:] == [4, 5]
+assert xs[:] == xs
+assert xs[:] is not xs
+assert xs[::2] == [0, 2, 4]
+assert xs[::-1] == [5, 4, 3, 2, 1, 0]
+assert xs[-2:] == [4, 5]
+assert xs[5:1:-2] == [5, 3]
+assert xs[10:] == []
+assert xs[-100:2] == [0, 1]
+assert xs[slice(1, 5, 2)] == [1, 3]
+
+s = 'hello'
+assert s[-1] == 'o'
+assert s[1:3] == 'el'
+assert s[::-1] == 'olleh'
+assert s[-3:] == 'llo'
+
+t = (1, 2, 3)
+assert t[-1] == 3
+assert t[1:] == (2, 3)
+assert t[::-1] == (3, 2, 1)
+
+
+xs = [0, 1, 2, 3]
+xs[1:3] = ['a', 'b', 'c']
+assert xs == [0, 'a', 'b', 'c', 3]
+xs[::2] = [7, 8, 9]
+assert xs == [7, 'a', 8, 'c', 9]
+xs[:] = xs
+assert xs == [7, 'a', 8, 'c', 9]
+xs[len(xs):] = (10,)
+assert xs == [7, 'a', 8, 'c', 9, 10]
+xs[-1] = 11
+assert xs[5] == 11
+
+xs = [0, 1, 2, 3, 4, 5]
+xs.__delitem__(slice(None, None, 2))
+assert xs == [1, 3, 5]
+xs.__delitem__(-1)
+assert xs == [1, 3]
+xs.__delitem__(slice(0, 1))
+assert xs == [3]
+
+
+sl = slice(1, 5, 2)
+assert sl.start == 1 and sl.stop == 5 and sl.step == 2
+assert slice(3).start is None
+assert slice(1, 10, 2).indices(5) == (1, 5, 2)
+assert slice(None, None, -1).indices(5) == (4, -1, -1)
+assert str(slice(1, 2)) == 'slice(1, 2, None)'
+assert slice(1, 2) == slice(1, 2, None)
+
+
+class Recorder:
+    def __getitem__(self, key):
+        return key
+
+r = Recorder()
+assert r[1:2] == slice(1, 2, None)
+assert r[::3] == slice(None, None, 3)
+assert r[1, 2:3] == (1, slice(2, 3, None))
+
+
+def catch(fn):
+    try:
+        fn()
+    except BaseException as e:
+        return type(e)
+    return None
+
+def list_out_of_range():
+    return [1, 2][-3]
+
+def str_out_of_range():
+    return 'ab'[2]
+
+def tuple_out_of_range():
+    return (1,)[-2]
+
+def set_out_of_range():
+    xs = [1]
+    xs[1] = 2
+
+def zero_step():
+    return [1][::0]
+
+def extended_size():
+    xs = [1, 2, 3]
+    xs[::2] = [1]
+
+def str_index():
+    xs = [1]
+    return xs['a']
+
+assert catch(list_out_of_range) is IndexError
+assert catch(str_out_of_range) is IndexError
+assert catch(tuple_out_of_range) is IndexError
+assert catch(set_out_of_range) is IndexError
+assert catch(zero_step) is ValueError
+assert catch(extended_size) is ValueError
+assert catch(str_index) is TypeError
+assert catch(lambda: slice(1).indices()) is TypeError
+assert catch(lambda: slice(1).indices(1, 2)) is TypeError
+assert catch(lambda: slice(1).__eq__()) is TypeError
+assert catch(lambda: slice(1).__repr__(1)) is TypeError
@@ -15,3 +15,257 @@ assert res == True
 
 
 
+
+
+# in looks for a substring
+assert 'bc' in 'abcd'
+assert 'ac' not in 'abcd'
+assert '' in 'abc'
+try:
+    1 in 'abc'
+    assert False
+except TypeError as e:
+    assert str(e) == "'in <string>' requires string as left operand, not int"
+
+# repetition
+assert 'ab' * 3 == 'ababab'
+assert 3 * 'ab' == 'ababab'
+assert 'ab' * 0 == ''
+assert 'ab' * -2 == ''
+assert 'ab' * True == 'ab'
+try:
+    'ab' * 1.5
+    assert False
+except TypeError as e:
+    assert str(e) == "can't multiply sequence by non-int of type 'float'"
+try:
+    'a' * 'b'
+    assert False
+except TypeError as e:
+    assert str(e) == "can't multiply sequence by non-int of type 'str'"
+
+# split and join
+assert 'a b  c'.split() == ['a', 'b', 'c']
+assert '  a b  '.split() == ['a', 'b']
+assert '  a b  c '.split(None, 1) == ['a', 'b  c ']
+assert '  a b  '.split(maxsplit=0) == ['a b  ']
+assert ''.split() == []
+assert '   '.split() == []
+assert 'a,b,,c'.split(',') == ['a', 'b', '', 'c']
+assert 'a,b,c'.split(',', 1) == ['a', 'b,c']
+assert 'a,b,c'.split(sep=',', maxsplit=-1) == ['a', 'b', 'c']
+assert ''.split(',') == ['']
+try:
+    'a'.split('')
+    assert False
+except ValueError as e:
+    assert str(e) == 'empty separator'
+try:
+    'a'.split(1)
+    assert False
+except TypeError as e:
+    assert str(e) == 'must be str or None, not int'
+
+assert ', '.join(['a', 'b', 'c']) == 'a, b, c'
+assert ''.join(('x', 'y')) == 'xy'
+assert '-'.join('abc') == 'a-b-c'
+assert '-'.join([]) == ''
+try:
+    ', '.join(['a', 1])
+    assert False
+except TypeError as e:
+    assert str(e) == 'sequence item 1: expected str instance, int found'
+try:
+    ''.join(1)
+    assert False
+except TypeError as e:
+    assert str(e) == 'can only join an iterable'
+
+assert 'abc'.splitlines() == ['abc']
+assert ''.splitlines() == []
+
+# strip
+assert '  a b  '.strip() == 'a b'
+assert '  a '.lstrip() == 'a '
+assert '  a '.rstrip() == '  a'
+assert 'xxaxyx'.strip('xy') == 'a'
+assert 'xxaxyx'.lstrip('x') == 'axyx'
+assert 'xxaxyx'.rstrip('xy') == 'xxa'
+assert 'abc'.strip(None) == 'abc'
+try:
+    'a'.strip(1)
+    assert False
+except TypeError as e:
+    assert str(e) == 'strip arg must be None or str'
+
+# replace
+assert 'aaa'.replace('a', 'b') == 'bbb'
+assert 'aaa'.replace('a', 'b', 2) == 'bba'
+assert 'aaa'.replace('a', 'b', -1) == 'bbb'
+assert 'abc'.replace('', '-') == '-a-b-c-'
+assert 'abc'.replace('x', 'y') == 'abc'
+
+# find, index and count
+s = 'hello world'
+assert s.find('o') == 4
+assert s.find('o', 5) == 7
+assert s.find('o', 5, 7) == -1
+assert s.find('o', -4) == 7
+assert s.find('z') == -1
+assert s.find('') == 0
+assert s.find('', 11) == 11
+assert s.find('', 12) == -1
+assert s.find('o', None, 5) == 4
+assert s.index('world') == 6
+try:
+    s.index('z')
+    assert False
+except ValueError as e:
+    assert str(e) == 'substring not found'
+assert s.count('o') == 2
+assert s.count('l', 3) == 2
+assert s.count('') == 12
+assert 'aaaa'.count('aa') == 2
+try:
+    s.find(1)
+    assert False
+except TypeError as e:
+    assert str(e) == 'must be str, not int'
+try:
+    s.find()
+    assert False
+except TypeError as e:
+    assert str(e) == 'find() takes at least 1 argument (0 given)'
+
+# startswith and endswith
+assert s.startswith('hell')
+assert not s.startswith('world')
+assert s.startswith('world', 6)
+assert s.startswith(('x', 'he'))
+assert s.endswith('world')
+assert s.endswith('hello', 0, 5)
+assert not s.endswith(('x', 'y'))
+assert s.startswith('', 11)
+assert not s.startswith('', 12)
+try:
+    s.startswith(1)
+    assert False
+except TypeError as e:
+    assert str(e) == 'startswith first arg must be str or a tuple of str, not int'
+
+# cases
+assert 'Hello'.upper() == 'HELLO'
+assert 'Hello'.lower() == 'hello'
+assert "hello wORLD they're 3rd".title() == "Hello World They'Re 3Rd"
+assert 'hello World'.capitalize() == 'Hello world'
+assert ''.capitalize() == ''
+try:
+    'a'.upper(1)
+    assert False
+except TypeError as e:
+    assert str(e) == 'str.upper() takes no arguments (1 given)'
+
+# classification
+assert '123'.isdigit()
+assert not '12a'.isdigit()
+assert not ''.isdigit()
+assert 'abc'.isalpha()
+assert not 'ab1'.isalpha()
+assert '   '.isspace()
+assert not ' a'.isspace()
+assert not ''.isspace()
+
+# partition
+assert 'a=b=c'.partition('=') == ('a', '=', 'b=c')
+assert 'abc'.partition('=') == ('abc', '', '')
+try:
+    'a'.partition('')
+    assert False
+except ValueError as e:
+    assert str(e) == 'empty separator'
+
+# padding
+assert '42'.zfill(5) == '00042'
+assert '-42'.zfill(5) == '-0042'
+assert '+42'.zfill(2) == '+42'
+assert 'abc'.center(7) == '  abc  '
+assert 'abc'.center(6) == ' abc  '
+assert 'ab'.center(5) == '  ab '
+assert 'ab'.center(5, '*') == '**ab*'
+assert 'abc'.center(2) == 'abc'
+assert 'ab'.ljust(4) == 'ab  '
+assert 'ab'.rjust(4, '0') == '00ab'
+try:
+    'a'.center(5, 'ab')
+    assert False
+except TypeError as e:
+    assert str(e) == 'The fill character must be exactly one character long'
+try:
+    'a'.ljust(1.5)
+    assert False
+except TypeError as e:
+    assert str(e) == "'float' object cannot be interpreted as an integer"
+
+# the methods are inherited by subclasses
+class Word(str):
+    'pass'
+
+assert Word('ab').upper() == 'AB'
+
+# escape sequences
+assert len('\n') == 1
+assert ord('\t') == 9
+assert 'it\'s' == "it's"
+assert "say \"hi\"" == 'say "hi"'
+assert '\\' + 'n' == '\\n'
+assert len('\\n') == 2
+assert '\x41é\U0001F600' == 'Aé😀'
+assert '\101' == 'A'
+assert '\0' == chr(0)
+assert '\a\b\f\v\r' == chr(7) + chr(8) + chr(12) + chr(11) + chr(13)
+assert '\N{LATIN SMALL LETTER E WITH ACUTE}' == 'é'
+assert '\N{snowman}' == chr(9731)
+assert '\N{HIRAGANA LETTER A}' == chr(12354)
+assert '\N{BYTE ORDER MARK}' == chr(65279)
+assert '\q' == '\\q'
+assert 'a\
+b' == 'ab'
+
+# raw strings keep backslashes
+assert r'\n' == '\\n'
+assert R'a\'b' == 'a\\\'b'
+assert len(r'\x41') == 4
+
+# triple-quoted strings span lines
+doc = '''first
+    second "quoted" 'too'
+third'''
+assert doc.splitlines() == ['first', '    second "quoted" \'too\'', 'third']
+assert """a""" + """b""" == 'ab'
+assert '''it's''' == "it's"
+assert """\tx""" == '\tx'
+
+# adjacent literals are joined
+assert 'a' "b" 'c' == 'abc'
+assert 'a' r'\n' == 'a\\n'
+joined = ('one, '
+          'two, '   # a comment between
+          'three')
+assert joined == 'one, two, three'
+
+# repr escapes what isn't printable
+assert repr('a\nb') == "'a\\nb'"
+assert repr("it's") == '"it\'s"'
+assert repr('both \' and "') == '\'both \\\' and "\''
+assert repr('\\') == "'\\\\'"
+assert repr('\x00\x7f') == "'\\x00\\x7f'"
+assert repr('é ') == "'é\\u2028'"
+assert str(['\t']) == "['\\t']"
+
+# methods with whitespace and line breaks
+assert ' \t a b \n'.strip() == 'a b'
+assert 'a\tb\nc  d'.split() == ['a', 'b', 'c', 'd']
+assert 'a\nb\r\nc\rd'.splitlines() == ['a', 'b', 'c', 'd']
+assert 'a\nb\r\n'.splitlines(True) == ['a\n', 'b\r\n']
+assert '\n'.join(['x', 'y']) == 'x\ny'
+assert '\t\n '.isspace()
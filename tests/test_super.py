@@ -0,0 +1,170 @@
+class Base:
+    def __init__(self, name):
+        self.name = name
+        self.log = ['Base']
+
+    def greet(self):
+        return 'hello ' + self.name
+
+class Child(Base):
+    def __init__(self, name, age):
+        super().__init__(name)
+        self.age = age
+        self.log.append('Child')
+
+    def greet(self):
+        return super().greet() + ', aged ' + str(self.age)
+
+c = Child('bob', 3)
+assert c.name == 'bob'
+assert c.age == 3
+assert c.log == ['Base', 'Child']
+assert c.greet() == 'hello bob, aged 3'
+
+
+# the explicit form
+class Explicit(Base):
+    def greet(self):
+        return super(Explicit, self).greet() + '!'
+
+assert Explicit('amy').greet() == 'hello amy!'
+assert super(Child, c).greet() == 'hello bob'
+
+
+# cooperative calls follow the mro of the instance
+class A:
+    def chain(self):
+        return ['A']
+
+class B(A):
+    def chain(self):
+        return ['B'] + super().chain()
+
+class C(A):
+    def chain(self):
+        return ['C'] + super().chain()
+
+class D(B, C):
+    def chain(self):
+        return ['D'] + super().chain()
+
+assert D().chain() == ['D', 'B', 'C', 'A']
+assert B().chain() == ['B', 'A']
+
+class Counter:
+    def __init__(self, **kwargs):
+        self.args = kwargs
+
+class Named(Counter):
+    def __init__(self, name, **kwargs):
+        super().__init__(**kwargs)
+        self.name = name
+
+class Sized(Counter):
+    def __init__(self, size, **kwargs):
+        super().__init__(**kwargs)
+        self.size = size
+
+class Both(Named, Sized):
+    'pass'
+
+b = Both(name='box', size=2, extra=1)
+assert b.name == 'box'
+assert b.size == 2
+assert len(b.args) == 1
+assert b.args['extra'] == 1
+
+
+# the class the method is defined in is used, not the type of self
+class Parent:
+    def who(self):
+        return 'Parent'
+
+class Middle(Parent):
+    def who(self):
+        return 'Middle > ' + super().who()
+
+class Leaf(Middle):
+    'pass'
+
+assert Leaf().who() == 'Middle > Parent'
+
+
+# the first argument needn't be named self
+class Other(Parent):
+    def who(this):
+        return 'Other > ' + super().who()
+
+assert Other().who() == 'Other > Parent'
+
+
+# super() works in nested blocks, generators and with class objects
+class Gen(Parent):
+    def each(self):
+        for i in range(2):
+            yield super().who() + str(i)
+
+assert list(Gen().each()) == ['Parent0', 'Parent1']
+
+assert super(Middle, Leaf).who(Leaf()) == 'Parent'
+
+class Meta(Parent):
+    def check(self):
+        return __class__
+
+assert Meta().check() is Meta
+
+
+# super() of exceptions
+class AppError(ValueError):
+    def __init__(self, msg, code):
+        super().__init__(msg)
+        self.code = code
+
+try:
+    raise AppError('bad', 3)
+except ValueError as e:
+    assert str(e) == 'bad'
+    assert e.code == 3
+
+
+# errors
+class Plain:
+    def missing(self):
+        return super().nothing
+
+try:
+    Plain().missing()
+    assert False
+except AttributeError as e:
+    assert str(e) == "'super' object has no attribute 'nothing'"
+
+def outside():
+    return super()
+
+try:
+    outside()
+    assert False
+except RuntimeError as e:
+    assert str(e) == 'super(): no arguments'
+
+def outside_with_arg(x):
+    return super()
+
+try:
+    outside_with_arg(1)
+    assert False
+except RuntimeError as e:
+    assert str(e) == 'super(): __class__ cell not found'
+
+try:
+    super(Plain, 1)
+    assert False
+except TypeError as e:
+    assert str(e) == 'super(type, obj): obj must be an instance or subtype of type'
+
+try:
+    super(1, 1)
+    assert False
+except TypeError as e:
+    assert str(e) == 'super() argument 1 must be a type, not int'
@@ -0,0 +1,93 @@
+res = 0
+try:
+    raise Exception('boom')
+except Exception as e:
+    res = 1
+
+assert res == 1
+
+
+class MyError(Exception):
+    'pass'
+
+res = ''
+try:
+    raise MyError
+except StopIteration:
+    res = 'stop'
+except MyError as e:
+    res = 'mine'
+    assert isinstance(e, MyError)
+
+assert res == 'mine'
+
+
+res = []
+try:
+    res.append(1)
+except:
+    res.append(2)
+else:
+    res.append(3)
+finally:
+    res.append(4)
+
+assert res == [1, 3, 4]
+
+
+res = []
+try:
+    try:
+        raise MyError
+    finally:
+        res.append('inner')
+except Exception:
+    res.append('outer')
+
+assert res == ['inner', 'outer']
+
+
+def foo(log):
+    try:
+        return 1
+    finally:
+        log.append('finally')
+
+log = []
+assert foo(log) == 1
+assert log == ['finally']
+
+
+def bar():
+    try:
+        raise Exception
+    finally:
+        return 2
+
+assert bar() == 2
+
+
+log = []
+for i in range(3):
+    try:
+        if i == 1:
+            continue
+        if i == 2:
+            break
+        log.append(i)
+    finally:
+        log.append('f')
+
+assert log == [0, 'f', 'f', 'f']
+
+
+res = 0
+try:
+    try:
+        raise MyError
+    except MyError:
+        raise
+except MyError:
+    res = 1
+
+assert res == 1
@@ -0,0 +1,150 @@
+t = (1, 2, 3)
+assert len(t) == 3
+assert t[0] == 1
+assert 2 in t
+assert t == (1, 2, 3)
+assert t != (1, 2)
+assert t + (4,) == (1, 2, 3, 4)
+assert t.index(3) == 2
+assert (1, 1, 2).count(1) == 2
+
+assert () == tuple()
+assert tuple([1, 2]) == (1, 2)
+assert (1) == 1
+assert len((1,)) == 1
+assert str((1,)) == '(1,)'
+assert str((1, 'a')) == "(1, 'a')"
+
+t = 1, 2
+assert t == (1, 2)
+t = 1,
+assert t == (1,)
+
+assert (1, 2) < (1, 3)
+assert (1, 2) < (1, 2, 0)
+assert (2,) > (1, 5)
+
+d = {(1, 2): 'a'}
+assert d[(1, 2)] == 'a'
+assert hash((1, 2)) == hash((1, 2))
+
+
+a, b = 1, 2
+a, b = b, a
+assert a == 2 and b == 1
+
+(a, b), c = (1, 2), 3
+assert a == 1 and b == 2 and c == 3
+
+[a, b] = 'xy'
+assert a == 'x' and b == 'y'
+
+first, *rest = [1, 2, 3]
+assert first == 1 and rest == [2, 3]
+
+*init, last = [1, 2, 3]
+assert init == [1, 2] and last == 3
+
+a, *mid, b = 1, 2
+assert mid == []
+
+xs = [0, 0]
+xs[0], xs[1] = 1, 2
+assert xs == [1, 2]
+
+assert [*rest, *(4, 5)] == [2, 3, 4, 5]
+assert (*rest, 4) == (2, 3, 4)
+
+
+def pair():
+    return 1, 2
+
+a, b = pair()
+assert a + b == 3
+
+
+total = 0
+for k, v in [('a', 1), ('b', 2)]:
+    total += v
+
+assert total == 3
+
+for i, (x, *ys) in [(0, [1, 2, 3])]:
+    assert x == 1 and ys == [2, 3]
+
+
+def catch(fn):
+    try:
+        fn()
+    except ValueError as e:
+        return str(e)
+    return None
+
+def too_many():
+    a, b = 1, 2, 3
+
+def not_enough():
+    a, b, c = 1, 2
+
+def not_enough_starred():
+    a, b, *c = [1]
+
+assert catch(too_many) == 'too many values to unpack (expected 2)'
+assert catch(not_enough) == 'not enough values to unpack (expected 3, got 2)'
+assert catch(not_enough_starred) == 'not enough values to unpack (expected at least 2, got 1)'
+
+
+try:
+    {}['a']
+except (TypeError, KeyError):
+    caught = True
+
+assert caught
+assert isinstance(1, (str, int))
+
+# chained assignment binds every target to the one value
+a = b = 5
+assert a == 5 and b == 5
+first, second = pair = 1, 2
+assert first == 1 and second == 2 and pair == (1, 2)
+xs = ys = []
+xs.append(1)
+assert ys == [1]
+i = items = [0, 0]
+i = items[i] = 1
+assert i == 1 and items == [0, 1]
+
+# repetition
+assert (1, 2) * 2 == (1, 2, 1, 2)
+assert 3 * (0,) == (0, 0, 0)
+assert (1,) * 0 == ()
+assert (1,) * -1 == ()
+assert () * 5 == ()
+
+# count and index
+assert (1, 2, 1).count(1) == 2
+assert (1, 2, 1).index(1) == 0
+assert (1, 2, 1).index(1, 1) == 2
+assert (1, 2, 1).index(2, -2, 3) == 1
+
+def arity(fn, *args):
+    try:
+        fn(*args)
+    except TypeError as e:
+        return str(e)
+
+assert arity(().count) == 'tuple.count() takes exactly one argument (0 given)'
+assert arity(().index) == 'index expected at least 1 argument, got 0'
+assert arity((1,).index, 1, 0, 1, 2) == 'index expected at most 3 arguments, got 4'
+assert arity(lambda: (1,) * 'a') == "can't multiply sequence by non-int of type 'str'"
+try:
+    (1, 2, 1).index(1, 1, 2)
+    assert False
+except ValueError as e:
+    assert str(e) == 'tuple.index(x): x not in tuple'
+
+assert arity((1, 2).__add__) == 'expected 1 argument, got 0'
+assert arity((1, 2).__getitem__) == 'expected 1 argument, got 0'
+assert arity((1, 2).__lt__) == 'expected 1 argument, got 0'
+assert arity((1, 2).__len__, 1) == 'expected 0 arguments, got 1'
+assert arity(iter((1, 2)).__next__, 1) == 'expected 0 arguments, got 1'
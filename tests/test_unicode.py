@@ -0,0 +1,115 @@
+# strings are sequences of code points
+s = 'héllo wörld'
+assert len(s) == 11
+assert s[1] == 'é'
+assert s[-4] == 'ö'
+assert s[1:4] == 'éll'
+assert s[::-1] == 'dlröw olléh'
+assert s[::2] == 'hlowrd'
+assert list('añb') == ['a', 'ñ', 'b']
+
+chars = []
+for c in '日本語':
+    chars.append(c)
+assert chars == ['日', '本', '語']
+assert len('日本語') == 3
+assert '本' in '日本語'
+assert '日本' + '語' == '日本語'
+assert '日' * 3 == '日日日'
+assert len('😀') == 1
+assert 'a😀b'[1] == '😀'
+
+try:
+    '日本'[2]
+    assert False
+except IndexError as e:
+    assert str(e) == 'string index out of range'
+
+# comparisons go by code point
+assert 'é' > 'z'
+assert 'a' < 'é' < '日'
+assert sorted(['日', 'é', 'a']) == ['a', 'é', '日']
+assert {'é': 1}['é'] == 1
+
+# str methods count code points
+assert 'héllo'.find('l') == 2
+assert 'héllo'.index('o') == 4
+assert 'ñañaña'.count('ña') == 3
+assert 'héllo'.find('l', 3) == 3
+assert 'héllo'.startswith('él', 1)
+assert 'éé'.center(4, '·') == '·éé·'
+assert 'é'.zfill(3) == '00é'
+assert 'ÉCOLE'.lower() == 'école'
+assert 'élan vital'.title() == 'Élan Vital'
+assert 'été'.isalpha()
+assert '١٢٣'.isdigit()
+assert 'a b'.split() == ['a', 'b']
+
+# ord and chr
+assert ord('a') == 97
+assert ord('é') == 233
+assert ord('日') == 26085
+assert ord('😀') == 128512
+assert chr(97) == 'a'
+assert chr(233) == 'é'
+assert chr(128512) == '😀'
+assert chr(ord('日')) == '日'
+assert ''.join([chr(ord(c) + 1) for c in 'HAL']) == 'IBM'
+
+try:
+    ord('ab')
+    assert False
+except TypeError as e:
+    assert str(e) == 'ord() expected a character, but string of length 2 found'
+try:
+    ord(1)
+    assert False
+except TypeError as e:
+    assert str(e) == 'ord() expected string of length 1, but int found'
+try:
+    chr(-1)
+    assert False
+except ValueError as e:
+    assert str(e) == 'chr() arg not in range(0x110000)'
+try:
+    chr(1114112)
+    assert False
+except ValueError as e:
+    assert str(e) == 'chr() arg not in range(0x110000)'
+try:
+    chr(1.5)
+    assert False
+except TypeError as e:
+    assert str(e) == "'float' object cannot be interpreted as an integer"
+
+# identifiers can be in any language
+café = 1
+π = 3.14
+名前 = 'name'
+x_é1 = 2
+assert café + x_é1 == 3
+assert π == 3.14
+assert 名前 == 'name'
+
+def grüß(wer):
+    return 'hallo ' + wer
+
+assert grüß('welt') == 'hallo welt'
+
+class Größe:
+    def __init__(self, wert):
+        self.wert = wert
+
+assert Größe(5).wert == 5
+
+# the full case mappings, and the final form of sigma
+assert 'ß'.upper() == 'SS'
+assert 'straße'.title() == 'Straße'
+assert 'ßa'.capitalize() == 'Ssa'
+assert 'ﬁ'.upper() == 'FI'
+assert 'İ'.lower() == 'i̇'
+assert 'ΟΔΟΣ ΣΑΣ'.lower() == 'οδος σας'
+assert 'Σ'.lower() == 'σ'
+assert '²'.isdigit()
+assert '①'.isdigit()
+assert not '½'.isdigit()
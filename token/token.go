@@ -23,25 +23,58 @@ const (
     ISN         = "is not"
     ASSERT      = "assert"
     RAISE       = "raise"
+    TRY         = "try"
+    EXCEPT      = "except"
+    FINALLY     = "finally"
+    AS          = "as"
+    YIELD       = "yield"
+    FROM        = "from"
+    LAMBDA      = "lambda"
+    GLOBAL      = "global"
+    NONLOCAL    = "nonlocal"
+    DEL         = "del"
 
     ASSIGN      = "="
     IDENTIFIER  = "IDENTIFIER"
     UNDERSCORE  = "_"
 
     INTEGER      = "INTEGER"
+    FLOAT       = "FLOAT"
     STRING      = "STRING"
+    BYTES       = "BYTES"
+    FSTRING     = "FSTRING"
 
     PLUS        = "+"
     MINUS       = "-"
     MUL         = "*"
+    POW         = "**"
     DIVIDE      = "/"
+    FLOORDIV    = "//"
+    MOD         = "%"
     PLUSASSIGN  = "+="
     MINUSASSIGN  = "-="
     MULASSIGN  = "*="
+    POWASSIGN  = "**="
     DIVIDEASSIGN  = "/="
+    FLOORDIVASSIGN  = "//="
+    MODASSIGN  = "%="
+
+    INVERT      = "~"
+    BITAND      = "&"
+    BITOR       = "|"
+    BITXOR      = "^"
+    LSHIFT      = "<<"
+    RSHIFT      = ">>"
+    BITANDASSIGN  = "&="
+    BITORASSIGN  = "|="
+    BITXORASSIGN  = "^="
+    LSHIFTASSIGN  = "<<="
+    RSHIFTASSIGN  = ">>="
 
     GT          = ">"
     LT          = "<"
+    GE          = ">="
+    LE          = "<="
     EQ          = "=="
     NEQ         = "!="
 
@@ -58,6 +91,7 @@ const (
     COLON       = ":"
     COMMA       = ","
     DOT         = "."
+    AT          = "@"
 )
 
 var Keywords = map[string]TokenType {
@@ -80,6 +114,16 @@ var Keywords = map[string]TokenType {
     "is not":   ISN,
     "assert":   ASSERT,
     "raise":    RAISE,
+    "try":      TRY,
+    "except":   EXCEPT,
+    "finally":  FINALLY,
+    "as":       AS,
+    "yield":    YIELD,
+    "from":     FROM,
+    "lambda":   LAMBDA,
+    "global":   GLOBAL,
+    "nonlocal": NONLOCAL,
+    "del":      DEL,
 }
 
 